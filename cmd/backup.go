@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/alist-org/alist/v3/internal/backup"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// BackupCmd represents the backup command
+var BackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Export or import storages, users, metas, settings and index jobs",
+}
+
+var backupOutput string
+var backupSecrets bool
+var ExportBackupCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export configuration into a json archive",
+	Run: func(cmd *cobra.Command, args []string) {
+		Init()
+		defer Release()
+		a, err := backup.Export(backupSecrets)
+		if err != nil {
+			utils.Log.Errorf("failed to export: %+v", err)
+			return
+		}
+		data, err := utils.Json.MarshalIndent(a, "", "  ")
+		if err != nil {
+			utils.Log.Errorf("failed to marshal archive: %+v", err)
+			return
+		}
+		if backupOutput == "" {
+			backupOutput = fmt.Sprintf("alist-backup-%s.json", a.CreatedAt.Format("20060102-150405"))
+		}
+		if err = os.WriteFile(backupOutput, data, 0o600); err != nil {
+			utils.Log.Errorf("failed to write archive: %+v", err)
+			return
+		}
+		utils.Log.Infof("configuration has been exported to %s", backupOutput)
+	},
+}
+
+var backupMode string
+var ImportBackupCmd = &cobra.Command{
+	Use:   "import ARCHIVE",
+	Short: "Import configuration from a json archive",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			utils.Log.Errorf("Please enter the archive file")
+			return
+		}
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			utils.Log.Errorf("failed to read archive: %+v", err)
+			return
+		}
+		var a backup.Archive
+		if err = utils.Json.Unmarshal(data, &a); err != nil {
+			utils.Log.Errorf("failed to parse archive: %+v", err)
+			return
+		}
+		Init()
+		defer Release()
+		err = backup.Import(context.Background(), &a, backup.ImportArgs{Mode: backupMode})
+		if err != nil {
+			utils.Log.Errorf("failed to import: %+v", err)
+			return
+		}
+		utils.Log.Infof("configuration has been imported from %s", args[0])
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(BackupCmd)
+	BackupCmd.AddCommand(ExportBackupCmd)
+	BackupCmd.AddCommand(ImportBackupCmd)
+	ExportBackupCmd.Flags().StringVarP(&backupOutput, "output", "o", "", "output file, alist-backup-TIME.json by default")
+	ExportBackupCmd.Flags().BoolVar(&backupSecrets, "secrets", false, "include storage credentials and user passwords")
+	ImportBackupCmd.Flags().StringVar(&backupMode, "mode", backup.ModeMerge, "merge or replace, replace deletes the objects not in the archive")
+}
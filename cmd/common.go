@@ -7,7 +7,9 @@ import (
 
 	"github.com/alist-org/alist/v3/internal/bootstrap"
 	"github.com/alist-org/alist/v3/internal/bootstrap/data"
+	"github.com/alist-org/alist/v3/internal/cache"
 	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
@@ -15,13 +17,16 @@ import (
 func Init() {
 	bootstrap.InitConfig()
 	bootstrap.Log()
+	bootstrap.InitCache()
 	bootstrap.InitDB()
 	data.InitData()
 	bootstrap.InitIndex()
 }
 
 func Release() {
+	op.CloseListDiskCache()
 	db.Close()
+	cache.Release()
 }
 
 var pid = -1
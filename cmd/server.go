@@ -18,6 +18,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server"
+	"github.com/alist-org/alist/v3/server/dlna"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -36,7 +37,13 @@ the address is defined in config file`,
 			time.Sleep(time.Duration(conf.Conf.DelayedStart) * time.Second)
 		}
 		bootstrap.InitOfflineDownloadTools()
+		bootstrap.InitListCache()
 		bootstrap.LoadStorages()
+		bootstrap.InitIndexJobs()
+		bootstrap.InitBackup()
+		bootstrap.InitJournal()
+		bootstrap.InitWarmer()
+		bootstrap.InitMusic()
 		bootstrap.InitTaskManager()
 		if !flags.Debug && !flags.Dev {
 			gin.SetMode(gin.ReleaseMode)
@@ -112,6 +119,17 @@ the address is defined in config file`,
 				}
 			}()
 		}
+		var dlnaSrv *dlna.Server
+		if conf.Conf.DLNA.Enable {
+			dlnaSrv = dlna.NewServer()
+			utils.Log.Infof("start DLNA server @ %s:%d", conf.Conf.Scheme.Address, conf.Conf.DLNA.Port)
+			go func() {
+				err := dlnaSrv.Start()
+				if err != nil && !errors.Is(err, http.ErrServerClosed) {
+					utils.Log.Fatalf("failed to start dlna server: %s", err.Error())
+				}
+			}()
+		}
 		// Wait for interrupt signal to gracefully shutdown the server with
 		// a timeout of 1 second.
 		quit := make(chan os.Signal, 1)
@@ -152,6 +170,15 @@ the address is defined in config file`,
 				}
 			}()
 		}
+		if dlnaSrv != nil {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := dlnaSrv.Shutdown(ctx); err != nil {
+					utils.Log.Fatal("DLNA server shutdown err: ", err)
+				}
+			}()
+		}
 		wg.Wait()
 		utils.Log.Println("Server exit")
 	},
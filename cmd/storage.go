@@ -4,11 +4,15 @@ Copyright © 2023 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"strconv"
 
+	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
 	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -47,6 +51,42 @@ var disableStorageCmd = &cobra.Command{
 	},
 }
 
+var newMasterKey string
+var rekeyStorageCmd = &cobra.Command{
+	Use:   "rekey",
+	Short: "Re-encrypt confidential fields of all storages with a new master key",
+	Run: func(cmd *cobra.Command, args []string) {
+		Init()
+		defer Release()
+		storages, _, err := db.GetStorages(1, -1)
+		if err != nil {
+			utils.Log.Errorf("failed to query storages: %+v", err)
+			return
+		}
+		if newMasterKey == "" {
+			newMasterKey = random.String(32)
+		}
+		// decrypt all storages first, so nothing is changed if the current key is wrong
+		for i := range storages {
+			storages[i].Addition, err = op.RekeyAddition(storages[i].Driver, storages[i].Addition, conf.Conf.MasterKey, newMasterKey)
+			if err != nil {
+				utils.Log.Errorf("failed to rekey storage [%s]: %+v", storages[i].MountPath, err)
+				return
+			}
+		}
+		for i := range storages {
+			if err = db.UpdateStorage(&storages[i]); err != nil {
+				utils.Log.Errorf("failed to update storage [%s]: %+v", storages[i].MountPath, err)
+				return
+			}
+		}
+		utils.Log.Infof("%d storages have been re-encrypted", len(storages))
+		utils.Log.Infof("set master_key in config file or ALIST_MASTER_KEY to the new key before starting the server")
+		// the key is printed only once and never logged
+		fmt.Printf("new master key: %s\n", newMasterKey)
+	},
+}
+
 var baseStyle = lipgloss.NewStyle().
 	BorderStyle(lipgloss.NormalBorder()).
 	BorderForeground(lipgloss.Color("240"))
@@ -150,6 +190,8 @@ func init() {
 	RootCmd.AddCommand(storageCmd)
 	storageCmd.AddCommand(disableStorageCmd)
 	storageCmd.AddCommand(listStorageCmd)
+	storageCmd.AddCommand(rekeyStorageCmd)
+	rekeyStorageCmd.Flags().StringVar(&newMasterKey, "new-key", "", "new master key, generated randomly if empty")
 	storageCmd.PersistentFlags().IntVarP(&storageTableHeight, "height", "H", 10, "Table height")
 	// Here you will define your flags and configuration settings.
 
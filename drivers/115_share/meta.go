@@ -6,7 +6,7 @@ import (
 )
 
 type Addition struct {
-	Cookie       string  `json:"cookie" type:"text" help:"one of QR code token and cookie required"`
+	Cookie       string  `json:"cookie" confidential:"true" type:"text" help:"one of QR code token and cookie required"`
 	QRCodeToken  string  `json:"qrcode_token" type:"text" help:"one of QR code token and cookie required"`
 	QRCodeSource string  `json:"qrcode_source" type:"select" options:"web,android,ios,tv,alipaymini,wechatmini,qandroid" default:"linux" help:"select the QR code device, default linux"`
 	PageSize     int64   `json:"page_size" type:"number" default:"1000" help:"list api per page size of 115 driver"`
@@ -7,7 +7,7 @@ import (
 
 type Addition struct {
 	Username string `json:"username" required:"true"`
-	Password string `json:"password" required:"true"`
+	Password string `json:"password" confidential:"true" required:"true"`
 	driver.RootID
 	//OrderBy        string `json:"order_by" type:"select" options:"file_id,file_name,size,update_at" default:"file_name"`
 	//OrderDirection string `json:"order_direction" type:"select" options:"asc,desc" default:"asc"`
@@ -24,4 +24,5 @@ func init() {
 	op.RegisterDriver(func() driver.Driver {
 		return &Pan123{}
 	})
+	op.RegisterDiskCacheObj(File{})
 }
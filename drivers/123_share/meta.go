@@ -11,7 +11,7 @@ type Addition struct {
 	driver.RootID
 	//OrderBy        string `json:"order_by" type:"select" options:"file_name,size,update_at" default:"file_name"`
 	//OrderDirection string `json:"order_direction" type:"select" options:"asc,desc" default:"asc"`
-	AccessToken string `json:"accesstoken" type:"text"`
+	AccessToken string `json:"accesstoken" confidential:"true" type:"text"`
 }
 
 var config = driver.Config{
@@ -7,8 +7,8 @@ import (
 
 type Addition struct {
 	Username string `json:"username" required:"true"`
-	Password string `json:"password" required:"true"`
-	Cookie   string `json:"cookie" help:"Fill in the cookie if need captcha"`
+	Password string `json:"password" confidential:"true" required:"true"`
+	Cookie   string `json:"cookie" confidential:"true" help:"Fill in the cookie if need captcha"`
 	driver.RootID
 }
 
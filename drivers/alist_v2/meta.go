@@ -8,8 +8,8 @@ import (
 type Addition struct {
 	driver.RootPath
 	Address     string `json:"url" required:"true"`
-	Password    string `json:"password"`
-	AccessToken string `json:"access_token"`
+	Password    string `json:"password" confidential:"true"`
+	AccessToken string `json:"access_token" confidential:"true"`
 }
 
 var config = driver.Config{
@@ -10,8 +10,8 @@ type Addition struct {
 	Address         string `json:"url" required:"true"`
 	MetaPassword    string `json:"meta_password"`
 	Username        string `json:"username"`
-	Password        string `json:"password"`
-	Token           string `json:"token"`
+	Password        string `json:"password" confidential:"true"`
+	Token           string `json:"token" confidential:"true"`
 	PassUAToUpsteam bool   `json:"pass_ua_to_upsteam" default:"true"`
 }
 
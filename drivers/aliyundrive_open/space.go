@@ -0,0 +1,23 @@
+package aliyundrive_open
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// GetSpace returns the personal space of the user, which is shared by the drives
+func (d *AliyundriveOpen) GetSpace(ctx context.Context) (*model.StorageSpace, error) {
+	res, err := d.request("/adrive/v1.0/user/getSpaceInfo", http.MethodPost, nil)
+	if err != nil {
+		return nil, err
+	}
+	info := utils.Json.Get(res, "personal_space_info")
+	total, used := info.Get("total_size").ToInt64(), info.Get("used_size").ToInt64()
+	return &model.StorageSpace{Total: total, Free: total - used}, nil
+}
+
+var _ driver.SpaceGetter = (*AliyundriveOpen)(nil)
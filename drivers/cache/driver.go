@@ -0,0 +1,239 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"io"
+	stdpath "path"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+const mb = 1024 * 1024
+
+type Cache struct {
+	model.Storage
+	Addition
+	store     *store
+	chunkSize int64
+}
+
+func (d *Cache) Config() driver.Config {
+	return config
+}
+
+func (d *Cache) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Cache) Init(ctx context.Context) error {
+	if d.RemotePath == "" {
+		return fmt.Errorf("remote_path is required")
+	}
+	d.RemotePath = utils.FixAndCleanPath(d.RemotePath)
+	if d.ChunkSize <= 0 {
+		d.ChunkSize = 4
+	}
+	d.chunkSize = int64(d.ChunkSize) * mb
+	if d.store != nil {
+		_ = d.store.drop()
+	}
+	s, err := newStore(d.storeDir(), int64(d.MaxSize)*mb, time.Duration(d.TTL)*time.Minute)
+	if err != nil {
+		return fmt.Errorf("failed to init cache dir: %w", err)
+	}
+	d.store = s
+	return nil
+}
+
+// storeDir returns the folder of the chunks, it's owned by the store and removed with it,
+// so it's always a sub folder created for the storage, never the configured cache_dir itself
+func (d *Cache) storeDir() string {
+	if d.CacheDir == "" {
+		return filepath.Join(conf.Conf.TempDir, "cache", strconv.Itoa(int(d.ID)))
+	}
+	return filepath.Join(d.CacheDir, "alist-cache-"+strconv.Itoa(int(d.ID)))
+}
+
+func (d *Cache) Drop(ctx context.Context) error {
+	if d.store != nil {
+		return d.store.drop()
+	}
+	return nil
+}
+
+func (d *Cache) Get(ctx context.Context, path string) (model.Obj, error) {
+	if utils.PathEqual(path, "/") {
+		return &model.Object{
+			Name:     "Root",
+			IsFolder: true,
+			Path:     "/",
+		}, nil
+	}
+	obj, err := fs.Get(ctx, stdpath.Join(d.RemotePath, path), &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return nil, err
+	}
+	return &model.Object{
+		Path:     path,
+		Name:     obj.GetName(),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		Ctime:    obj.CreateTime(),
+		IsFolder: obj.IsDir(),
+		HashInfo: obj.GetHash(),
+	}, nil
+}
+
+func (d *Cache) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	objs, err := fs.List(ctx, stdpath.Join(d.RemotePath, dir.GetPath()), &fs.ListArgs{NoLog: true, Refresh: args.Refresh})
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(objs, func(obj model.Obj) (model.Obj, error) {
+		thumb, ok := model.GetThumb(obj)
+		objRes := model.Object{
+			Name:     obj.GetName(),
+			Size:     obj.GetSize(),
+			Modified: obj.ModTime(),
+			Ctime:    obj.CreateTime(),
+			IsFolder: obj.IsDir(),
+			HashInfo: obj.GetHash(),
+		}
+		if !ok {
+			return &objRes, nil
+		}
+		return &model.ObjThumb{
+			Object: objRes,
+			Thumbnail: model.Thumbnail{
+				Thumbnail: thumb,
+			},
+		}, nil
+	})
+}
+
+func (d *Cache) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	size := file.GetSize()
+	rrc := &model.RangeReadCloser{}
+	var (
+		remoteMu     sync.Mutex
+		remoteReader model.RangeReaderFunc
+	)
+	// the remote link is only requested when there is a cache miss
+	getRemoteReader := func(ctx context.Context) (model.RangeReaderFunc, error) {
+		remoteMu.Lock()
+		defer remoteMu.Unlock()
+		if remoteReader != nil {
+			return remoteReader, nil
+		}
+		storage, actualPath, err := op.GetStorageAndActualPath(stdpath.Join(d.RemotePath, file.GetPath()))
+		if err != nil {
+			return nil, err
+		}
+		link, _, err := op.Link(ctx, storage, actualPath, args)
+		if err != nil {
+			return nil, err
+		}
+		if link.MFile != nil {
+			rrc.Closers.Add(link.MFile)
+		}
+		remoteReader, err = stream.GetRangeReaderFromLink(size, link)
+		return remoteReader, err
+	}
+	rrc.RangeReader = func(ctx context.Context, httpRange http_range.Range) (io.ReadCloser, error) {
+		if httpRange.Start < 0 || httpRange.Start > size {
+			return nil, fmt.Errorf("range start %d out of file size %d", httpRange.Start, size)
+		}
+		end := size
+		if httpRange.Length >= 0 && httpRange.Start+httpRange.Length < size {
+			end = httpRange.Start + httpRange.Length
+		}
+		return &cachedReader{
+			ctx:             ctx,
+			d:               d,
+			file:            file,
+			getRemoteReader: getRemoteReader,
+			offset:          httpRange.Start,
+			end:             end,
+		}, nil
+	}
+	return &model.Link{RangeReadCloser: rrc}, nil
+}
+
+func (d *Cache) getActualPathForRemote(path string) (driver.Driver, string, error) {
+	return op.GetStorageAndActualPath(stdpath.Join(d.RemotePath, path))
+}
+
+func (d *Cache) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	storage, actualPath, err := d.getActualPathForRemote(parentDir.GetPath())
+	if err != nil {
+		return err
+	}
+	return op.MakeDir(ctx, storage, stdpath.Join(actualPath, dirName))
+}
+
+func (d *Cache) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	storage, srcActualPath, err := d.getActualPathForRemote(srcObj.GetPath())
+	if err != nil {
+		return err
+	}
+	_, dstActualPath, err := d.getActualPathForRemote(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	defer d.store.invalidate(srcObj.GetPath())
+	return op.Move(ctx, storage, srcActualPath, dstActualPath)
+}
+
+func (d *Cache) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	storage, actualPath, err := d.getActualPathForRemote(srcObj.GetPath())
+	if err != nil {
+		return err
+	}
+	defer d.store.invalidate(srcObj.GetPath())
+	return op.Rename(ctx, storage, actualPath, newName)
+}
+
+func (d *Cache) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	storage, srcActualPath, err := d.getActualPathForRemote(srcObj.GetPath())
+	if err != nil {
+		return err
+	}
+	_, dstActualPath, err := d.getActualPathForRemote(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	defer d.store.invalidate(stdpath.Join(dstDir.GetPath(), srcObj.GetName()))
+	return op.Copy(ctx, storage, srcActualPath, dstActualPath)
+}
+
+func (d *Cache) Remove(ctx context.Context, obj model.Obj) error {
+	storage, actualPath, err := d.getActualPathForRemote(obj.GetPath())
+	if err != nil {
+		return err
+	}
+	defer d.store.invalidate(obj.GetPath())
+	return op.Remove(ctx, storage, actualPath)
+}
+
+func (d *Cache) Put(ctx context.Context, dstDir model.Obj, s model.FileStreamer, up driver.UpdateProgress) error {
+	storage, actualPath, err := d.getActualPathForRemote(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	defer d.store.invalidate(stdpath.Join(dstDir.GetPath(), s.GetName()))
+	return op.Put(ctx, storage, actualPath, s, up, false)
+}
+
+var _ driver.Driver = (*Cache)(nil)
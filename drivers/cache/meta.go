@@ -0,0 +1,28 @@
+package cache
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	RemotePath string `json:"remote_path" required:"true" help:"This is the path to be cached"`
+	CacheDir   string `json:"cache_dir" help:"Where to store cached chunks, a sub folder of it is used for each storage, default is under temp_dir"`
+	MaxSize    int    `json:"max_size" type:"number" default:"10240" help:"Max size of the cache in MB"`
+	ChunkSize  int    `json:"chunk_size" type:"number" default:"4" help:"Size of each cached chunk in MB"`
+	TTL        int    `json:"ttl" type:"number" default:"1440" help:"Expiration of cached chunks in minutes"`
+}
+
+var config = driver.Config{
+	Name:        "Cache",
+	LocalSort:   true,
+	OnlyProxy:   true,
+	NoCache:     true,
+	DefaultRoot: "/",
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Cache{}
+	})
+}
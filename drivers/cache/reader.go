@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// cachedReader reads [offset, end) of the file chunk by chunk through the store
+type cachedReader struct {
+	ctx             context.Context
+	d               *Cache
+	file            model.Obj
+	getRemoteReader func(ctx context.Context) (model.RangeReaderFunc, error)
+
+	offset, end int64
+	cur         *os.File
+	curIdx      int64
+}
+
+func (r *cachedReader) chunkKey(idx int64) string {
+	// the key changes with the file, so stale chunks are never hit
+	return fmt.Sprintf("%s_%d_%d_%d", utils.GetMD5EncodeStr(r.file.GetPath()),
+		r.file.GetSize(), r.file.ModTime().Unix(), idx)
+}
+
+func (r *cachedReader) fill(idx int64) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		rangeReader, err := r.getRemoteReader(r.ctx)
+		if err != nil {
+			return err
+		}
+		start := idx * r.d.chunkSize
+		length := utils.Min(r.d.chunkSize, r.file.GetSize()-start)
+		rc, err := rangeReader(r.ctx, http_range.Range{Start: start, Length: length})
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		n, err := io.CopyN(w, rc, length)
+		if err != nil {
+			return fmt.Errorf("failed read chunk %d, read %d of %d bytes: %w", idx, n, length, err)
+		}
+		return nil
+	}
+}
+
+func (r *cachedReader) openChunk(idx int64) (*os.File, error) {
+	var err error
+	// the chunk may be evicted between fetch and open, so try again
+	for i := 0; i < 2; i++ {
+		var p string
+		p, err = r.d.store.fetch(r.chunkKey(idx), r.file.GetPath(), r.fill(idx))
+		if err != nil {
+			return nil, err
+		}
+		var f *os.File
+		f, err = os.Open(p)
+		if err == nil {
+			return f, nil
+		}
+	}
+	return nil, err
+}
+
+func (r *cachedReader) Read(p []byte) (int, error) {
+	if r.offset >= r.end {
+		return 0, io.EOF
+	}
+	select {
+	case <-r.ctx.Done():
+		return 0, r.ctx.Err()
+	default:
+	}
+	idx := r.offset / r.d.chunkSize
+	if r.cur == nil || r.curIdx != idx {
+		if r.cur != nil {
+			_ = r.cur.Close()
+			r.cur = nil
+		}
+		f, err := r.openChunk(idx)
+		if err != nil {
+			return 0, err
+		}
+		if _, err = f.Seek(r.offset-idx*r.d.chunkSize, io.SeekStart); err != nil {
+			_ = f.Close()
+			return 0, err
+		}
+		r.cur, r.curIdx = f, idx
+	}
+	if remain := r.end - r.offset; int64(len(p)) > remain {
+		p = p[:remain]
+	}
+	n, err := r.cur.Read(p)
+	r.offset += int64(n)
+	if err == io.EOF {
+		if n == 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		// continue with next chunk
+		err = nil
+	}
+	return n, err
+}
+
+func (r *cachedReader) Close() error {
+	if r.cur != nil {
+		err := r.cur.Close()
+		r.cur = nil
+		return err
+	}
+	return nil
+}
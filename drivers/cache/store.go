@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"container/list"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/pkg/singleflight"
+)
+
+type chunk struct {
+	key string
+	// path of the file in storage, used to invalidate
+	path    string
+	size    int64
+	created time.Time
+}
+
+// store is an on-disk LRU cache of file chunks
+type store struct {
+	dir     string
+	maxSize int64
+	ttl     time.Duration
+
+	mu     sync.Mutex
+	lru    *list.List
+	chunks map[string]*list.Element
+	size   int64
+	g      singleflight.Group[string]
+}
+
+// newStore creates the store in dir, which must be owned by it as it's removed on start and drop
+func newStore(dir string, maxSize int64, ttl time.Duration) (*store, error) {
+	// chunks of last run are dropped since we don't know which files they belong to
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, err
+	}
+	return &store{
+		dir:     dir,
+		maxSize: maxSize,
+		ttl:     ttl,
+		lru:     list.New(),
+		chunks:  make(map[string]*list.Element),
+	}, nil
+}
+
+func (s *store) filePath(key string) string {
+	return filepath.Join(s.dir, key)
+}
+
+func (s *store) get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.chunks[key]
+	if !ok {
+		return "", false
+	}
+	if s.ttl > 0 && time.Since(e.Value.(*chunk).created) > s.ttl {
+		s.remove(e)
+		return "", false
+	}
+	s.lru.MoveToFront(e)
+	return s.filePath(key), true
+}
+
+// fetch returns the file of the chunk, the chunk is filled by fill if it's not cached
+func (s *store) fetch(key, path string, fill func(w io.Writer) error) (string, error) {
+	if p, ok := s.get(key); ok {
+		return p, nil
+	}
+	p, err, _ := s.g.Do(key, func() (string, error) {
+		if p, ok := s.get(key); ok {
+			return p, nil
+		}
+		tmp, err := os.CreateTemp(s.dir, "tmp-*")
+		if err != nil {
+			return "", err
+		}
+		err = fill(tmp)
+		_ = tmp.Close()
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+			return "", err
+		}
+		info, err := os.Stat(tmp.Name())
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+			return "", err
+		}
+		if err = os.Rename(tmp.Name(), s.filePath(key)); err != nil {
+			_ = os.Remove(tmp.Name())
+			return "", err
+		}
+		s.add(&chunk{key: key, path: path, size: info.Size(), created: time.Now()})
+		return s.filePath(key), nil
+	})
+	return p, err
+}
+
+func (s *store) add(c *chunk) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.chunks[c.key]; ok {
+		s.size -= e.Value.(*chunk).size
+		s.lru.Remove(e)
+	}
+	s.chunks[c.key] = s.lru.PushFront(c)
+	s.size += c.size
+	// keep the newest chunk even if it's larger than max size
+	for s.maxSize > 0 && s.size > s.maxSize && s.lru.Len() > 1 {
+		s.remove(s.lru.Back())
+	}
+}
+
+func (s *store) remove(e *list.Element) {
+	c := e.Value.(*chunk)
+	s.lru.Remove(e)
+	delete(s.chunks, c.key)
+	s.size -= c.size
+	_ = os.Remove(s.filePath(c.key))
+}
+
+// invalidate removes chunks of the file or all files under the folder
+func (s *store) invalidate(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	for _, e := range s.chunks {
+		c := e.Value.(*chunk)
+		if c.path == path || strings.HasPrefix(c.path, prefix) {
+			s.remove(e)
+		}
+	}
+}
+
+func (s *store) drop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lru.Init()
+	s.chunks = make(map[string]*list.Element)
+	s.size = 0
+	return os.RemoveAll(s.dir)
+}
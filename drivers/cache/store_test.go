@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func fillWith(data string) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		_, err := io.Copy(w, bytes.NewBufferString(data))
+		return err
+	}
+}
+
+func TestStore(t *testing.T) {
+	s, err := newStore(t.TempDir()+"/cache", 8, 0)
+	if err != nil {
+		t.Fatalf("failed to create store: %+v", err)
+	}
+	if _, err = s.fetch("a", "/dir/a", fillWith("aaaa")); err != nil {
+		t.Fatalf("failed to fetch: %+v", err)
+	}
+	if _, err = s.fetch("b", "/dir/b", fillWith("bbbb")); err != nil {
+		t.Fatalf("failed to fetch: %+v", err)
+	}
+	// touch a, then b should be evicted by c
+	if _, ok := s.get("a"); !ok {
+		t.Errorf("expect a cached")
+	}
+	if _, err = s.fetch("c", "/c", fillWith("cccc")); err != nil {
+		t.Fatalf("failed to fetch: %+v", err)
+	}
+	if _, ok := s.get("b"); ok {
+		t.Errorf("expect b evicted")
+	}
+	if s.size != 8 {
+		t.Errorf("expect size 8, got %d", s.size)
+	}
+	s.invalidate("/dir")
+	if _, ok := s.get("a"); ok {
+		t.Errorf("expect a invalidated")
+	}
+	if _, ok := s.get("c"); !ok {
+		t.Errorf("expect c cached")
+	}
+}
+
+func TestStoreDir(t *testing.T) {
+	cacheDir := t.TempDir()
+	kept := filepath.Join(cacheDir, "kept.txt")
+	if err := os.WriteFile(kept, []byte("1"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	d := &Cache{Addition: Addition{CacheDir: cacheDir}}
+	d.ID = 3
+	if dir := d.storeDir(); filepath.Dir(dir) != cacheDir {
+		t.Fatalf("expect a sub folder of cache_dir, got %s", dir)
+	}
+	s, err := newStore(d.storeDir(), 8, 0)
+	if err != nil {
+		t.Fatalf("failed to create store: %+v", err)
+	}
+	if _, err = s.fetch("a", "/a", fillWith("aaaa")); err != nil {
+		t.Fatalf("failed to fetch: %+v", err)
+	}
+	if err = s.drop(); err != nil {
+		t.Fatalf("failed to drop: %+v", err)
+	}
+	if _, err = os.Stat(kept); err != nil {
+		t.Errorf("expect the files in cache_dir kept: %+v", err)
+	}
+}
@@ -0,0 +1,333 @@
+package chunker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+type Chunker struct {
+	model.Storage
+	Addition
+	chunkSize int64
+}
+
+func (d *Chunker) Config() driver.Config {
+	return config
+}
+
+func (d *Chunker) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Chunker) Init(ctx context.Context) error {
+	if d.RemotePath == "" {
+		return fmt.Errorf("remote_path is required")
+	}
+	if d.ChunkSize <= 0 {
+		return fmt.Errorf("chunk_size must be greater than 0")
+	}
+	d.RemotePath = utils.FixAndCleanPath(d.RemotePath)
+	d.chunkSize = int64(d.ChunkSize) * 1024 * 1024
+	return nil
+}
+
+func (d *Chunker) Drop(ctx context.Context) error {
+	return nil
+}
+
+func (d *Chunker) Get(ctx context.Context, path string) (model.Obj, error) {
+	if utils.PathEqual(path, "/") {
+		return &model.Object{
+			Name:     "Root",
+			IsFolder: true,
+			Path:     "/",
+		}, nil
+	}
+	dir, name := stdpath.Split(path)
+	objs, err := d.List(ctx, &model.Object{Path: dir, IsFolder: true}, model.ListArgs{})
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objs {
+		if obj.GetName() == name {
+			return obj, nil
+		}
+	}
+	return nil, errs.ObjectNotFound
+}
+
+func (d *Chunker) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	plain, files, err := d.list(ctx, dir.GetPath(), args.Refresh)
+	if err != nil {
+		return nil, err
+	}
+	objs := make([]model.Obj, 0, len(plain)+len(files))
+	for _, obj := range plain {
+		objs = append(objs, &model.Object{
+			Path:     stdpath.Join(dir.GetPath(), obj.GetName()),
+			Name:     obj.GetName(),
+			Size:     obj.GetSize(),
+			Modified: obj.ModTime(),
+			Ctime:    obj.CreateTime(),
+			IsFolder: obj.IsDir(),
+			HashInfo: obj.GetHash(),
+		})
+	}
+	for _, f := range files {
+		objs = append(objs, &model.Object{
+			Path:     stdpath.Join(dir.GetPath(), f.name),
+			Name:     f.name,
+			Size:     f.size(),
+			Modified: f.manifest.ModTime(),
+			Ctime:    f.manifest.CreateTime(),
+		})
+	}
+	return objs, nil
+}
+
+func (d *Chunker) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	dir := stdpath.Dir(file.GetPath())
+	f, err := d.getChunkedFile(ctx, file.GetPath())
+	if errs.IsObjectNotFound(err) {
+		// small files are stored as is
+		storage, actualPath, err := d.getStorageAndActualPath(file.GetPath())
+		if err != nil {
+			return nil, err
+		}
+		link, _, err := op.Link(ctx, storage, actualPath, args)
+		return link, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err = d.readManifest(ctx, dir, f); err != nil {
+		return nil, err
+	}
+	size := f.size()
+	rangeReader := func(ctx context.Context, httpRange http_range.Range) (io.ReadCloser, error) {
+		if httpRange.Start < 0 || httpRange.Start > size {
+			return nil, fmt.Errorf("range start %d out of file size %d", httpRange.Start, size)
+		}
+		end := size
+		if httpRange.Length >= 0 && httpRange.Start+httpRange.Length < size {
+			end = httpRange.Start + httpRange.Length
+		}
+		return &chunkedReader{
+			f:      f,
+			offset: httpRange.Start,
+			end:    end,
+			readChunk: func(c model.Obj, r http_range.Range) (io.ReadCloser, error) {
+				return d.rangeRead(ctx, stdpath.Join(dir, c.GetName()), c.GetSize(), r)
+			},
+		}, nil
+	}
+	return &model.Link{
+		RangeReadCloser: &model.RangeReadCloser{RangeReader: rangeReader},
+	}, nil
+}
+
+func (d *Chunker) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	storage, actualPath, err := d.getStorageAndActualPath(parentDir.GetPath())
+	if err != nil {
+		return err
+	}
+	return op.MakeDir(ctx, storage, stdpath.Join(actualPath, dirName))
+}
+
+// names of all remote objs of the obj
+func (d *Chunker) remoteNames(ctx context.Context, obj model.Obj) ([]string, error) {
+	if obj.IsDir() {
+		return []string{obj.GetName()}, nil
+	}
+	f, err := d.getChunkedFile(ctx, obj.GetPath())
+	if errs.IsObjectNotFound(err) {
+		return []string{obj.GetName()}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := utils.MustSliceConvert(f.chunks, func(src model.Obj) string {
+		return src.GetName()
+	})
+	// manifest goes last, so a half moved file is hidden instead of broken
+	return append(names, f.manifest.GetName()), nil
+}
+
+func (d *Chunker) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.moveOrCopy(ctx, srcObj, dstDir, op.Move)
+}
+
+func (d *Chunker) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.moveOrCopy(ctx, srcObj, dstDir, op.Copy)
+}
+
+func (d *Chunker) moveOrCopy(ctx context.Context, srcObj, dstDir model.Obj,
+	fn func(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string, lazyCache ...bool) error) error {
+	srcStorage, srcActualDir, err := d.getStorageAndActualPath(stdpath.Dir(srcObj.GetPath()))
+	if err != nil {
+		return err
+	}
+	dstStorage, dstActualDir, err := d.getStorageAndActualPath(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	if srcStorage.GetStorage().MountPath != dstStorage.GetStorage().MountPath {
+		return errs.MoveBetweenTwoStorages
+	}
+	names, err := d.remoteNames(ctx, srcObj)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err = fn(ctx, srcStorage, stdpath.Join(srcActualDir, name), dstActualDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Chunker) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	storage, actualDir, err := d.getStorageAndActualPath(stdpath.Dir(srcObj.GetPath()))
+	if err != nil {
+		return err
+	}
+	if srcObj.IsDir() {
+		return op.Rename(ctx, storage, stdpath.Join(actualDir, srcObj.GetName()), newName)
+	}
+	f, err := d.getChunkedFile(ctx, srcObj.GetPath())
+	if errs.IsObjectNotFound(err) {
+		return op.Rename(ctx, storage, stdpath.Join(actualDir, srcObj.GetName()), newName)
+	}
+	if err != nil {
+		return err
+	}
+	for i, c := range f.chunks {
+		if err = op.Rename(ctx, storage, stdpath.Join(actualDir, c.GetName()), chunkName(newName, i)); err != nil {
+			return err
+		}
+	}
+	return op.Rename(ctx, storage, stdpath.Join(actualDir, f.manifest.GetName()), manifestName(newName))
+}
+
+func (d *Chunker) Remove(ctx context.Context, obj model.Obj) error {
+	storage, actualDir, err := d.getStorageAndActualPath(stdpath.Dir(obj.GetPath()))
+	if err != nil {
+		return err
+	}
+	names, err := d.remoteNames(ctx, obj)
+	if err != nil {
+		return err
+	}
+	// remove manifest first, so a half removed file is hidden instead of broken
+	names = append(names[len(names)-1:], names[:len(names)-1]...)
+	for _, name := range names {
+		if err = op.Remove(ctx, storage, stdpath.Join(actualDir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Chunker) Put(ctx context.Context, dstDir model.Obj, s model.FileStreamer, up driver.UpdateProgress) error {
+	storage, actualDir, err := d.getStorageAndActualPath(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	size := s.GetSize()
+	if size <= d.chunkSize {
+		if err = op.Put(ctx, storage, actualDir, s, up, false); err != nil {
+			return err
+		}
+		d.removeStaleChunks(ctx, storage, actualDir, dstDir.GetPath(), s.GetName(), 0)
+		return nil
+	}
+	count := int((size + d.chunkSize - 1) / d.chunkSize)
+	for i := 0; i < count; i++ {
+		chunkSize := utils.Min(d.chunkSize, size-int64(i)*d.chunkSize)
+		chunk := &stream.FileStream{
+			Ctx: ctx,
+			Obj: &model.Object{
+				Name:     chunkName(s.GetName(), i),
+				Size:     chunkSize,
+				Modified: s.ModTime(),
+			},
+			Reader:            io.LimitReader(s, chunkSize),
+			Mimetype:          "application/octet-stream",
+			WebPutAsTask:      s.NeedStore(),
+			ForceStreamUpload: true,
+		}
+		i := i
+		err = op.Put(ctx, storage, actualDir, chunk, func(p float64) {
+			up((float64(i) + p/100) * 100 / float64(count))
+		}, false)
+		if err != nil {
+			return fmt.Errorf("failed to upload chunk %d: %w", i, err)
+		}
+	}
+	data, err := utils.Json.Marshal(manifest{
+		Version:    manifestVersion,
+		Size:       size,
+		ChunkSize:  d.chunkSize,
+		ChunkCount: count,
+	})
+	if err != nil {
+		return err
+	}
+	err = op.Put(ctx, storage, actualDir, &stream.FileStream{
+		Ctx: ctx,
+		Obj: &model.Object{
+			Name:     manifestName(s.GetName()),
+			Size:     int64(len(data)),
+			Modified: s.ModTime(),
+		},
+		Reader:   bytes.NewReader(data),
+		Mimetype: "application/json",
+	}, nil, false)
+	if err != nil {
+		return fmt.Errorf("failed to upload manifest: %w", err)
+	}
+	// the old file may have more chunks, or chunks left by a failed upload
+	d.removeStaleChunks(ctx, storage, actualDir, dstDir.GetPath(), s.GetName(), count)
+	// the file may be stored as is before
+	if _, err = op.Get(ctx, storage, stdpath.Join(actualDir, s.GetName())); err == nil {
+		if err = op.Remove(ctx, storage, stdpath.Join(actualDir, s.GetName())); err != nil {
+			log.Warnf("failed to remove old file %s: %+v", s.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// removeStaleChunks removes the chunks of the file name in dir from index from,
+// and the manifest if all chunks are removed
+func (d *Chunker) removeStaleChunks(ctx context.Context, storage driver.Driver, actualDir, dir, name string, from int) {
+	objs, err := fs.List(ctx, d.getRemotePath(dir), &fs.ListArgs{NoLog: true})
+	if err != nil {
+		log.Warnf("failed to list stale chunks of %s: %+v", name, err)
+		return
+	}
+	for _, obj := range objs {
+		objName := obj.GetName()
+		if base, idx, ok := parseChunkName(objName); !(ok && base == name && idx >= from) &&
+			!(from == 0 && objName == manifestName(name)) {
+			continue
+		}
+		if err = op.Remove(ctx, storage, stdpath.Join(actualDir, objName)); err != nil {
+			log.Warnf("failed to remove stale chunk %s: %+v", objName, err)
+		}
+	}
+}
+
+var _ driver.Driver = (*Chunker)(nil)
@@ -0,0 +1,25 @@
+package chunker
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	RemotePath string `json:"remote_path" required:"true" help:"This is where the chunks stores"`
+	ChunkSize  int    `json:"chunk_size" type:"number" required:"true" default:"100" help:"Files larger than it are split into chunks, in MB"`
+}
+
+var config = driver.Config{
+	Name:        "Chunker",
+	LocalSort:   true,
+	OnlyProxy:   true,
+	NoCache:     true,
+	DefaultRoot: "/",
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Chunker{}
+	})
+}
@@ -0,0 +1,29 @@
+package chunker
+
+import "github.com/alist-org/alist/v3/internal/model"
+
+const manifestVersion = 1
+
+// manifest is stored beside the chunks of a file
+type manifest struct {
+	Version    int   `json:"version"`
+	Size       int64 `json:"size"`
+	ChunkSize  int64 `json:"chunk_size"`
+	ChunkCount int   `json:"chunk_count"`
+}
+
+// chunkedFile is a file split into chunks in the remote storage
+type chunkedFile struct {
+	name string
+	// sorted by index
+	chunks   []model.Obj
+	manifest model.Obj
+}
+
+func (f *chunkedFile) size() int64 {
+	var size int64
+	for _, c := range f.chunks {
+		size += c.GetSize()
+	}
+	return size
+}
@@ -0,0 +1,225 @@
+package chunker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	stdpath "path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+const (
+	chunkSuffix    = ".alist_chunk_"
+	manifestSuffix = ".alist_chunk_manifest"
+)
+
+var chunkRegexp = regexp.MustCompile(`^(.+)\.alist_chunk_(\d{3,})$`)
+
+func chunkName(name string, idx int) string {
+	return fmt.Sprintf("%s%s%03d", name, chunkSuffix, idx)
+}
+
+// parseChunkName returns the name of the file and the index of the chunk
+func parseChunkName(name string) (string, int, bool) {
+	m := chunkRegexp.FindStringSubmatch(name)
+	if m == nil {
+		return "", 0, false
+	}
+	idx, err := strconv.Atoi(m[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return m[1], idx, true
+}
+
+func manifestName(name string) string {
+	return name + manifestSuffix
+}
+
+func (d *Chunker) getRemotePath(path string) string {
+	return stdpath.Join(d.RemotePath, path)
+}
+
+func (d *Chunker) getStorageAndActualPath(path string) (driver.Driver, string, error) {
+	return op.GetStorageAndActualPath(d.getRemotePath(path))
+}
+
+// list the remote folder, chunks and manifests are merged into chunked files
+func (d *Chunker) list(ctx context.Context, dir string, refresh bool) ([]model.Obj, map[string]*chunkedFile, error) {
+	objs, err := fs.List(ctx, d.getRemotePath(dir), &fs.ListArgs{NoLog: true, Refresh: refresh})
+	if err != nil {
+		return nil, nil, err
+	}
+	type indexed struct {
+		idx int
+		obj model.Obj
+	}
+	chunks := make(map[string][]indexed)
+	manifests := make(map[string]model.Obj)
+	var plain []model.Obj
+	for _, obj := range objs {
+		name := obj.GetName()
+		if obj.IsDir() {
+			plain = append(plain, obj)
+			continue
+		}
+		if strings.HasSuffix(name, manifestSuffix) {
+			manifests[strings.TrimSuffix(name, manifestSuffix)] = obj
+			continue
+		}
+		if base, idx, ok := parseChunkName(name); ok {
+			chunks[base] = append(chunks[base], indexed{idx: idx, obj: obj})
+			continue
+		}
+		plain = append(plain, obj)
+	}
+	files := make(map[string]*chunkedFile)
+	for name, m := range manifests {
+		cs := chunks[name]
+		sort.Slice(cs, func(i, j int) bool {
+			return cs[i].idx < cs[j].idx
+		})
+		files[name] = &chunkedFile{
+			name:     name,
+			manifest: m,
+			chunks: utils.MustSliceConvert(cs, func(src indexed) model.Obj {
+				return src.obj
+			}),
+		}
+	}
+	// chunks without manifest are left by failed uploads, just hide them
+	return plain, files, nil
+}
+
+func (d *Chunker) getChunkedFile(ctx context.Context, path string) (*chunkedFile, error) {
+	dir, name := stdpath.Split(path)
+	_, files, err := d.list(ctx, dir, false)
+	if err != nil {
+		return nil, err
+	}
+	f, ok := files[name]
+	if !ok {
+		return nil, errs.ObjectNotFound
+	}
+	return f, nil
+}
+
+func (d *Chunker) readManifest(ctx context.Context, dir string, f *chunkedFile) (*manifest, error) {
+	data, err := d.readAll(ctx, stdpath.Join(dir, f.manifest.GetName()), f.manifest.GetSize())
+	if err != nil {
+		return nil, err
+	}
+	var m manifest
+	if err = utils.Json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest of %s: %w", f.name, err)
+	}
+	if m.ChunkCount != len(f.chunks) {
+		return nil, fmt.Errorf("%s expects %d chunks, but found %d", f.name, m.ChunkCount, len(f.chunks))
+	}
+	if m.Size != f.size() {
+		return nil, fmt.Errorf("%s expects size %d, but chunks size is %d", f.name, m.Size, f.size())
+	}
+	return &m, nil
+}
+
+func (d *Chunker) readAll(ctx context.Context, path string, size int64) ([]byte, error) {
+	rc, err := d.rangeRead(ctx, path, size, http_range.Range{Start: 0, Length: size})
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func (d *Chunker) rangeRead(ctx context.Context, path string, size int64, r http_range.Range) (io.ReadCloser, error) {
+	storage, actualPath, err := d.getStorageAndActualPath(path)
+	if err != nil {
+		return nil, err
+	}
+	link, _, err := op.Link(ctx, storage, actualPath, model.LinkArgs{})
+	if err != nil {
+		return nil, err
+	}
+	rangeReader, err := stream.GetRangeReaderFromLink(size, link)
+	if err != nil {
+		return nil, err
+	}
+	return rangeReader(ctx, r)
+}
+
+// chunkedReader reads [offset, end) of a chunked file, opens chunks one by one
+type chunkedReader struct {
+	f           *chunkedFile
+	offset, end int64
+	cur         io.ReadCloser
+	curRemain   int64
+	// readChunk reads the range of the chunk
+	readChunk func(c model.Obj, r http_range.Range) (io.ReadCloser, error)
+}
+
+func (r *chunkedReader) Read(p []byte) (int, error) {
+	if r.offset >= r.end {
+		return 0, io.EOF
+	}
+	if r.cur == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	if int64(len(p)) > r.curRemain {
+		p = p[:r.curRemain]
+	}
+	n, err := r.cur.Read(p)
+	r.offset += int64(n)
+	r.curRemain -= int64(n)
+	if err == io.EOF && r.curRemain > 0 {
+		return n, io.ErrUnexpectedEOF
+	}
+	if r.curRemain <= 0 {
+		// continue with next chunk
+		_ = r.cur.Close()
+		r.cur = nil
+		err = nil
+	}
+	return n, err
+}
+
+// open the chunk which contains offset
+func (r *chunkedReader) open() error {
+	var start int64
+	for _, c := range r.f.chunks {
+		if r.offset < start+c.GetSize() {
+			offset := r.offset - start
+			length := utils.Min(c.GetSize()-offset, r.end-r.offset)
+			rc, err := r.readChunk(c, http_range.Range{Start: offset, Length: length})
+			if err != nil {
+				return err
+			}
+			r.cur, r.curRemain = rc, length
+			return nil
+		}
+		start += c.GetSize()
+	}
+	return io.ErrUnexpectedEOF
+}
+
+func (r *chunkedReader) Close() error {
+	if r.cur != nil {
+		err := r.cur.Close()
+		r.cur = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package chunker
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+)
+
+func TestParseChunkName(t *testing.T) {
+	tests := []struct {
+		name string
+		base string
+		idx  int
+		ok   bool
+	}{
+		{name: chunkName("a.mkv", 0), base: "a.mkv", idx: 0, ok: true},
+		{name: chunkName("a.alist_chunk_001", 12), base: "a.alist_chunk_001", idx: 12, ok: true},
+		{name: "a.mkv.alist_chunk_1234", base: "a.mkv", idx: 1234, ok: true},
+		{name: "a.mkv.alist_chunk_12"},
+		{name: "a.mkv.alist_chunk_abc"},
+		{name: manifestName("a.mkv")},
+		{name: ".alist_chunk_001"},
+		{name: "a.mkv"},
+	}
+	for _, tt := range tests {
+		base, idx, ok := parseChunkName(tt.name)
+		if base != tt.base || idx != tt.idx || ok != tt.ok {
+			t.Errorf("%s: expect %s %d %v, got %s %d %v", tt.name, tt.base, tt.idx, tt.ok, base, idx, ok)
+		}
+	}
+}
+
+func TestChunkedReader(t *testing.T) {
+	data := map[string]string{
+		chunkName("f", 0): "0123",
+		chunkName("f", 1): "4567",
+		chunkName("f", 2): "89",
+	}
+	f := &chunkedFile{name: "f"}
+	for i := 0; i < 3; i++ {
+		name := chunkName("f", i)
+		f.chunks = append(f.chunks, &model.Object{Name: name, Size: int64(len(data[name]))})
+	}
+	tests := []struct {
+		offset, end int64
+		expect      string
+		opened      int
+	}{
+		{offset: 0, end: 10, expect: "0123456789", opened: 3},
+		{offset: 2, end: 6, expect: "2345", opened: 2},
+		{offset: 4, end: 8, expect: "4567", opened: 1},
+		{offset: 5, end: 6, expect: "5", opened: 1},
+		{offset: 7, end: 10, expect: "789", opened: 2},
+		{offset: 10, end: 10, expect: "", opened: 0},
+	}
+	for _, tt := range tests {
+		opened := 0
+		r := &chunkedReader{
+			f:      f,
+			offset: tt.offset,
+			end:    tt.end,
+			readChunk: func(c model.Obj, rng http_range.Range) (io.ReadCloser, error) {
+				opened++
+				content := data[c.GetName()]
+				if rng.Start < 0 || rng.Length <= 0 || rng.Start+rng.Length > int64(len(content)) {
+					t.Fatalf("range %+v out of chunk %s", rng, c.GetName())
+				}
+				return io.NopCloser(bytes.NewBufferString(content[rng.Start : rng.Start+rng.Length])), nil
+			},
+		}
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("[%d, %d): failed read: %+v", tt.offset, tt.end, err)
+		}
+		if string(got) != tt.expect || opened != tt.opened {
+			t.Errorf("[%d, %d): expect %q with %d chunks opened, got %q with %d", tt.offset, tt.end, tt.expect, tt.opened, got, opened)
+		}
+		_ = r.Close()
+	}
+}
@@ -10,12 +10,12 @@ const (
 )
 
 type Addition struct {
-	RefreshToken string `json:"refresh_token" required:"true"`
+	RefreshToken string `json:"refresh_token" confidential:"true" required:"true"`
 	driver.RootPath
 
 	OauthTokenURL string `json:"oauth_token_url" default:"https://api.xhofe.top/alist/dropbox/token"`
 	ClientID      string `json:"client_id" required:"false" help:"Keep it empty if you don't have one"`
-	ClientSecret  string `json:"client_secret" required:"false" help:"Keep it empty if you don't have one"`
+	ClientSecret  string `json:"client_secret" confidential:"true" required:"false" help:"Keep it empty if you don't have one"`
 
 	AccessToken     string
 	RootNamespaceId string
@@ -0,0 +1,43 @@
+package google_drive
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/go-resty/resty/v2"
+)
+
+type aboutQuota struct {
+	StorageQuota struct {
+		// int64 in string, absent if the storage is unlimited
+		Limit string `json:"limit"`
+		Usage string `json:"usage"`
+	} `json:"storageQuota"`
+}
+
+// GetSpace returns the storage quota of the user
+func (d *GoogleDrive) GetSpace(ctx context.Context) (*model.StorageSpace, error) {
+	var resp aboutQuota
+	_, err := d.request("https://www.googleapis.com/drive/v3/about", http.MethodGet, func(req *resty.Request) {
+		req.SetQueryParam("fields", "storageQuota")
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StorageQuota.Limit == "" {
+		// unlimited storage always has the most free space
+		return &model.StorageSpace{Free: math.MaxInt64}, nil
+	}
+	total, err := strconv.ParseInt(resp.StorageQuota.Limit, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	used, _ := strconv.ParseInt(resp.StorageQuota.Usage, 10, 64)
+	return &model.StorageSpace{Total: total, Free: total - used}, nil
+}
+
+var _ driver.SpaceGetter = (*GoogleDrive)(nil)
@@ -10,7 +10,7 @@ type Addition struct {
 	driver.RootPath
 	// define other
 	AppId           string `json:"app_id" type:"text" help:"app id"`
-	AppSecret       string `json:"app_secret" type:"text" help:"app secret"`
+	AppSecret       string `json:"app_secret" confidential:"true" type:"text" help:"app secret"`
 	ExternalMode    bool   `json:"external_mode" type:"bool" help:"external mode"`
 	TenantUrlPrefix string `json:"tenant_url_prefix" type:"text" help:"tenant url prefix"`
 }
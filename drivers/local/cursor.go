@@ -0,0 +1,85 @@
+package local
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+)
+
+const (
+	// cursorExpiration is how long an idle cursor is kept open
+	cursorExpiration = 5 * time.Minute
+	// maxCursors bounds the open folders, the oldest cursor is closed beyond it
+	maxCursors = 64
+)
+
+// dirCursor is a folder being listed page by page, ReadDir of the file
+// continues from the previous page, so a huge folder is never read at once
+type dirCursor struct {
+	f    *os.File
+	path string
+	used time.Time
+}
+
+type dirCursors struct {
+	mu sync.Mutex
+	m  map[string]*dirCursor
+}
+
+// take removes the cursor of token, so a page is never listed twice at the same time
+func (cs *dirCursors) take(token string) *dirCursor {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.evict()
+	c, ok := cs.m[token]
+	if !ok {
+		return nil
+	}
+	delete(cs.m, token)
+	return c
+}
+
+// put keeps the cursor for the next page and returns its token
+func (cs *dirCursors) put(c *dirCursor) string {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.m == nil {
+		cs.m = make(map[string]*dirCursor)
+	}
+	cs.evict()
+	if len(cs.m) >= maxCursors {
+		var oldest string
+		for token, c := range cs.m {
+			if oldest == "" || c.used.Before(cs.m[oldest].used) {
+				oldest = token
+			}
+		}
+		_ = cs.m[oldest].f.Close()
+		delete(cs.m, oldest)
+	}
+	c.used = time.Now()
+	token := random.String(16)
+	cs.m[token] = c
+	return token
+}
+
+// evict closes the expired cursors, it's called with mu held
+func (cs *dirCursors) evict() {
+	for token, c := range cs.m {
+		if time.Since(c.used) > cursorExpiration {
+			_ = c.f.Close()
+			delete(cs.m, token)
+		}
+	}
+}
+
+func (cs *dirCursors) close() {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	for _, c := range cs.m {
+		_ = c.f.Close()
+	}
+	cs.m = nil
+}
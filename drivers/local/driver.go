@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"net/http"
 	"os"
@@ -35,6 +36,9 @@ type Local struct {
 	// zero means no limit
 	thumbConcurrency int
 	thumbTokenBucket TokenBucket
+
+	watcher *watcher
+	cursors dirCursors
 }
 
 func (d *Local) Config() driver.Config {
@@ -79,10 +83,27 @@ func (d *Local) Init(ctx context.Context) error {
 	} else {
 		d.thumbTokenBucket = NewStaticTokenBucketWithMigration(d.thumbTokenBucket, d.thumbConcurrency)
 	}
+	if d.watcher != nil {
+		_ = d.watcher.Close()
+		d.watcher = nil
+	}
+	if d.Watch {
+		w, err := newWatcher(d)
+		if err != nil {
+			return err
+		}
+		d.watcher = w
+	}
 	return nil
 }
 
 func (d *Local) Drop(ctx context.Context) error {
+	d.cursors.close()
+	if d.watcher != nil {
+		err := d.watcher.Close()
+		d.watcher = nil
+		return err
+	}
 	return nil
 }
 
@@ -106,6 +127,99 @@ func (d *Local) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([
 	}
 	return files, nil
 }
+
+func (d *Local) ListPage(ctx context.Context, dir model.Obj, args model.ListPageArgs) ([]model.Obj, string, error) {
+	fullPath := dir.GetPath()
+	var c *dirCursor
+	if args.Token == "" {
+		f, err := os.Open(fullPath)
+		if err != nil {
+			return nil, "", err
+		}
+		c = &dirCursor{f: f, path: fullPath}
+	} else if c = d.cursors.take(args.Token); c == nil || c.path != fullPath {
+		if c != nil {
+			_ = c.f.Close()
+		}
+		return nil, "", errors.New("list token expired, please list from the first page")
+	}
+	// the files are in the order of the folder, only sorted inside a page
+	var files []model.Obj
+	for len(files) < args.Limit {
+		entries, err := c.f.ReadDir(args.Limit - len(files))
+		for _, entry := range entries {
+			if !d.ShowHidden && strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			f, err := entry.Info()
+			if err != nil {
+				// removed after read dir
+				if errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				_ = c.f.Close()
+				return nil, "", err
+			}
+			files = append(files, d.FileInfoToObj(f, args.ReqPath, fullPath))
+		}
+		if errors.Is(err, io.EOF) {
+			_ = c.f.Close()
+			return files, "", nil
+		}
+		if err != nil {
+			_ = c.f.Close()
+			return nil, "", err
+		}
+	}
+	return files, d.cursors.put(c), nil
+}
+
+func (d *Local) ListR(ctx context.Context, dir model.Obj, args model.ListArgs, callback driver.ListRCallback) error {
+	return d.listR(ctx, dir.GetPath(), "", args.ReqPath, make(map[string]struct{}), callback)
+}
+
+func (d *Local) listR(ctx context.Context, fullPath, parent, reqPath string, visited map[string]struct{}, callback driver.ListRCallback) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// symlinks are followed, but never list a folder twice in case of loops
+	if realPath, err := filepath.EvalSymlinks(fullPath); err == nil {
+		if _, ok := visited[realPath]; ok {
+			return nil
+		}
+		visited[realPath] = struct{}{}
+	}
+	rawFiles, err := readDir(fullPath)
+	if err != nil {
+		if parent == "" {
+			return err
+		}
+		// skip the sub folder that can't be read, like walking folder by folder does
+		log.Warnf("failed read dir %s: %+v", fullPath, err)
+		return nil
+	}
+	files := make([]model.Obj, 0, len(rawFiles))
+	for _, f := range rawFiles {
+		if !d.ShowHidden && strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		files = append(files, d.FileInfoToObj(f, reqPath, fullPath))
+	}
+	if err = callback(parent, files); err != nil {
+		return err
+	}
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+		err = d.listR(ctx, f.GetPath(), stdpath.Join(parent, f.GetName()), stdpath.Join(reqPath, f.GetName()), visited, callback)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (d *Local) FileInfoToObj(f fs.FileInfo, reqPath string, fullPath string) model.Obj {
 	thumb := ""
 	if d.Thumbnail {
@@ -329,3 +443,5 @@ func (d *Local) Put(ctx context.Context, dstDir model.Obj, stream model.FileStre
 }
 
 var _ driver.Driver = (*Local)(nil)
+var _ driver.ListPager = (*Local)(nil)
+var _ driver.ListR = (*Local)(nil)
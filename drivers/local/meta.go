@@ -13,6 +13,7 @@ type Addition struct {
 	ShowHidden       bool   `json:"show_hidden" default:"true" required:"false" help:"show hidden directories and files"`
 	MkdirPerm        string `json:"mkdir_perm" default:"777"`
 	RecycleBinPath   string `json:"recycle_bin_path" default:"delete permanently" help:"path to recycle bin, delete permanently if empty or keep 'delete permanently'"`
+	Watch            bool   `json:"watch" default:"false" help:"watch changes made by other programs, then refresh cache and search index"`
 }
 
 var config = driver.Config{
@@ -0,0 +1,14 @@
+//go:build !(linux || darwin || freebsd)
+
+package local
+
+import (
+	"context"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func (d *Local) GetSpace(ctx context.Context) (*model.StorageSpace, error) {
+	return nil, errs.NotImplement
+}
@@ -0,0 +1,21 @@
+//go:build linux || darwin || freebsd
+
+package local
+
+import (
+	"context"
+	"syscall"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func (d *Local) GetSpace(ctx context.Context) (*model.StorageSpace, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(d.GetRootPath(), &stat); err != nil {
+		return nil, err
+	}
+	return &model.StorageSpace{
+		Total: int64(stat.Blocks) * int64(stat.Bsize),
+		Free:  int64(stat.Bavail) * int64(stat.Bsize),
+	}, nil
+}
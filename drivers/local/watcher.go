@@ -0,0 +1,149 @@
+package local
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+)
+
+// watcher watches changes made by other programs under the root folder,
+// then clears the list cache and calls objs update hooks of changed folders.
+// Note that network filesystems (e.g. a mounted SMB share) only report
+// changes made through the local mount.
+type watcher struct {
+	d     *Local
+	w     *fsnotify.Watcher
+	flush func()
+
+	mu    sync.Mutex
+	dirty map[string]struct{}
+}
+
+func newWatcher(d *Local) (*watcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	wt := &watcher{
+		d:     d,
+		w:     w,
+		dirty: make(map[string]struct{}),
+	}
+	wt.flush = utils.NewDebounce2(time.Second, wt.refresh)
+	wt.addRecursive(d.GetRootPath())
+	go wt.run()
+	return wt, nil
+}
+
+func (wt *watcher) Close() error {
+	return wt.w.Close()
+}
+
+func (wt *watcher) isHidden(name string) bool {
+	return !wt.d.ShowHidden && strings.HasPrefix(filepath.Base(name), ".")
+}
+
+func (wt *watcher) addRecursive(root string) {
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// skip unreadable folders
+			return nil
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if path != root && wt.isHidden(path) {
+			return filepath.SkipDir
+		}
+		if err := wt.w.Add(path); err != nil {
+			log.Warnf("failed watch %s: %+v", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Warnf("failed walk %s: %+v", root, err)
+	}
+}
+
+func (wt *watcher) run() {
+	for {
+		select {
+		case event, ok := <-wt.w.Events:
+			if !ok {
+				return
+			}
+			wt.handle(event)
+		case err, ok := <-wt.w.Errors:
+			if !ok {
+				return
+			}
+			log.Errorf("local watcher of %s error: %+v", wt.d.MountPath, err)
+		}
+	}
+}
+
+func (wt *watcher) handle(event fsnotify.Event) {
+	if wt.isHidden(event.Name) || event.Op == fsnotify.Chmod {
+		return
+	}
+	log.Debugf("local watcher event: %s", event)
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			wt.addRecursive(event.Name)
+			wt.markDirty(event.Name)
+		}
+	}
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		if p, ok := wt.relPath(event.Name); ok {
+			op.ClearCache(wt.d, p)
+		}
+	}
+	wt.markDirty(filepath.Dir(event.Name))
+}
+
+func (wt *watcher) markDirty(dir string) {
+	p, ok := wt.relPath(dir)
+	if !ok {
+		return
+	}
+	wt.mu.Lock()
+	wt.dirty[p] = struct{}{}
+	wt.mu.Unlock()
+	wt.flush()
+}
+
+func (wt *watcher) relPath(fullPath string) (string, bool) {
+	rel, err := filepath.Rel(wt.d.GetRootPath(), fullPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	return utils.FixAndCleanPath(filepath.ToSlash(rel)), true
+}
+
+func (wt *watcher) refresh() {
+	wt.mu.Lock()
+	dirs := wt.dirty
+	wt.dirty = make(map[string]struct{})
+	wt.mu.Unlock()
+	for dir := range dirs {
+		op.ClearCache(wt.d, dir)
+		// list with refresh calls the objs update hooks, e.g. the search index updater
+		_, err := op.List(context.Background(), wt.d, dir, model.ListArgs{
+			ReqPath: utils.GetFullPath(wt.d.MountPath, dir),
+			Refresh: true,
+		})
+		if err != nil {
+			log.Debugf("local watcher failed list %s: %+v", dir, err)
+		}
+	}
+}
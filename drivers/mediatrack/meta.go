@@ -6,7 +6,7 @@ import (
 )
 
 type Addition struct {
-	AccessToken string `json:"access_token" required:"true"`
+	AccessToken string `json:"access_token" confidential:"true" required:"true"`
 	ProjectID   string `json:"project_id"`
 	driver.RootID
 	OrderBy   string `json:"order_by" type:"select" options:"updated_at,title,size" default:"title"`
@@ -21,4 +21,5 @@ func init() {
 	op.RegisterDriver(func() driver.Driver {
 		return &MediaTrack{}
 	})
+	op.RegisterDiskCacheObj(&Object{})
 }
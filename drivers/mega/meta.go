@@ -10,9 +10,9 @@ type Addition struct {
 	//driver.RootPath
 	//driver.RootID
 	Email       string `json:"email" required:"true"`
-	Password    string `json:"password" required:"true"`
+	Password    string `json:"password" confidential:"true" required:"true"`
 	TwoFACode   string `json:"two_fa_code" required:"false" help:"2FA 6-digit code, filling in the 2FA code alone will not support reloading driver"`
-	TwoFASecret string `json:"two_fa_secret" required:"false" help:"2FA secret"`
+	TwoFASecret string `json:"two_fa_secret" confidential:"true" required:"false" help:"2FA secret"`
 }
 
 var config = driver.Config{
@@ -21,6 +21,7 @@ type Onedrive struct {
 	Addition
 	AccessToken string
 	root        *Object
+	driveID     string
 	mutex       sync.Mutex
 }
 
@@ -191,6 +192,30 @@ func (d *Onedrive) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
 	return err
 }
 
+// IsPeer reports whether dst is a OneDrive storage of the same drive,
+// the root folders can be different
+func (d *Onedrive) IsPeer(dst driver.Driver) bool {
+	p, ok := dst.(*Onedrive)
+	if !ok || p.Region != d.Region {
+		return false
+	}
+	id, err := d.getDriveID()
+	if err != nil {
+		return false
+	}
+	peerID, err := p.getDriveID()
+	return err == nil && id == peerID
+}
+
+// PeerCopy works as Copy, as the paths and ids of a peer are of the same drive
+func (d *Onedrive) PeerCopy(ctx context.Context, srcObj model.Obj, dst driver.Driver, dstDir model.Obj) error {
+	return d.Copy(ctx, srcObj, dstDir)
+}
+
+func (d *Onedrive) PeerMove(ctx context.Context, srcObj model.Obj, dst driver.Driver, dstDir model.Obj) error {
+	return d.Move(ctx, srcObj, dstDir)
+}
+
 func (d *Onedrive) Remove(ctx context.Context, obj model.Obj) error {
 	url := d.GetMetaUrl(false, obj.GetPath())
 	_, err := d.Request(url, http.MethodDelete, nil, nil)
@@ -207,4 +232,40 @@ func (d *Onedrive) Put(ctx context.Context, dstDir model.Obj, stream model.FileS
 	return err
 }
 
+func (d *Onedrive) CreateUploadSession(ctx context.Context, dstDir model.Obj, args model.UploadSessionArgs) (*model.UploadSession, error) {
+	// ApiDoc: https://learn.microsoft.com/en-us/onedrive/developer/rest-api/api/driveitem_createuploadsession?view=odsp-graph-online
+	url := d.GetMetaUrl(false, path.Join(dstDir.GetPath(), args.Name)) + "/createUploadSession"
+	var resp UploadSessionResp
+	_, err := d.Request(url, http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"item": base.Json{"@microsoft.graph.conflictBehavior": "replace"},
+		}).SetContext(ctx)
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	// the upload url needs no authorization, all parts are put to it with Content-Range
+	return &model.UploadSession{
+		Method:      http.MethodPut,
+		URLs:        []string{resp.UploadUrl},
+		PartSize:    d.ChunkSize * 1024 * 1024,
+		RangeHeader: true,
+		Expires:     resp.ExpirationDateTime,
+	}, nil
+}
+
+func (d *Onedrive) CompleteUploadSession(ctx context.Context, dstDir model.Obj, args model.UploadCompleteArgs) (model.Obj, error) {
+	// onedrive commits the file after the last part is uploaded, just check it
+	file, err := d.GetFile(path.Join(dstDir.GetPath(), args.Name))
+	if err != nil {
+		return nil, err
+	}
+	if file.Size != args.Size {
+		return nil, fmt.Errorf("size of uploaded file mismatch, expect %d, got %d", args.Size, file.Size)
+	}
+	return fileToObj(*file, dstDir.GetID()), nil
+}
+
 var _ driver.Driver = (*Onedrive)(nil)
+var _ driver.DirectUpload = (*Onedrive)(nil)
+var _ driver.Peer = (*Onedrive)(nil)
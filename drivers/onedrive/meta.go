@@ -10,9 +10,9 @@ type Addition struct {
 	Region       string `json:"region" type:"select" required:"true" options:"global,cn,us,de" default:"global"`
 	IsSharepoint bool   `json:"is_sharepoint"`
 	ClientID     string `json:"client_id" required:"true"`
-	ClientSecret string `json:"client_secret" required:"true"`
+	ClientSecret string `json:"client_secret" confidential:"true" required:"true"`
 	RedirectUri  string `json:"redirect_uri" required:"true" default:"https://alist.nn.ci/tool/onedrive/callback"`
-	RefreshToken string `json:"refresh_token" required:"true"`
+	RefreshToken string `json:"refresh_token" confidential:"true" required:"true"`
 	SiteId       string `json:"site_id"`
 	ChunkSize    int64  `json:"chunk_size" type:"number" default:"5"`
 	CustomHost   string `json:"custom_host" help:"Custom host for onedrive download link"`
@@ -28,4 +28,5 @@ func init() {
 	op.RegisterDriver(func() driver.Driver {
 		return &Onedrive{}
 	})
+	op.RegisterDiskCacheObj(&Object{})
 }
@@ -0,0 +1,34 @@
+package onedrive
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+type driveQuota struct {
+	Quota struct {
+		Total     int64 `json:"total"`
+		Remaining int64 `json:"remaining"`
+	} `json:"quota"`
+}
+
+// GetSpace returns the quota of the drive
+// ApiDoc: https://learn.microsoft.com/en-us/onedrive/developer/rest-api/api/drive_get
+func (d *Onedrive) GetSpace(ctx context.Context) (*model.StorageSpace, error) {
+	host := onedriveHostMap[d.Region]
+	url := fmt.Sprintf("%s/v1.0/me/drive", host.Api)
+	if d.IsSharepoint {
+		url = fmt.Sprintf("%s/v1.0/sites/%s/drive", host.Api, d.SiteId)
+	}
+	var resp driveQuota
+	if _, err := d.Request(url, http.MethodGet, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &model.StorageSpace{Total: resp.Quota.Total, Free: resp.Quota.Remaining}, nil
+}
+
+var _ driver.SpaceGetter = (*Onedrive)(nil)
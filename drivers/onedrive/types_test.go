@@ -0,0 +1,36 @@
+package onedrive
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestObjectDiskCache(t *testing.T) {
+	var f File
+	err := json.Unmarshal([]byte(`{"id":"id","name":"a.txt","size":10,"file":{},
+		"fileSystemInfo":{"lastModifiedDateTime":"2024-01-02T03:04:05Z"},
+		"thumbnails":[{"medium":{"url":"https://thumb"}}]}`), &f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode([]model.Obj{fileToObj(f, "parent")}); err != nil {
+		t.Fatalf("failed encode: %+v", err)
+	}
+	var objs []model.Obj
+	if err := gob.NewDecoder(&buf).Decode(&objs); err != nil {
+		t.Fatalf("failed decode: %+v", err)
+	}
+	obj, ok := objs[0].(*Object)
+	if !ok {
+		t.Fatalf("decoded %T, want *Object", objs[0])
+	}
+	if obj.ID != "id" || obj.Name != "a.txt" || obj.Size != 10 || obj.IsDir() || obj.ParentID != "parent" ||
+		obj.Thumbnail.Thumbnail != "https://thumb" || !obj.ModTime().Equal(f.FileSystemInfo.LastModifiedDateTime) {
+		t.Errorf("decoded %+v", obj)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	stdpath "path"
 	"strconv"
+	"strings"
 
 	"github.com/alist-org/alist/v3/drivers/base"
 	"github.com/alist-org/alist/v3/internal/driver"
@@ -125,6 +126,25 @@ func (d *Onedrive) Request(url string, method string, callback base.ReqCallback,
 	return res.Body(), nil
 }
 
+// getDriveID gets the id of the drive the storage is mounted from, it's cached once got
+func (d *Onedrive) getDriveID() (string, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.driveID != "" {
+		return d.driveID, nil
+	}
+	var resp struct {
+		Id string `json:"id"`
+	}
+	url := strings.TrimSuffix(d.GetMetaUrl(false, "/"), "/root")
+	_, err := d.Request(url+"?$select=id", http.MethodGet, nil, &resp)
+	if err != nil {
+		return "", err
+	}
+	d.driveID = resp.Id
+	return d.driveID, nil
+}
+
 func (d *Onedrive) getFiles(path string) ([]File, error) {
 	var res []File
 	nextLink := d.GetMetaUrl(false, path) + "/children?$top=5000&$expand=thumbnails($select=medium)&$select=id,name,size,fileSystemInfo,content.downloadUrl,file,parentReference"
@@ -9,7 +9,7 @@ type Addition struct {
 	driver.RootPath
 	Region       string `json:"region" type:"select" required:"true" options:"global,cn,us,de" default:"global"`
 	ClientID     string `json:"client_id" required:"true"`
-	ClientSecret string `json:"client_secret" required:"true"`
+	ClientSecret string `json:"client_secret" confidential:"true" required:"true"`
 	TenantID     string `json:"tenant_id"`
 	Email        string `json:"email"`
 	ChunkSize    int64  `json:"chunk_size" type:"number" default:"5"`
@@ -26,4 +26,5 @@ func init() {
 	op.RegisterDriver(func() driver.Driver {
 		return &OnedriveAPP{}
 	})
+	op.RegisterDiskCacheObj(&Object{})
 }
@@ -0,0 +1,30 @@
+package onedrive_app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+type driveQuota struct {
+	Quota struct {
+		Total     int64 `json:"total"`
+		Remaining int64 `json:"remaining"`
+	} `json:"quota"`
+}
+
+// GetSpace returns the quota of the drive of the user
+// ApiDoc: https://learn.microsoft.com/en-us/onedrive/developer/rest-api/api/drive_get
+func (d *OnedriveAPP) GetSpace(ctx context.Context) (*model.StorageSpace, error) {
+	host := onedriveHostMap[d.Region]
+	var resp driveQuota
+	if _, err := d.Request(fmt.Sprintf("%s/v1.0/users/%s/drive", host.Api, d.Email), http.MethodGet, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &model.StorageSpace{Total: resp.Quota.Total, Free: resp.Quota.Remaining}, nil
+}
+
+var _ driver.SpaceGetter = (*OnedriveAPP)(nil)
@@ -10,7 +10,7 @@ import (
 type Addition struct {
 	driver.RootPath
 	ShareLinkURL       string `json:"url" required:"true"`
-	ShareLinkPassword  string `json:"password"`
+	ShareLinkPassword  string `json:"password" confidential:"true"`
 	IsSharepoint       bool
 	downloadLinkPrefix string
 	Headers            http.Header
@@ -8,9 +8,9 @@ import (
 type Addition struct {
 	driver.RootID
 	Username           string `json:"username" required:"true"`
-	Password           string `json:"password" required:"true"`
+	Password           string `json:"password" confidential:"true" required:"true"`
 	Platform           string `json:"platform" required:"true" default:"web" type:"select" options:"android,web,pc"`
-	RefreshToken       string `json:"refresh_token" required:"true" default:""`
+	RefreshToken       string `json:"refresh_token" confidential:"true" required:"true" default:""`
 	RefreshTokenMethod string `json:"refresh_token_method" required:"true" type:"select" options:"oauth2,http"`
 	CaptchaToken       string `json:"captcha_token" default:""`
 	DeviceID           string `json:"device_id"  required:"false" default:""`
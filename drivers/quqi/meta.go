@@ -8,8 +8,8 @@ import (
 type Addition struct {
 	driver.RootID
 	Phone    string `json:"phone"`
-	Password string `json:"password"`
-	Cookie   string `json:"cookie" help:"Cookie can be used on multiple clients at the same time"`
+	Password string `json:"password" confidential:"true"`
+	Cookie   string `json:"cookie" confidential:"true" help:"Cookie can be used on multiple clients at the same time"`
 	CDN      bool   `json:"cdn" help:"If you enable this option, the download speed can be increased, but there will be some performance loss"`
 }
 
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"github.com/alist-org/alist/v3/server/common"
 	"io"
+	"net/http"
 	"net/url"
 	stdpath "path"
+	"sort"
 	"strings"
 	"time"
 
@@ -16,6 +18,8 @@ import (
 
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
@@ -80,6 +84,34 @@ func (d *S3) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]mo
 	return d.listV1(dir.GetPath(), args)
 }
 
+func (d *S3) ListPage(ctx context.Context, dir model.Obj, args model.ListPageArgs) ([]model.Obj, string, error) {
+	if d.ListObjectVersion == "v2" {
+		// v2 pages continue either from a continuation token or after a key
+		var continuationToken, startAfter string
+		if kind, token, ok := strings.Cut(args.Token, ":"); ok && kind == "a" {
+			startAfter = token
+		} else if ok {
+			continuationToken = token
+		}
+		files, nextToken, nextAfter, err := d.listV2Page(dir.GetPath(), continuationToken, startAfter, int64(args.Limit), false)
+		if err != nil {
+			return nil, "", err
+		}
+		switch {
+		case nextToken != "":
+			return files, "c:" + nextToken, nil
+		case nextAfter != "":
+			return files, "a:" + nextAfter, nil
+		}
+		return files, "", nil
+	}
+	return d.listV1Page(dir.GetPath(), args.Token, int64(args.Limit), false)
+}
+
+func (d *S3) ListR(ctx context.Context, dir model.Obj, args model.ListArgs, callback driver.ListRCallback) error {
+	return d.listR(ctx, dir.GetPath(), callback)
+}
+
 func (d *S3) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
 	path := getKey(file.GetPath(), false)
 	filename := stdpath.Base(path)
@@ -152,6 +184,26 @@ func (d *S3) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
 	return d.copy(ctx, srcObj.GetPath(), stdpath.Join(dstDir.GetPath(), srcObj.GetName()), srcObj.IsDir())
 }
 
+// IsPeer reports whether dst is an S3 storage with the same endpoint and credentials,
+// the buckets and root folders can be different
+func (d *S3) IsPeer(dst driver.Driver) bool {
+	p, ok := dst.(*S3)
+	return ok && p.config.Name == d.config.Name && p.Endpoint == d.Endpoint &&
+		p.Region == d.Region && p.AccessKeyID == d.AccessKeyID
+}
+
+func (d *S3) PeerCopy(ctx context.Context, srcObj model.Obj, dst driver.Driver, dstDir model.Obj) error {
+	return d.copyTo(ctx, dst.(*S3).Bucket, srcObj.GetPath(), stdpath.Join(dstDir.GetPath(), srcObj.GetName()), srcObj.IsDir())
+}
+
+func (d *S3) PeerMove(ctx context.Context, srcObj model.Obj, dst driver.Driver, dstDir model.Obj) error {
+	err := d.PeerCopy(ctx, srcObj, dst, dstDir)
+	if err != nil {
+		return err
+	}
+	return d.Remove(ctx, srcObj)
+}
+
 func (d *S3) Remove(ctx context.Context, obj model.Obj) error {
 	if obj.IsDir() {
 		return d.removeDir(ctx, obj.GetPath())
@@ -177,4 +229,129 @@ func (d *S3) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreame
 	return err
 }
 
+func (d *S3) CreateUploadSession(ctx context.Context, dstDir model.Obj, args model.UploadSessionArgs) (*model.UploadSession, error) {
+	key := getKey(stdpath.Join(dstDir.GetPath(), args.Name), false)
+	expire := time.Hour * time.Duration(d.SignURLExpire)
+	session := &model.UploadSession{
+		Method:  http.MethodPut,
+		Expires: time.Now().Add(expire),
+	}
+	if args.Mimetype != "" {
+		session.Headers = map[string]string{"Content-Type": args.Mimetype}
+	}
+	// small file is uploaded by a single put
+	if args.Size <= s3manager.DefaultUploadPartSize {
+		input := &s3.PutObjectInput{
+			Bucket: &d.Bucket,
+			Key:    &key,
+		}
+		if args.Mimetype != "" {
+			input.ContentType = &args.Mimetype
+		}
+		req, _ := d.client.PutObjectRequest(input)
+		u, err := req.Presign(expire)
+		if err != nil {
+			return nil, err
+		}
+		session.URLs = []string{u}
+		session.PartSize = args.Size
+		return session, nil
+	}
+	input := &s3.CreateMultipartUploadInput{
+		Bucket: &d.Bucket,
+		Key:    &key,
+	}
+	if args.Mimetype != "" {
+		input.ContentType = &args.Mimetype
+	}
+	upload, err := d.client.CreateMultipartUploadWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	// the content type is set when the upload is created, not needed by the parts
+	session.Headers = nil
+	session.ID = *upload.UploadId
+	session.PartSize = directUploadPartSize
+	if args.Size > s3manager.MaxUploadParts*session.PartSize {
+		session.PartSize = (args.Size + s3manager.MaxUploadParts - 1) / s3manager.MaxUploadParts
+	}
+	parts := (args.Size + session.PartSize - 1) / session.PartSize
+	session.URLs = make([]string, 0, parts)
+	for i := int64(1); i <= parts; i++ {
+		req, _ := d.client.UploadPartRequest(&s3.UploadPartInput{
+			Bucket:     &d.Bucket,
+			Key:        &key,
+			UploadId:   upload.UploadId,
+			PartNumber: aws.Int64(i),
+		})
+		u, err := req.Presign(expire)
+		if err != nil {
+			return nil, err
+		}
+		session.URLs = append(session.URLs, u)
+	}
+	return session, nil
+}
+
+func (d *S3) CompleteUploadSession(ctx context.Context, dstDir model.Obj, args model.UploadCompleteArgs) (model.Obj, error) {
+	key := getKey(stdpath.Join(dstDir.GetPath(), args.Name), false)
+	if args.ID != "" {
+		parts := make([]*s3.CompletedPart, 0, len(args.Parts))
+		for _, part := range args.Parts {
+			parts = append(parts, &s3.CompletedPart{
+				ETag:       aws.String(part.ETag),
+				PartNumber: aws.Int64(int64(part.Number)),
+			})
+		}
+		sort.Slice(parts, func(i, j int) bool {
+			return *parts[i].PartNumber < *parts[j].PartNumber
+		})
+		_, err := d.client.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          &d.Bucket,
+			Key:             &key,
+			UploadId:        &args.ID,
+			MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	head, err := d.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: &d.Bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	size := aws.Int64Value(head.ContentLength)
+	if size != args.Size {
+		return nil, fmt.Errorf("size of uploaded file mismatch, expect %d, got %d", args.Size, size)
+	}
+	return &model.Object{
+		Name:     args.Name,
+		Size:     size,
+		Modified: aws.TimeValue(head.LastModified),
+	}, nil
+}
+
+func (d *S3) AbortUploadSession(ctx context.Context, dstDir model.Obj, args model.UploadCompleteArgs) error {
+	// the parts of an unfinished multipart upload are kept and billed until it's aborted
+	key := getKey(stdpath.Join(dstDir.GetPath(), args.Name), false)
+	_, err := d.client.AbortMultipartUploadWithContext(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   &d.Bucket,
+		Key:      &key,
+		UploadId: &args.ID,
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchUpload {
+		// completed already
+		return nil
+	}
+	return err
+}
+
 var _ driver.Driver = (*S3)(nil)
+var _ driver.ListPager = (*S3)(nil)
+var _ driver.ListR = (*S3)(nil)
+var _ driver.DirectUpload = (*S3)(nil)
+var _ driver.AbortUploadSession = (*S3)(nil)
+var _ driver.Peer = (*S3)(nil)
@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"path"
 	"strings"
+	"time"
 
+	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -70,6 +72,9 @@ func getKey(path string, dir bool) string {
 
 var defaultPlaceholderName = ".alist"
 
+// directUploadPartSize is the part size of the multipart upload made by clients directly
+const directUploadPartSize int64 = 16 * 1024 * 1024
+
 func getPlaceholderName(placeholder string) string {
 	if placeholder == "" {
 		return defaultPlaceholderName
@@ -78,126 +83,289 @@ func getPlaceholderName(placeholder string) string {
 }
 
 func (d *S3) listV1(prefix string, args model.ListArgs) ([]model.Obj, error) {
-	prefix = getKey(prefix, true)
-	log.Debugf("list: %s", prefix)
 	files := make([]model.Obj, 0)
 	marker := ""
 	for {
-		input := &s3.ListObjectsInput{
-			Bucket:    &d.Bucket,
-			Marker:    &marker,
-			Prefix:    &prefix,
-			Delimiter: aws.String("/"),
-		}
-		listObjectsResult, err := d.client.ListObjects(input)
+		page, next, err := d.listV1Page(prefix, marker, 0, args.S3ShowPlaceholder)
 		if err != nil {
 			return nil, err
 		}
-		for _, object := range listObjectsResult.CommonPrefixes {
-			name := path.Base(strings.Trim(*object.Prefix, "/"))
-			file := model.Object{
-				//Id:        *object.Key,
-				Name:     name,
-				Modified: d.Modified,
-				IsFolder: true,
-			}
-			files = append(files, &file)
+		files = append(files, page...)
+		if next == "" {
+			break
 		}
-		for _, object := range listObjectsResult.Contents {
-			name := path.Base(*object.Key)
-			if !args.S3ShowPlaceholder && (name == getPlaceholderName(d.Placeholder) || name == d.Placeholder) {
-				continue
-			}
-			file := model.Object{
-				//Id:        *object.Key,
-				Name:     name,
-				Size:     *object.Size,
-				Modified: *object.LastModified,
-			}
-			files = append(files, &file)
+		marker = next
+	}
+	return files, nil
+}
+
+// listV1Page list one page of objects after marker, the returned marker is empty after the last page
+func (d *S3) listV1Page(prefix, marker string, maxKeys int64, showPlaceholder bool) ([]model.Obj, string, error) {
+	prefix = getKey(prefix, true)
+	log.Debugf("list: %s", prefix)
+	files := make([]model.Obj, 0)
+	input := &s3.ListObjectsInput{
+		Bucket:    &d.Bucket,
+		Marker:    &marker,
+		Prefix:    &prefix,
+		Delimiter: aws.String("/"),
+	}
+	if maxKeys > 0 {
+		input.MaxKeys = &maxKeys
+	}
+	listObjectsResult, err := d.client.ListObjects(input)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, object := range listObjectsResult.CommonPrefixes {
+		name := path.Base(strings.Trim(*object.Prefix, "/"))
+		file := model.Object{
+			//Id:        *object.Key,
+			Name:     name,
+			Modified: d.Modified,
+			IsFolder: true,
 		}
-		if listObjectsResult.IsTruncated == nil {
-			return nil, errors.New("IsTruncated nil")
+		files = append(files, &file)
+	}
+	for _, object := range listObjectsResult.Contents {
+		name := path.Base(*object.Key)
+		if !showPlaceholder && (name == getPlaceholderName(d.Placeholder) || name == d.Placeholder) {
+			continue
 		}
-		if *listObjectsResult.IsTruncated {
-			marker = *listObjectsResult.NextMarker
-		} else {
-			break
+		file := model.Object{
+			//Id:        *object.Key,
+			Name:     name,
+			Size:     *object.Size,
+			Modified: *object.LastModified,
 		}
+		files = append(files, &file)
 	}
-	return files, nil
+	if listObjectsResult.IsTruncated == nil {
+		return nil, "", errors.New("IsTruncated nil")
+	}
+	if !*listObjectsResult.IsTruncated {
+		return files, "", nil
+	}
+	return files, *listObjectsResult.NextMarker, nil
 }
 
 func (d *S3) listV2(prefix string, args model.ListArgs) ([]model.Obj, error) {
-	prefix = getKey(prefix, true)
 	files := make([]model.Obj, 0)
-	var continuationToken, startAfter *string
+	var continuationToken, startAfter string
 	for {
-		input := &s3.ListObjectsV2Input{
-			Bucket:            &d.Bucket,
-			ContinuationToken: continuationToken,
-			Prefix:            &prefix,
-			Delimiter:         aws.String("/"),
-			StartAfter:        startAfter,
-		}
-		listObjectsResult, err := d.client.ListObjectsV2(input)
+		page, nextToken, nextAfter, err := d.listV2Page(prefix, continuationToken, startAfter, 0, args.S3ShowPlaceholder)
 		if err != nil {
 			return nil, err
 		}
-		log.Debugf("resp: %+v", listObjectsResult)
-		for _, object := range listObjectsResult.CommonPrefixes {
-			name := path.Base(strings.Trim(*object.Prefix, "/"))
-			file := model.Object{
-				//Id:        *object.Key,
-				Name:     name,
-				Modified: d.Modified,
-				IsFolder: true,
-			}
-			files = append(files, &file)
+		files = append(files, page...)
+		if nextToken == "" && nextAfter == "" {
+			break
+		}
+		continuationToken, startAfter = nextToken, nextAfter
+	}
+	return files, nil
+}
+
+// listV2Page list one page of objects, the next page starts from the returned continuation token
+// if it's not empty, or else after the returned key. Both are empty after the last page
+func (d *S3) listV2Page(prefix, continuationToken, startAfter string, maxKeys int64, showPlaceholder bool) ([]model.Obj, string, string, error) {
+	prefix = getKey(prefix, true)
+	files := make([]model.Obj, 0)
+	input := &s3.ListObjectsV2Input{
+		Bucket:    &d.Bucket,
+		Prefix:    &prefix,
+		Delimiter: aws.String("/"),
+	}
+	if continuationToken != "" {
+		input.ContinuationToken = &continuationToken
+	}
+	if startAfter != "" {
+		input.StartAfter = &startAfter
+	}
+	if maxKeys > 0 {
+		input.MaxKeys = &maxKeys
+	}
+	listObjectsResult, err := d.client.ListObjectsV2(input)
+	if err != nil {
+		return nil, "", "", err
+	}
+	log.Debugf("resp: %+v", listObjectsResult)
+	for _, object := range listObjectsResult.CommonPrefixes {
+		name := path.Base(strings.Trim(*object.Prefix, "/"))
+		file := model.Object{
+			//Id:        *object.Key,
+			Name:     name,
+			Modified: d.Modified,
+			IsFolder: true,
+		}
+		files = append(files, &file)
+	}
+	for _, object := range listObjectsResult.Contents {
+		if strings.HasSuffix(*object.Key, "/") {
+			continue
 		}
-		for _, object := range listObjectsResult.Contents {
-			if strings.HasSuffix(*object.Key, "/") {
+		name := path.Base(*object.Key)
+		if !showPlaceholder && (name == getPlaceholderName(d.Placeholder) || name == d.Placeholder) {
+			continue
+		}
+		file := model.Object{
+			//Id:        *object.Key,
+			Name:     name,
+			Size:     *object.Size,
+			Modified: *object.LastModified,
+		}
+		files = append(files, &file)
+	}
+	if !aws.BoolValue(listObjectsResult.IsTruncated) {
+		return files, "", "", nil
+	}
+	if listObjectsResult.NextContinuationToken != nil {
+		return files, *listObjectsResult.NextContinuationToken, "", nil
+	}
+	if len(listObjectsResult.Contents) == 0 {
+		return files, "", "", nil
+	}
+	return files, "", *listObjectsResult.Contents[len(listObjectsResult.Contents)-1].Key, nil
+}
+
+// listRBatch groups the objs found by ListR by their parent folder,
+// the folders are made up from the keys as there is no delimiter
+type listRBatch struct {
+	parent   string
+	objs     []model.Obj
+	dirs     map[string]struct{}
+	modified time.Time
+	callback driver.ListRCallback
+}
+
+func (b *listRBatch) add(parent string, obj model.Obj) error {
+	if parent != b.parent || len(b.objs) >= 1000 {
+		if err := b.flush(); err != nil {
+			return err
+		}
+		b.parent = parent
+	}
+	b.objs = append(b.objs, obj)
+	return nil
+}
+
+func (b *listRBatch) addDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	if _, ok := b.dirs[dir]; ok {
+		return nil
+	}
+	parent := parentOf(dir)
+	if err := b.addDir(parent); err != nil {
+		return err
+	}
+	b.dirs[dir] = struct{}{}
+	return b.add(parent, &model.Object{
+		Name:     path.Base(dir),
+		Modified: b.modified,
+		IsFolder: true,
+	})
+}
+
+func (b *listRBatch) flush() error {
+	if len(b.objs) == 0 {
+		return nil
+	}
+	objs := b.objs
+	b.objs = nil
+	return b.callback(b.parent, objs)
+}
+
+func parentOf(rel string) string {
+	parent := path.Dir(rel)
+	if parent == "." {
+		return ""
+	}
+	return parent
+}
+
+func (d *S3) listR(ctx context.Context, prefix string, callback driver.ListRCallback) error {
+	prefix = getKey(prefix, true)
+	batch := &listRBatch{
+		dirs:     make(map[string]struct{}),
+		modified: d.Modified,
+		callback: callback,
+	}
+	var batchErr error
+	addObjects := func(objects []*s3.Object) bool {
+		for _, object := range objects {
+			rel := strings.TrimPrefix(*object.Key, prefix)
+			if rel == "" {
 				continue
 			}
-			name := path.Base(*object.Key)
-			if !args.S3ShowPlaceholder && (name == getPlaceholderName(d.Placeholder) || name == d.Placeholder) {
+			// folder placeholder objects end with a slash
+			if strings.HasSuffix(rel, "/") {
+				if batchErr = batch.addDir(strings.TrimSuffix(rel, "/")); batchErr != nil {
+					return false
+				}
 				continue
 			}
-			file := model.Object{
-				//Id:        *object.Key,
+			parent, name := parentOf(rel), path.Base(rel)
+			if batchErr = batch.addDir(parent); batchErr != nil {
+				return false
+			}
+			if name == getPlaceholderName(d.Placeholder) || name == d.Placeholder {
+				continue
+			}
+			batchErr = batch.add(parent, &model.Object{
 				Name:     name,
 				Size:     *object.Size,
 				Modified: *object.LastModified,
+			})
+			if batchErr != nil {
+				return false
 			}
-			files = append(files, &file)
-		}
-		if !aws.BoolValue(listObjectsResult.IsTruncated) {
-			break
 		}
-		if listObjectsResult.NextContinuationToken != nil {
-			continuationToken = listObjectsResult.NextContinuationToken
-			continue
-		}
-		if len(listObjectsResult.Contents) == 0 {
-			break
-		}
-		startAfter = listObjectsResult.Contents[len(listObjectsResult.Contents)-1].Key
+		return true
 	}
-	return files, nil
+	var err error
+	if d.ListObjectVersion == "v2" {
+		err = d.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+			Bucket: &d.Bucket,
+			Prefix: &prefix,
+		}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+			return addObjects(page.Contents)
+		})
+	} else {
+		err = d.client.ListObjectsPagesWithContext(ctx, &s3.ListObjectsInput{
+			Bucket: &d.Bucket,
+			Prefix: &prefix,
+		}, func(page *s3.ListObjectsOutput, lastPage bool) bool {
+			return addObjects(page.Contents)
+		})
+	}
+	if batchErr != nil {
+		return batchErr
+	}
+	if err != nil {
+		return err
+	}
+	return batch.flush()
 }
 
 func (d *S3) copy(ctx context.Context, src string, dst string, isDir bool) error {
+	return d.copyTo(ctx, d.Bucket, src, dst, isDir)
+}
+
+// copyTo copies src to dst of dstBucket, which may be another bucket of the same account
+func (d *S3) copyTo(ctx context.Context, dstBucket string, src string, dst string, isDir bool) error {
 	if isDir {
-		return d.copyDir(ctx, src, dst)
+		return d.copyDir(ctx, dstBucket, src, dst)
 	}
-	return d.copyFile(ctx, src, dst)
+	return d.copyFile(ctx, dstBucket, src, dst)
 }
 
-func (d *S3) copyFile(ctx context.Context, src string, dst string) error {
+func (d *S3) copyFile(ctx context.Context, dstBucket string, src string, dst string) error {
 	srcKey := getKey(src, false)
 	dstKey := getKey(dst, false)
 	input := &s3.CopyObjectInput{
-		Bucket:     &d.Bucket,
+		Bucket:     &dstBucket,
 		CopySource: aws.String("/" + d.Bucket + "/" + srcKey),
 		Key:        &dstKey,
 	}
@@ -205,7 +373,7 @@ func (d *S3) copyFile(ctx context.Context, src string, dst string) error {
 	return err
 }
 
-func (d *S3) copyDir(ctx context.Context, src string, dst string) error {
+func (d *S3) copyDir(ctx context.Context, dstBucket string, src string, dst string) error {
 	objs, err := op.List(ctx, d, src, model.ListArgs{S3ShowPlaceholder: true})
 	if err != nil {
 		return err
@@ -214,9 +382,9 @@ func (d *S3) copyDir(ctx context.Context, src string, dst string) error {
 		cSrc := path.Join(src, obj.GetName())
 		cDst := path.Join(dst, obj.GetName())
 		if obj.IsDir() {
-			err = d.copyDir(ctx, cSrc, cDst)
+			err = d.copyDir(ctx, dstBucket, cSrc, cDst)
 		} else {
-			err = d.copyFile(ctx, cSrc, cDst)
+			err = d.copyFile(ctx, dstBucket, cSrc, cDst)
 		}
 		if err != nil {
 			return err
@@ -8,8 +8,8 @@ import (
 type Addition struct {
 	Address    string `json:"address" required:"true"`
 	Username   string `json:"username" required:"true"`
-	PrivateKey string `json:"private_key" type:"text"`
-	Password   string `json:"password"`
+	PrivateKey string `json:"private_key" confidential:"true" type:"text"`
+	Password   string `json:"password" confidential:"true"`
 	Passphrase string `json:"passphrase"`
 	driver.RootPath
 	IgnoreSymlinkError bool `json:"ignore_symlink_error" default:"false" info:"Ignore symlink error"`
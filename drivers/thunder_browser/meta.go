@@ -18,11 +18,11 @@ type ExpertAddition struct {
 
 	// 登录方式1
 	Username string `json:"username" required:"true" help:"login type is user,this is required"`
-	Password string `json:"password" required:"true" help:"login type is user,this is required"`
+	Password string `json:"password" confidential:"true" required:"true" help:"login type is user,this is required"`
 	// 登录方式2
-	RefreshToken string `json:"refresh_token" required:"true" help:"login type is refresh_token,this is required"`
+	RefreshToken string `json:"refresh_token" confidential:"true" required:"true" help:"login type is refresh_token,this is required"`
 
-	SafePassword string `json:"safe_password" required:"true" help:"super safe password"` // 超级保险箱密码
+	SafePassword string `json:"safe_password" confidential:"true" required:"true" help:"super safe password"` // 超级保险箱密码
 
 	// 签名方法1
 	Algorithms string `json:"algorithms" required:"true" help:"sign type is algorithms,this is required" default:"uWRwO7gPfdPB/0NfPtfQO+71,F93x+qPluYy6jdgNpq+lwdH1ap6WOM+nfz8/V,0HbpxvpXFsBK5CoTKam,dQhzbhzFRcawnsZqRETT9AuPAJ+wTQso82mRv,SAH98AmLZLRa6DB2u68sGhyiDh15guJpXhBzI,unqfo7Z64Rie9RNHMOB,7yxUdFADp3DOBvXdz0DPuKNVT35wqa5z0DEyEvf,RBG,ThTWPG5eC0UBqlbQ+04nZAptqGCdpv9o55A"`
@@ -36,7 +36,7 @@ type ExpertAddition struct {
 	// 必要且影响登录,由签名决定
 	DeviceID      string `json:"device_id"  required:"false" default:""`
 	ClientID      string `json:"client_id"  required:"true" default:"ZUBzD9J_XPXfn7f7"`
-	ClientSecret  string `json:"client_secret"  required:"true" default:"yESVmHecEe6F0aou69vl-g"`
+	ClientSecret  string `json:"client_secret" confidential:"true"  required:"true" default:"yESVmHecEe6F0aou69vl-g"`
 	ClientVersion string `json:"client_version"  required:"true" default:"1.10.0.2633"`
 	PackageName   string `json:"package_name"  required:"true" default:"com.xunlei.browser"`
 
@@ -76,8 +76,8 @@ func (i *ExpertAddition) GetIdentity() string {
 type Addition struct {
 	driver.RootID
 	Username     string `json:"username" required:"true"`
-	Password     string `json:"password" required:"true"`
-	SafePassword string `json:"safe_password" required:"true"` // 超级保险箱密码
+	Password     string `json:"password" confidential:"true" required:"true"`
+	SafePassword string `json:"safe_password" confidential:"true" required:"true"` // 超级保险箱密码
 	CaptchaToken string `json:"captcha_token"`
 	UseVideoUrl  bool   `json:"use_video_url" default:"false"`
 	RemoveWay    string `json:"remove_way" required:"true" type:"select" options:"trash,delete"`
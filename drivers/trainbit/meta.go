@@ -8,7 +8,7 @@ import (
 type Addition struct {
 	driver.RootID
 	AUSHELLPORTAL string `json:"AUSHELLPORTAL" required:"true"`
-	ApiKey string `json:"apikey" required:"true"`
+	ApiKey string `json:"apikey" confidential:"true" required:"true"`
 }
 
 var config = driver.Config{
@@ -0,0 +1,228 @@
+package union
+
+import (
+	"context"
+	"errors"
+	stdpath "path"
+	"strings"
+	"sync/atomic"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+type Union struct {
+	model.Storage
+	Addition
+	members []string
+	next    atomic.Uint32
+}
+
+func (d *Union) Config() driver.Config {
+	return config
+}
+
+func (d *Union) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Union) Init(ctx context.Context) error {
+	if d.Paths == "" {
+		return errors.New("paths is required")
+	}
+	d.members = nil
+	for _, path := range strings.Split(d.Paths, "\n") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		path = utils.FixAndCleanPath(path)
+		if utils.IsSubPath(path, d.MountPath) || utils.IsSubPath(d.MountPath, path) {
+			return errors.New("member path can't overlap the union itself")
+		}
+		d.members = append(d.members, path)
+	}
+	if len(d.members) == 0 {
+		return errors.New("paths is required")
+	}
+	return nil
+}
+
+func (d *Union) Drop(ctx context.Context) error {
+	d.members = nil
+	return nil
+}
+
+func (d *Union) Get(ctx context.Context, path string) (model.Obj, error) {
+	if utils.PathEqual(path, "/") {
+		return &model.Object{
+			Name:     "Root",
+			IsFolder: true,
+			Path:     "/",
+		}, nil
+	}
+	for _, member := range d.members {
+		obj, err := fs.Get(ctx, d.memberPath(member, path), &fs.GetArgs{NoLog: true})
+		if err == nil {
+			return toObj(path, obj), nil
+		}
+	}
+	return nil, errs.ObjectNotFound
+}
+
+func (d *Union) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	var (
+		objs  []model.Obj
+		found bool
+	)
+	seen := make(map[string]struct{})
+	for _, member := range d.members {
+		tmp, err := fs.List(ctx, d.memberPath(member, dir.GetPath()), &fs.ListArgs{NoLog: true, Refresh: args.Refresh})
+		if err != nil {
+			continue
+		}
+		found = true
+		for _, obj := range tmp {
+			// the first member wins for files with the same name, folders are merged
+			if _, ok := seen[obj.GetName()]; ok {
+				continue
+			}
+			seen[obj.GetName()] = struct{}{}
+			objs = append(objs, toObj(stdpath.Join(dir.GetPath(), obj.GetName()), obj))
+		}
+	}
+	if !found {
+		return nil, errs.ObjectNotFound
+	}
+	return objs, nil
+}
+
+func (d *Union) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	members := d.existMembers(ctx, file.GetPath())
+	if len(members) == 0 {
+		return nil, errs.ObjectNotFound
+	}
+	return d.link(ctx, d.memberPath(members[0], file.GetPath()), args)
+}
+
+func (d *Union) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	member, err := d.createMember(ctx, parentDir.GetPath())
+	if err != nil {
+		return err
+	}
+	storage, actualPath, err := d.getStorageAndActualPath(member, parentDir.GetPath())
+	if err != nil {
+		return err
+	}
+	return op.MakeDir(ctx, storage, stdpath.Join(actualPath, dirName))
+}
+
+func (d *Union) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	members := d.existMembers(ctx, srcObj.GetPath())
+	if len(members) == 0 {
+		return errs.ObjectNotFound
+	}
+	for _, member := range members {
+		srcStorage, srcActualPath, err := d.getStorageAndActualPath(member, srcObj.GetPath())
+		if err != nil {
+			return err
+		}
+		dstStorage, dstActualPath, err := d.getStorageAndActualPath(member, dstDir.GetPath())
+		if err != nil {
+			return err
+		}
+		if srcStorage.GetStorage().MountPath != dstStorage.GetStorage().MountPath {
+			return errs.MoveBetweenTwoStorages
+		}
+		// the dst folder may only exist in other members
+		if err = op.MakeDir(ctx, dstStorage, dstActualPath); err != nil {
+			return err
+		}
+		if err = op.Move(ctx, srcStorage, srcActualPath, dstActualPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Union) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	members := d.existMembers(ctx, srcObj.GetPath())
+	if len(members) == 0 {
+		return errs.ObjectNotFound
+	}
+	for _, member := range members {
+		storage, actualPath, err := d.getStorageAndActualPath(member, srcObj.GetPath())
+		if err != nil {
+			return err
+		}
+		if err = op.Rename(ctx, storage, actualPath, newName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Union) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	members := d.existMembers(ctx, srcObj.GetPath())
+	if len(members) == 0 {
+		return errs.ObjectNotFound
+	}
+	// copy the visible one, which is in the first member
+	srcStorage, srcActualPath, err := d.getStorageAndActualPath(members[0], srcObj.GetPath())
+	if err != nil {
+		return err
+	}
+	dstStorage, dstActualPath, err := d.getStorageAndActualPath(members[0], dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	if srcStorage.GetStorage().MountPath != dstStorage.GetStorage().MountPath {
+		return errs.MoveBetweenTwoStorages
+	}
+	if err = op.MakeDir(ctx, dstStorage, dstActualPath); err != nil {
+		return err
+	}
+	return op.Copy(ctx, srcStorage, srcActualPath, dstActualPath)
+}
+
+func (d *Union) Remove(ctx context.Context, obj model.Obj) error {
+	members := d.existMembers(ctx, obj.GetPath())
+	if len(members) == 0 {
+		return errs.ObjectNotFound
+	}
+	for _, member := range members {
+		storage, actualPath, err := d.getStorageAndActualPath(member, obj.GetPath())
+		if err != nil {
+			return err
+		}
+		if err = op.Remove(ctx, storage, actualPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Union) Put(ctx context.Context, dstDir model.Obj, s model.FileStreamer, up driver.UpdateProgress) error {
+	// overwrite the existing file in place, otherwise it would shadow the new one
+	var member string
+	if members := d.existMembers(ctx, stdpath.Join(dstDir.GetPath(), s.GetName())); len(members) > 0 {
+		member = members[0]
+	} else {
+		var err error
+		member, err = d.createMember(ctx, dstDir.GetPath())
+		if err != nil {
+			return err
+		}
+	}
+	storage, actualPath, err := d.getStorageAndActualPath(member, dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	return op.Put(ctx, storage, actualPath, s, up, false)
+}
+
+var _ driver.Driver = (*Union)(nil)
@@ -0,0 +1,99 @@
+package union
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	_ "github.com/alist-org/alist/v3/drivers/virtual"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+func createStorage(t *testing.T, storage model.Storage) {
+	if _, err := op.CreateStorage(context.Background(), storage); err != nil {
+		t.Fatalf("failed to create storage %s: %+v", storage.MountPath, err)
+	}
+}
+
+func localMember(t *testing.T, mountPath string, dirs ...string) {
+	root := t.TempDir()
+	for _, dir := range dirs {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0777); err != nil {
+			t.Fatal(err)
+		}
+	}
+	createStorage(t, model.Storage{
+		Driver:    "Local",
+		MountPath: mountPath,
+		Addition:  `{"root_folder_path":"` + filepath.ToSlash(root) + `"}`,
+	})
+}
+
+func newUnion(t *testing.T, paths, policy string) *Union {
+	d := &Union{Addition: Addition{Paths: paths, CreatePolicy: policy}}
+	d.MountPath = "/union"
+	if err := d.Init(context.Background()); err != nil {
+		t.Fatalf("failed to init union: %+v", err)
+	}
+	return d
+}
+
+func TestCreatePolicy(t *testing.T) {
+	ctx := context.Background()
+	localMember(t, "/m1")
+	localMember(t, "/m2", "only_m2")
+	createStorage(t, model.Storage{
+		Driver:    "Virtual",
+		MountPath: "/v",
+		Addition:  `{"num_file":1,"num_folder":1,"max_file_size":10,"min_file_size":1}`,
+	})
+	tests := []struct {
+		paths  string
+		policy string
+		dir    string
+		expect []string
+	}{
+		{paths: "/m1\n/m2", policy: "existing_path", dir: "/only_m2", expect: []string{"/m2", "/m2"}},
+		{paths: "/m1\n/m2", policy: "existing_path", dir: "/", expect: []string{"/m1"}},
+		{paths: "/m2\n/m1", policy: "first_found", dir: "/missing", expect: []string{"/m2"}},
+		{paths: "/m1\n/m2", policy: "round_robin", dir: "/", expect: []string{"/m1", "/m2", "/m1"}},
+		// the virtual member doesn't report free space
+		{paths: "/v\n/m2", policy: "most_free_space", dir: "/", expect: []string{"/m2"}},
+		// fallback to existing_path if no member reports free space
+		{paths: "/v", policy: "most_free_space", dir: "/", expect: []string{"/v"}},
+	}
+	for _, tt := range tests {
+		d := newUnion(t, tt.paths, tt.policy)
+		for i, expect := range tt.expect {
+			member, err := d.createMember(ctx, tt.dir)
+			if err != nil {
+				t.Fatalf("%s %s: failed to choose member: %+v", tt.policy, tt.dir, err)
+			}
+			if member != expect {
+				t.Errorf("%s %s #%d: expect %s, got %s", tt.policy, tt.dir, i, expect, member)
+			}
+		}
+	}
+	if _, err := newUnion(t, "/m1\n/m2", "existing_path").createMember(ctx, "/missing"); err == nil {
+		t.Errorf("expect no member for missing dir with existing_path")
+	}
+	if err := (&Union{Addition: Addition{Paths: "/union/a"}, Storage: model.Storage{MountPath: "/union"}}).Init(ctx); err == nil {
+		t.Errorf("expect overlapped member rejected")
+	}
+}
@@ -0,0 +1,29 @@
+package union
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	Paths        string `json:"paths" required:"true" type:"text" help:"Paths of member storages, one per line"`
+	CreatePolicy string `json:"create_policy" type:"select" required:"true" options:"existing_path,first_found,most_free_space,round_robin" default:"existing_path" help:"How to choose the member for new files and folders. existing_path: the first member where the parent folder exists; first_found: the first member; most_free_space: the member with most free space, only for members which report capacity (Local, OneDrive, OneDrive App, AliyundriveOpen, GoogleDrive); round_robin: members in turn"`
+}
+
+var config = driver.Config{
+	Name:             "Union",
+	LocalSort:        true,
+	NoCache:          true,
+	DefaultRoot:      "/",
+	ProxyRangeOption: true,
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Union{
+			Addition: Addition{
+				CreatePolicy: "existing_path",
+			},
+		}
+	})
+}
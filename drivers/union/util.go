@@ -0,0 +1,134 @@
+package union
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	log "github.com/sirupsen/logrus"
+)
+
+func (d *Union) memberPath(member, path string) string {
+	return stdpath.Join(member, path)
+}
+
+// existMembers returns the members where path exists
+func (d *Union) existMembers(ctx context.Context, path string) []string {
+	var members []string
+	for _, member := range d.members {
+		if _, err := fs.Get(ctx, d.memberPath(member, path), &fs.GetArgs{NoLog: true}); err == nil {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
+// createMember chooses the member to create obj in dir by the create policy
+func (d *Union) createMember(ctx context.Context, dir string) (string, error) {
+	switch d.CreatePolicy {
+	case "first_found":
+		return d.members[0], nil
+	case "round_robin":
+		i := d.next.Add(1) - 1
+		return d.members[i%uint32(len(d.members))], nil
+	case "most_free_space":
+		member, err := d.mostFreeSpaceMember(ctx)
+		if err == nil {
+			return member, nil
+		}
+		log.Warnf("union %s: %+v, fallback to existing_path", d.MountPath, err)
+	}
+	members := d.existMembers(ctx, dir)
+	if len(members) == 0 {
+		return "", errs.ObjectNotFound
+	}
+	return members[0], nil
+}
+
+func (d *Union) mostFreeSpaceMember(ctx context.Context) (string, error) {
+	var (
+		best string
+		free int64 = -1
+	)
+	for _, member := range d.members {
+		storage, err := fs.GetStorage(member, &fs.GetStoragesArgs{})
+		if err != nil {
+			continue
+		}
+		sg, ok := storage.(driver.SpaceGetter)
+		if !ok {
+			continue
+		}
+		space, err := sg.GetSpace(ctx)
+		if err != nil {
+			log.Debugf("failed get space of %s: %+v", member, err)
+			continue
+		}
+		if space.Free > free {
+			best, free = member, space.Free
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("no member reports free space")
+	}
+	return best, nil
+}
+
+func toObj(path string, obj model.Obj) model.Obj {
+	objRes := model.Object{
+		Path:     path,
+		Name:     obj.GetName(),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		Ctime:    obj.CreateTime(),
+		IsFolder: obj.IsDir(),
+		HashInfo: obj.GetHash(),
+	}
+	thumb, ok := model.GetThumb(obj)
+	if !ok {
+		return &objRes
+	}
+	return &model.ObjThumb{
+		Object: objRes,
+		Thumbnail: model.Thumbnail{
+			Thumbnail: thumb,
+		},
+	}
+}
+
+func (d *Union) link(ctx context.Context, reqPath string, args model.LinkArgs) (*model.Link, error) {
+	storage, err := fs.GetStorage(reqPath, &fs.GetStoragesArgs{})
+	if err != nil {
+		return nil, err
+	}
+	_, err = fs.Get(ctx, reqPath, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return nil, err
+	}
+	if common.ShouldProxy(storage, stdpath.Base(reqPath)) {
+		link := &model.Link{
+			URL: fmt.Sprintf("%s/p%s?sign=%s",
+				common.GetApiUrl(args.HttpReq),
+				utils.EncodePath(reqPath, true),
+				sign.Sign(reqPath)),
+		}
+		if args.HttpReq != nil && d.ProxyRange {
+			link.RangeReadCloser = common.NoProxyRange
+		}
+		return link, nil
+	}
+	link, _, err := fs.Link(ctx, reqPath, args)
+	return link, err
+}
+
+func (d *Union) getStorageAndActualPath(member, path string) (driver.Driver, string, error) {
+	return op.GetStorageAndActualPath(d.memberPath(member, path))
+}
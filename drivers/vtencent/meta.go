@@ -7,7 +7,7 @@ import (
 
 type Addition struct {
 	driver.RootID
-	Cookie         string `json:"cookie" required:"true"`
+	Cookie         string `json:"cookie" confidential:"true" required:"true"`
 	TfUid          string `json:"tf_uid"`
 	OrderBy        string `json:"order_by" type:"select" options:"Name,Size,UpdateTime,CreatTime"`
 	OrderDirection string `json:"order_direction" type:"select" options:"Asc,Desc"`
@@ -7,7 +7,7 @@ import (
 
 type Addition struct {
 	RootFolderID   string `json:"root_folder_id"`
-	Cookies        string `json:"cookies" required:"true"`
+	Cookies        string `json:"cookies" confidential:"true" required:"true"`
 	OrderBy        string `json:"order_by" type:"select" options:"name,size,updated_at" default:"name"`
 	OrderDirection string `json:"order_direction" type:"select" options:"asc,desc" default:"asc"`
 	UploadThread   string `json:"upload_thread" default:"4" help:"4<=thread<=32"`
@@ -0,0 +1,444 @@
+package backup
+
+import (
+	"context"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/search"
+	"github.com/alist-org/alist/v3/internal/warmer"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const Version = 1
+
+const (
+	ModeMerge   = "merge"
+	ModeReplace = "replace"
+)
+
+// secretSettings are only exported with secrets
+var secretSettings = []string{
+	conf.Token,
+	conf.SSOClientSecret,
+	conf.LdapManagerPassword,
+	conf.S3SecretAccessKey,
+	conf.Aria2Secret,
+}
+
+// runtimeSettings are never exported
+var runtimeSettings = []string{
+	conf.IndexProgress,
+}
+
+type Archive struct {
+	Version   int                 `json:"version"`
+	CreatedAt time.Time           `json:"created_at"`
+	Secrets   bool                `json:"secrets"`
+	Storages  []model.Storage     `json:"storages"`
+	Users     []User              `json:"users"`
+	Metas     []model.Meta        `json:"metas"`
+	Settings  []model.SettingItem `json:"settings"`
+	IndexJobs []model.IndexJob    `json:"index_jobs"`
+	WarmJobs  []model.WarmJob     `json:"warm_jobs"`
+}
+
+// User with the secrets, which are hidden in model.User
+type User struct {
+	model.User
+	PwdHash   string `json:"pwd_hash,omitempty"`
+	PwdTS     int64  `json:"pwd_ts,omitempty"`
+	Salt      string `json:"salt,omitempty"`
+	OtpSecret string `json:"otp_secret,omitempty"`
+	Authn     string `json:"authn,omitempty"`
+}
+
+// Export all configuration into an archive,
+// confidential storage fields, user secrets and meta passwords are only exported when secrets is true
+func Export(secrets bool) (*Archive, error) {
+	a := &Archive{
+		Version:   Version,
+		CreatedAt: time.Now(),
+		Secrets:   secrets,
+	}
+	storages, _, err := db.GetStorages(1, -1)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storages")
+	}
+	for i := range storages {
+		if secrets {
+			// decrypted, so the archive can be imported with another master key
+			storages[i].Addition, err = op.DecryptAddition(storages[i].Driver, storages[i].Addition)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "failed decrypt storage [%s]", storages[i].MountPath)
+			}
+		} else {
+			op.MaskStorage(&storages[i])
+		}
+	}
+	a.Storages = storages
+	users, _, err := db.GetUsers(1, -1)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get users")
+	}
+	for _, u := range users {
+		user := User{User: u}
+		if secrets {
+			user.PwdHash, user.PwdTS, user.Salt = u.PwdHash, u.PwdTS, u.Salt
+			user.OtpSecret, user.Authn = u.OtpSecret, u.Authn
+		}
+		a.Users = append(a.Users, user)
+	}
+	a.Metas, _, err = db.GetMetas(1, -1)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get metas")
+	}
+	if !secrets {
+		for i := range a.Metas {
+			a.Metas[i].Password = ""
+		}
+	}
+	settings, err := db.GetSettingItems()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get settings")
+	}
+	for _, item := range settings {
+		if item.IsDeprecated() || utils.SliceContains(runtimeSettings, item.Key) ||
+			(!secrets && utils.SliceContains(secretSettings, item.Key)) {
+			continue
+		}
+		a.Settings = append(a.Settings, item)
+	}
+	a.IndexJobs, err = db.GetIndexJobs()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get index jobs")
+	}
+	for i := range a.IndexJobs {
+		a.IndexJobs[i].Progress = model.IndexProgress{}
+	}
+	a.WarmJobs, err = db.GetWarmJobs()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get warm jobs")
+	}
+	return a, nil
+}
+
+type ImportArgs struct {
+	// Mode is merge or replace, merge keeps the objects not in the archive,
+	// replace deletes them. Settings are always merged.
+	Mode string
+	// Reload the changed storages in memory, it's false when the server is not running
+	Reload bool
+}
+
+// Import the archive. Objects are matched by mount path, username, path and key,
+// the secrets not in the archive are kept if the object already exists.
+func Import(ctx context.Context, a *Archive, args ImportArgs) error {
+	if a.Version > Version {
+		return errors.Errorf("unsupported archive version %d, the latest supported is %d", a.Version, Version)
+	}
+	if args.Mode != ModeMerge && args.Mode != ModeReplace {
+		return errors.Errorf("invalid import mode: %s", args.Mode)
+	}
+	var errs []error
+	errs = append(errs, importStorages(ctx, a, args)...)
+	errs = append(errs, importUsers(a, args)...)
+	errs = append(errs, importMetas(a, args)...)
+	errs = append(errs, importSettings(a)...)
+	errs = append(errs, importIndexJobs(a, args)...)
+	errs = append(errs, importWarmJobs(a, args)...)
+	return utils.MergeErrors(errs...)
+}
+
+func importStorages(ctx context.Context, a *Archive, args ImportArgs) []error {
+	olds, _, err := db.GetStorages(1, -1)
+	if err != nil {
+		return []error{errors.WithMessage(err, "failed get storages")}
+	}
+	oldMap := make(map[string]*model.Storage)
+	for i := range olds {
+		oldMap[olds[i].MountPath] = &olds[i]
+	}
+	var errs []error
+	seen := make(map[string]struct{})
+	for _, storage := range a.Storages {
+		storage.MountPath = utils.FixAndCleanPath(storage.MountPath)
+		seen[storage.MountPath] = struct{}{}
+		if err := importStorage(ctx, storage, oldMap[storage.MountPath], args.Reload); err != nil {
+			errs = append(errs, errors.WithMessagef(err, "failed import storage [%s]", storage.MountPath))
+		}
+	}
+	if args.Mode != ModeReplace {
+		return errs
+	}
+	for _, old := range olds {
+		if _, ok := seen[old.MountPath]; ok {
+			continue
+		}
+		if err := db.DeleteStorageById(old.ID); err != nil {
+			errs = append(errs, errors.WithMessagef(err, "failed delete storage [%s]", old.MountPath))
+			continue
+		}
+		if args.Reload {
+			op.UnloadStorage(ctx, old.MountPath)
+		}
+	}
+	return errs
+}
+
+func importStorage(ctx context.Context, storage model.Storage, old *model.Storage, reload bool) error {
+	if _, err := op.GetDriver(storage.Driver); err != nil {
+		return err
+	}
+	oldAddition := ""
+	storage.ID = 0
+	if old != nil {
+		if old.Driver != storage.Driver {
+			return errors.Errorf("driver %s of existing storage can't be changed to %s", old.Driver, storage.Driver)
+		}
+		var err error
+		oldAddition, err = op.DecryptAddition(old.Driver, old.Addition)
+		if err != nil {
+			return err
+		}
+		storage.ID = old.ID
+	}
+	addition, err := op.RestoreMasked(storage.Driver, storage.Addition, oldAddition)
+	if err != nil {
+		return err
+	}
+	storage.Addition, err = op.EncryptAddition(storage.Driver, addition)
+	if err != nil {
+		return err
+	}
+	storage.Modified = time.Now()
+	if old != nil {
+		err = db.UpdateStorage(&storage)
+	} else {
+		err = db.CreateStorage(&storage)
+	}
+	if err != nil || !reload {
+		return err
+	}
+	op.UnloadStorage(ctx, storage.MountPath)
+	if storage.Disabled {
+		return nil
+	}
+	return op.LoadStorage(ctx, storage)
+}
+
+func importUsers(a *Archive, args ImportArgs) []error {
+	olds, _, err := db.GetUsers(1, -1)
+	if err != nil {
+		return []error{errors.WithMessage(err, "failed get users")}
+	}
+	// admin and guest are matched by role, as they may be renamed
+	findOld := func(u User) *model.User {
+		for i := range olds {
+			if u.IsAdmin() || u.IsGuest() {
+				if olds[i].Role == u.Role {
+					return &olds[i]
+				}
+			} else if !olds[i].IsAdmin() && !olds[i].IsGuest() && olds[i].Username == u.Username {
+				return &olds[i]
+			}
+		}
+		return nil
+	}
+	var errs []error
+	seen := make(map[uint]struct{})
+	for _, u := range a.Users {
+		user := u.User
+		user.Password = ""
+		old := findOld(u)
+		if u.PwdHash != "" {
+			user.PwdHash, user.PwdTS, user.Salt = u.PwdHash, u.PwdTS, u.Salt
+			user.OtpSecret, user.Authn = u.OtpSecret, u.Authn
+		} else if old != nil {
+			user.PwdHash, user.PwdTS, user.Salt = old.PwdHash, old.PwdTS, old.Salt
+			user.OtpSecret, user.Authn = old.OtpSecret, old.Authn
+		} else {
+			user.SetPassword(random.String(16))
+			log.Warnf("user [%s] is imported without password, please reset it", user.Username)
+		}
+		if old != nil {
+			user.ID = old.ID
+			seen[old.ID] = struct{}{}
+			err = op.UpdateUser(&user)
+		} else {
+			user.ID = 0
+			err = op.CreateUser(&user)
+		}
+		if err != nil {
+			errs = append(errs, errors.WithMessagef(err, "failed import user [%s]", user.Username))
+		}
+	}
+	if args.Mode != ModeReplace {
+		return errs
+	}
+	for _, old := range olds {
+		if _, ok := seen[old.ID]; ok || old.IsAdmin() || old.IsGuest() {
+			continue
+		}
+		if err := op.DeleteUserById(old.ID); err != nil {
+			errs = append(errs, errors.WithMessagef(err, "failed delete user [%s]", old.Username))
+		}
+	}
+	return errs
+}
+
+func importMetas(a *Archive, args ImportArgs) []error {
+	olds, _, err := db.GetMetas(1, -1)
+	if err != nil {
+		return []error{errors.WithMessage(err, "failed get metas")}
+	}
+	oldMap := make(map[string]*model.Meta)
+	for i := range olds {
+		oldMap[olds[i].Path] = &olds[i]
+	}
+	var errs []error
+	seen := make(map[string]struct{})
+	for _, meta := range a.Metas {
+		meta.Path = utils.FixAndCleanPath(meta.Path)
+		seen[meta.Path] = struct{}{}
+		if old, ok := oldMap[meta.Path]; ok {
+			meta.ID = old.ID
+			// passwords are stripped from the archive without secrets
+			if !a.Secrets {
+				meta.Password = old.Password
+			}
+			err = op.UpdateMeta(&meta)
+		} else {
+			meta.ID = 0
+			err = op.CreateMeta(&meta)
+		}
+		if err != nil {
+			errs = append(errs, errors.WithMessagef(err, "failed import meta [%s]", meta.Path))
+		}
+	}
+	if args.Mode != ModeReplace {
+		return errs
+	}
+	for _, old := range olds {
+		if _, ok := seen[old.Path]; ok {
+			continue
+		}
+		if err := op.DeleteMetaById(old.ID); err != nil {
+			errs = append(errs, errors.WithMessagef(err, "failed delete meta [%s]", old.Path))
+		}
+	}
+	return errs
+}
+
+func importSettings(a *Archive) []error {
+	olds, err := db.GetSettingItems()
+	if err != nil {
+		return []error{errors.WithMessage(err, "failed get settings")}
+	}
+	values := make(map[string]string)
+	for _, item := range a.Settings {
+		values[item.Key] = item.Value
+	}
+	var items []model.SettingItem
+	for _, old := range olds {
+		value, ok := values[old.Key]
+		// only values are imported, the other fields follow this version
+		if !ok || old.IsDeprecated() || old.Flag == model.READONLY || utils.SliceContains(runtimeSettings, old.Key) {
+			continue
+		}
+		old.Value = value
+		items = append(items, old)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	if err = op.SaveSettingItems(items); err != nil {
+		return []error{errors.WithMessage(err, "failed import settings")}
+	}
+	return nil
+}
+
+func importIndexJobs(a *Archive, args ImportArgs) []error {
+	olds, err := db.GetIndexJobs()
+	if err != nil {
+		return []error{errors.WithMessage(err, "failed get index jobs")}
+	}
+	oldMap := make(map[string]uint)
+	for _, old := range olds {
+		oldMap[old.Path] = old.ID
+	}
+	var errs []error
+	seen := make(map[string]struct{})
+	for _, job := range a.IndexJobs {
+		job.Path = utils.FixAndCleanPath(job.Path)
+		job.Progress = model.IndexProgress{}
+		seen[job.Path] = struct{}{}
+		if id, ok := oldMap[job.Path]; ok {
+			job.ID = id
+			err = search.UpdateIndexJob(&job)
+		} else {
+			job.ID = 0
+			err = search.CreateIndexJob(&job)
+		}
+		if err != nil {
+			errs = append(errs, errors.WithMessagef(err, "failed import index job [%s]", job.Path))
+		}
+	}
+	if args.Mode != ModeReplace {
+		return errs
+	}
+	for _, old := range olds {
+		if _, ok := seen[old.Path]; ok {
+			continue
+		}
+		if err := search.DeleteIndexJobById(old.ID); err != nil {
+			errs = append(errs, errors.WithMessagef(err, "failed delete index job [%s]", old.Path))
+		}
+	}
+	return errs
+}
+
+func importWarmJobs(a *Archive, args ImportArgs) []error {
+	olds, err := db.GetWarmJobs()
+	if err != nil {
+		return []error{errors.WithMessage(err, "failed get warm jobs")}
+	}
+	oldMap := make(map[string]uint)
+	for _, old := range olds {
+		oldMap[old.Path] = old.ID
+	}
+	var errs []error
+	seen := make(map[string]struct{})
+	for _, job := range a.WarmJobs {
+		job.Path = utils.FixAndCleanPath(job.Path)
+		seen[job.Path] = struct{}{}
+		if id, ok := oldMap[job.Path]; ok {
+			job.ID = id
+			err = warmer.UpdateWarmJob(&job)
+		} else {
+			job.ID = 0
+			err = warmer.CreateWarmJob(&job)
+		}
+		if err != nil {
+			errs = append(errs, errors.WithMessagef(err, "failed import warm job [%s]", job.Path))
+		}
+	}
+	if args.Mode != ModeReplace {
+		return errs
+	}
+	for _, old := range olds {
+		if _, ok := seen[old.Path]; ok {
+			continue
+		}
+		if err := warmer.DeleteWarmJobById(old.ID); err != nil {
+			errs = append(errs, errors.WithMessagef(err, "failed delete warm job [%s]", old.Path))
+		}
+	}
+	return errs
+}
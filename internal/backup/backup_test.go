@@ -0,0 +1,67 @@
+package backup_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/backup"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+func TestExportImport(t *testing.T) {
+	admin := &model.User{Username: "admin", Role: model.ADMIN}
+	admin.SetPassword("password")
+	if err := op.CreateUser(admin); err != nil {
+		t.Fatalf("failed create admin: %+v", err)
+	}
+	if err := op.CreateMeta(&model.Meta{Path: "/a", Password: "123"}); err != nil {
+		t.Fatalf("failed create meta: %+v", err)
+	}
+	a, err := backup.Export(false)
+	if err != nil {
+		t.Fatalf("failed export: %+v", err)
+	}
+	if len(a.Users) != 1 || a.Users[0].PwdHash != "" {
+		t.Errorf("expected user exported without secrets, got: %+v", a.Users)
+	}
+	if len(a.Metas) != 1 || a.Metas[0].Password != "" {
+		t.Errorf("expected meta exported without password, got: %+v", a.Metas)
+	}
+
+	if err = op.CreateMeta(&model.Meta{Path: "/b"}); err != nil {
+		t.Fatalf("failed create meta: %+v", err)
+	}
+	admin.Username = "root"
+	if err = op.UpdateUser(admin); err != nil {
+		t.Fatalf("failed update admin: %+v", err)
+	}
+	err = backup.Import(context.Background(), a, backup.ImportArgs{Mode: backup.ModeReplace})
+	if err != nil {
+		t.Fatalf("failed import: %+v", err)
+	}
+	metas, _, _ := db.GetMetas(1, -1)
+	if len(metas) != 1 || metas[0].Path != "/a" || metas[0].Password != "123" {
+		t.Errorf("expected only meta /a with the password kept after replace, got: %+v", metas)
+	}
+	users, _, _ := db.GetUsers(1, -1)
+	if len(users) != 1 || users[0].Username != "admin" {
+		t.Fatalf("expected admin renamed back, got: %+v", users)
+	}
+	if err = users[0].ValidateRawPassword("password"); err != nil {
+		t.Errorf("expected password kept when not in archive: %+v", err)
+	}
+}
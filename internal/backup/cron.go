@@ -0,0 +1,158 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	stdpath "path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	filePrefix = "alist-backup-"
+	fileSuffix = ".json"
+	timeFormat = "20060102-150405"
+)
+
+var (
+	backupCron *cron.Cron
+	lastBackup time.Time
+	backupMu   sync.Mutex
+)
+
+// InitCron checks the backup settings every minute, so changes take effect without restart
+func InitCron() {
+	if backupCron != nil {
+		backupCron.Stop()
+	}
+	lastBackup = time.Time{}
+	backupCron = cron.NewCron(time.Minute)
+	backupCron.Do(func() {
+		dir := setting.GetStr(conf.BackupPath)
+		interval := setting.GetInt(conf.BackupInterval, 24)
+		if dir == "" || interval <= 0 {
+			return
+		}
+		// resume the schedule from the latest backup, so a restart neither
+		// postpones nor repeats it, it's retried until the dir is listable
+		if lastBackup.IsZero() {
+			latest, err := latestBackup(context.Background(), dir)
+			if err != nil {
+				log.Warnf("failed to find the latest backup: %+v", err)
+				return
+			}
+			lastBackup = latest
+		}
+		if time.Since(lastBackup) < time.Duration(interval)*time.Hour {
+			return
+		}
+		lastBackup = time.Now()
+		if err := SaveTo(context.Background(), dir, setting.GetBool(conf.BackupSecrets)); err != nil {
+			log.Errorf("failed to backup: %+v", err)
+			return
+		}
+		if err := clean(context.Background(), dir, setting.GetInt(conf.BackupKeep, 7)); err != nil {
+			log.Errorf("failed to clean old backups: %+v", err)
+		}
+	})
+}
+
+// SaveTo exports an archive into dir of alist
+func SaveTo(ctx context.Context, dir string, secrets bool) error {
+	backupMu.Lock()
+	defer backupMu.Unlock()
+	a, err := Export(secrets)
+	if err != nil {
+		return err
+	}
+	data, err := utils.Json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed marshal archive")
+	}
+	name := fmt.Sprintf("%s%s%s", filePrefix, a.CreatedAt.Format(timeFormat), fileSuffix)
+	s := &stream.FileStream{
+		Ctx: ctx,
+		Obj: &model.Object{
+			Name:     name,
+			Size:     int64(len(data)),
+			Modified: a.CreatedAt,
+		},
+		Reader:   bytes.NewReader(data),
+		Mimetype: "application/json",
+	}
+	if err = fs.PutDirectly(ctx, dir, s); err != nil {
+		return err
+	}
+	log.Infof("backup saved to %s", stdpath.Join(dir, name))
+	return nil
+}
+
+// clean removes the old backups, only keep the latest ones
+func clean(ctx context.Context, dir string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	names, err := backupNames(ctx, dir)
+	if err != nil {
+		return err
+	}
+	if len(names) <= keep {
+		return nil
+	}
+	var errs []error
+	for _, name := range names[:len(names)-keep] {
+		if err = fs.Remove(ctx, stdpath.Join(dir, name)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utils.MergeErrors(errs...)
+}
+
+// backupNames lists the backups in dir, sorted from the oldest to the latest
+func backupNames(ctx context.Context, dir string) ([]string, error) {
+	objs, err := fs.List(ctx, dir, &fs.ListArgs{Refresh: true, NoLog: true})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, obj := range objs {
+		if !obj.IsDir() && strings.HasPrefix(obj.GetName(), filePrefix) && strings.HasSuffix(obj.GetName(), fileSuffix) {
+			names = append(names, obj.GetName())
+		}
+	}
+	// names are sorted by time as the time is formatted in order
+	sort.Strings(names)
+	return names, nil
+}
+
+// latestBackup returns the time of the latest backup in dir, zero if there is none
+func latestBackup(ctx context.Context, dir string) (time.Time, error) {
+	names, err := backupNames(ctx, dir)
+	if err != nil {
+		if errs.IsObjectNotFound(err) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	for i := len(names) - 1; i >= 0; i-- {
+		name := strings.TrimSuffix(strings.TrimPrefix(names[i], filePrefix), fileSuffix)
+		if t, err := time.ParseInLocation(timeFormat, name, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, nil
+}
@@ -0,0 +1,29 @@
+package bootstrap
+
+import (
+	"github.com/alist-org/alist/v3/internal/cache"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/op"
+	log "github.com/sirupsen/logrus"
+)
+
+func InitCache() {
+	if err := cache.Init(conf.Conf.Cache); err != nil {
+		log.Fatalf("failed init cache: %+v", err)
+	}
+	if conf.Conf.Cache.Type == cache.TypeRedis {
+		log.Infof("use redis cache @ %s", conf.Conf.Cache.Address)
+	}
+}
+
+func InitListCache() {
+	c := conf.Conf.ListCache
+	if !c.Enable {
+		return
+	}
+	if err := op.InitListDiskCache(c.Path, int64(c.MaxSize)*1024*1024); err != nil {
+		log.Errorf("failed init list disk cache: %+v", err)
+		return
+	}
+	log.Infof("use list disk cache @ %s", c.Path)
+}
@@ -145,6 +145,12 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.ForwardDirectLinkParams, Value: "false", Type: conf.TypeBool, Group: model.GLOBAL},
 		{Key: conf.IgnoreDirectLinkParams, Value: "sign,alist_ts", Type: conf.TypeString, Group: model.GLOBAL},
 		{Key: conf.WebauthnLoginEnabled, Value: "false", Type: conf.TypeBool, Group: model.GLOBAL, Flag: model.PUBLIC},
+		{Key: conf.BackupPath, Value: "", Type: conf.TypeString, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `the path to save backups in, empty to disable scheduled backup`},
+		{Key: conf.BackupInterval, Value: "24", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `hours between scheduled backups`},
+		{Key: conf.BackupKeep, Value: "7", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `number of backups to keep, 0 to keep all`},
+		{Key: conf.BackupSecrets, Value: "false", Type: conf.TypeBool, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `include storage credentials and user passwords in scheduled backups`},
+		{Key: conf.ChangeJournalRetention, Value: "168", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `hours to keep the changes for the sync clients, 0 to disable the change journal`},
+		{Key: conf.WarmConcurrency, Value: "2", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `max concurrent listings of a storage when warming the cache`},
 
 		// single settings
 		{Key: conf.Token, Value: token, Type: conf.TypeString, Group: model.SINGLE, Flag: model.PRIVATE},
@@ -152,6 +158,7 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.AutoUpdateIndex, Value: "false", Type: conf.TypeBool, Group: model.INDEX},
 		{Key: conf.IgnorePaths, Value: "", Type: conf.TypeText, Group: model.INDEX, Flag: model.PRIVATE, Help: `one path per line`},
 		{Key: conf.MaxIndexDepth, Value: "20", Type: conf.TypeNumber, Group: model.INDEX, Flag: model.PRIVATE, Help: `max depth of index`},
+		{Key: conf.IndexPhotoExif, Value: "false", Type: conf.TypeBool, Group: model.INDEX, Flag: model.PRIVATE, Help: `scan the exif of the images into the photo timeline after building the index`},
 		{Key: conf.IndexProgress, Value: "{}", Type: conf.TypeText, Group: model.SINGLE, Flag: model.PRIVATE},
 
 		// SSO settings
@@ -184,6 +191,13 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.S3AccessKeyId, Value: "", Type: conf.TypeString, Group: model.S3, Flag: model.PRIVATE},
 		{Key: conf.S3SecretAccessKey, Value: "", Type: conf.TypeString, Group: model.S3, Flag: model.PRIVATE},
 		{Key: conf.S3Buckets, Value: "[]", Type: conf.TypeString, Group: model.S3, Flag: model.PRIVATE},
+
+		// music settings
+		{Key: conf.MusicPaths, Value: "", Type: conf.TypeText, Group: model.MUSIC, Flag: model.PRIVATE, Help: `paths scanned into the music library, one path per line`},
+		{Key: conf.MusicScanInterval, Value: "0", Type: conf.TypeNumber, Group: model.MUSIC, Flag: model.PRIVATE, Help: `hours between the scans of the music library, 0 means scan manually`},
+		{Key: conf.DLNAPaths, Value: "/", Type: conf.TypeText, Group: model.DLNA, Flag: model.PRIVATE, Help: `paths exposed by the dlna server, one path per line`},
+		{Key: conf.DLNAUser, Value: "", Type: conf.TypeString, Group: model.DLNA, Flag: model.PRIVATE, Help: `the user to browse as, empty means the guest`},
+		{Key: conf.WopiClientURL, Value: "", Type: conf.TypeString, Group: model.WOPI, Flag: model.PRIVATE, Help: `url of the Collabora/OnlyOffice server to edit the office documents, like http://localhost:9980`},
 	}
 	initialSettingItems = append(initialSettingItems, tool.Tools.Items()...)
 	if flags.Dev {
@@ -16,3 +16,7 @@ func InitIndex() {
 		search.WriteProgress(progress)
 	}
 }
+
+func InitIndexJobs() {
+	search.InitIndexJobs()
+}
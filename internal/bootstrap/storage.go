@@ -11,6 +11,11 @@ import (
 )
 
 func LoadStorages() {
+	if n, err := op.EncryptPlainStorages(); err != nil {
+		utils.Log.Errorf("failed encrypt plaintext storages: %+v", err)
+	} else if n > 0 {
+		utils.Log.Infof("encrypted the confidential fields of %d storages with the master key", n)
+	}
 	storages, err := db.GetEnabledStorages()
 	if err != nil {
 		utils.Log.Fatalf("failed get enabled storages: %+v", err)
@@ -0,0 +1,199 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gocache "github.com/Xhofe/go-cache"
+	log "github.com/sirupsen/logrus"
+)
+
+// Cache is the cache used by op, it's kept in memory by default,
+// and shared by all instances if redis is configured.
+type Cache[V any] interface {
+	Get(key string) (V, bool)
+	// Set stores the value, ttl <= 0 means it never expires
+	Set(key string, value V, ttl time.Duration)
+	// Update stores the value changed by a write, so the other instances drop their copies
+	Update(key string, value V, ttl time.Duration)
+	// Del deletes the keys in all instances
+	Del(keys ...string)
+	// Drop deletes the keys without notifying the other instances,
+	// it's for the entries found stale by a read rather than changed by a write
+	Drop(keys ...string)
+	// Clear deletes all keys in all instances
+	Clear()
+}
+
+type backendCache interface {
+	getName() string
+	setBackend(b *redisBackend)
+	invalidate(keys []string)
+}
+
+var (
+	registry   = map[string]backendCache{}
+	registryMu sync.Mutex
+	current    *redisBackend
+)
+
+func register(c backendCache) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[c.getName()]; ok {
+		panic("cache already exists: " + c.getName())
+	}
+	registry[c.getName()] = c
+	if current != nil {
+		current.add(c)
+	}
+}
+
+// localTTL is how long the entries of redis are kept in memory,
+// the copies are dropped by the invalidations of writes, it only bounds a missed one.
+const localTTL = 5 * time.Second
+
+// New creates a cache whose entries are stored in redis if configured,
+// so the values must be encodable by gob. They are also kept in memory for localTTL,
+// so the hot keys are not fetched from redis on every lookup.
+func New[V any](name string, shards int) Cache[V] {
+	c := newStore[V](name, shards, false)
+	register(c)
+	return c
+}
+
+// NewLocal creates a cache whose entries are always kept in memory of each instance,
+// only the invalidations are shared. It's for the values can't be encoded, like model.Obj
+// which is implemented by drivers and type asserted by them.
+// onInvalidate is called with the keys invalidated by the other instances, empty keys means all.
+func NewLocal[V any](name string, shards int, onInvalidate ...func(keys []string)) Cache[V] {
+	c := newStore[V](name, shards, true)
+	c.onInvalidate = onInvalidate
+	register(c)
+	return c
+}
+
+type store[V any] struct {
+	name    string
+	local   bool
+	mem     gocache.ICache[V]
+	backend atomic.Pointer[redisBackend]
+
+	onInvalidate []func(keys []string)
+}
+
+func newStore[V any](name string, shards int, local bool) *store[V] {
+	return &store[V]{
+		name:  name,
+		local: local,
+		mem:   gocache.NewMemCache(gocache.WithShards[V](shards)),
+	}
+}
+
+func (s *store[V]) getName() string {
+	return s.name
+}
+
+func (s *store[V]) setBackend(b *redisBackend) {
+	s.backend.Store(b)
+	s.mem.Clear()
+}
+
+func (s *store[V]) invalidate(keys []string) {
+	if len(keys) == 0 {
+		s.mem.Clear()
+	} else {
+		s.mem.Del(keys...)
+	}
+	for _, fn := range s.onInvalidate {
+		fn(keys)
+	}
+}
+
+// shared returns the backend if the entries are stored in it
+func (s *store[V]) shared() *redisBackend {
+	if s.local {
+		return nil
+	}
+	return s.backend.Load()
+}
+
+func (s *store[V]) Get(key string) (V, bool) {
+	if value, ok := s.mem.Get(key); ok {
+		return value, true
+	}
+	b := s.shared()
+	if b == nil {
+		var zero V
+		return zero, false
+	}
+	value, ok, err := redisGet[V](b, s.name, key)
+	if err != nil {
+		log.Warnf("failed get cache %s[%s]: %+v", s.name, key, err)
+	}
+	if ok {
+		s.mem.Set(key, value, gocache.WithEx[V](localTTL))
+	}
+	return value, ok
+}
+
+func (s *store[V]) Set(key string, value V, ttl time.Duration) {
+	if b := s.shared(); b != nil {
+		if err := redisSet(b, s.name, key, value, ttl); err != nil {
+			log.Warnf("failed set cache %s[%s]: %+v", s.name, key, err)
+			return
+		}
+		if ttl <= 0 || ttl > localTTL {
+			ttl = localTTL
+		}
+	}
+	if ttl > 0 {
+		s.mem.Set(key, value, gocache.WithEx[V](ttl))
+	} else {
+		s.mem.Set(key, value)
+	}
+}
+
+func (s *store[V]) Update(key string, value V, ttl time.Duration) {
+	s.Set(key, value, ttl)
+	if b := s.backend.Load(); b != nil {
+		b.publish(s.name, []string{key})
+	}
+}
+
+func (s *store[V]) Del(keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	s.Drop(keys...)
+	if b := s.backend.Load(); b != nil {
+		b.publish(s.name, keys)
+	}
+}
+
+func (s *store[V]) Drop(keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	s.mem.Del(keys...)
+	if b := s.shared(); b != nil {
+		if err := b.del(s.name, keys); err != nil {
+			log.Warnf("failed delete cache %s%v: %+v", s.name, keys, err)
+		}
+	}
+}
+
+func (s *store[V]) Clear() {
+	s.mem.Clear()
+	b := s.backend.Load()
+	if b == nil {
+		return
+	}
+	if !s.local {
+		if err := b.clear(s.name); err != nil {
+			log.Warnf("failed clear cache %s: %+v", s.name, err)
+		}
+	}
+	b.publish(s.name, nil)
+}
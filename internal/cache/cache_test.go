@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestBackend acts as an instance connected to the redis
+func newTestBackend(t *testing.T, addr string) *redisBackend {
+	b := newRedisBackend(redis.NewClient(&redis.Options{Addr: addr}), "test:")
+	if err := b.subscribe(context.Background()); err != nil {
+		t.Fatalf("failed to subscribe: %+v", err)
+	}
+	t.Cleanup(func() {
+		_ = b.close()
+	})
+	return b
+}
+
+func eventually(t *testing.T, cond func() bool, msg string) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error(msg)
+}
+
+type testValue struct {
+	Name   string
+	Secret string `json:"-"`
+}
+
+func TestSharedCache(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c1 := newStore[*testValue]("shared", 1, false)
+	c2 := newStore[*testValue]("shared", 1, false)
+	newTestBackend(t, mr.Addr()).add(c1)
+	newTestBackend(t, mr.Addr()).add(c2)
+
+	c1.Set("a", &testValue{Name: "a", Secret: "s"}, time.Minute)
+	if v, ok := c2.Get("a"); !ok || v.Name != "a" || v.Secret != "s" {
+		t.Errorf("expect the entry shared with all fields, got %+v", v)
+	}
+	c1.Set("nil", nil, 0)
+	if v, ok := c2.Get("nil"); !ok || v != nil {
+		t.Errorf("expect the nil entry shared, got %+v, %v", v, ok)
+	}
+	c2.Del("a")
+	eventually(t, func() bool {
+		_, ok := c1.Get("a")
+		return !ok
+	}, "expect the entry deleted")
+	c1.Set("b", &testValue{Name: "b"}, 0)
+	c2.Clear()
+	eventually(t, func() bool {
+		_, ok := c1.Get("b")
+		return !ok
+	}, "expect the entry of other instance cleared")
+	if len(mr.Keys()) != 0 {
+		t.Errorf("expect all entries cleared, but got %v", mr.Keys())
+	}
+}
+
+func TestSharedCacheLocalTier(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c1 := newStore[int]("tier", 1, false)
+	c2 := newStore[int]("tier", 1, false)
+	newTestBackend(t, mr.Addr()).add(c1)
+	newTestBackend(t, mr.Addr()).add(c2)
+
+	c1.Set("a", 1, 0)
+	if v, ok := c2.Get("a"); !ok || v != 1 {
+		t.Fatalf("expect the entry shared, got %d", v)
+	}
+	// the copy of c2 is kept in memory, the lookups don't reach redis
+	mr.Del("test:tier:a")
+	if v, ok := c2.Get("a"); !ok || v != 1 {
+		t.Errorf("expect the entry kept in memory, got %d", v)
+	}
+	c1.Update("a", 2, 0)
+	eventually(t, func() bool {
+		v, ok := c2.Get("a")
+		return ok && v == 2
+	}, "expect the copy of other instance dropped after update")
+
+	// drop is only for this instance, the copy of the other one is kept
+	c1.Drop("a")
+	time.Sleep(50 * time.Millisecond)
+	if v, ok := c2.Get("a"); !ok || v != 2 {
+		t.Errorf("expect the copy of other instance kept after drop, got %d, %v", v, ok)
+	}
+	if _, ok := c1.Get("a"); ok {
+		t.Errorf("expect the entry dropped")
+	}
+}
+
+func TestLocalCache(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c1 := newStore[int]("local", 1, true)
+	c2 := newStore[int]("local", 1, true)
+	newTestBackend(t, mr.Addr()).add(c1)
+	newTestBackend(t, mr.Addr()).add(c2)
+
+	c1.Set("a", 1, 0)
+	if _, ok := c2.Get("a"); ok {
+		t.Errorf("expect the entry not shared")
+	}
+	c2.Set("a", 2, 0)
+	c1.Update("a", 3, 0)
+	eventually(t, func() bool {
+		_, ok := c2.Get("a")
+		return !ok
+	}, "expect the entry of other instance dropped after update")
+	if v, ok := c1.Get("a"); !ok || v != 3 {
+		t.Errorf("expect the updated entry kept, got %d", v)
+	}
+
+	c2.Set("b", 1, 0)
+	c1.Del("b")
+	eventually(t, func() bool {
+		_, ok := c2.Get("b")
+		return !ok
+	}, "expect the entry of other instance deleted")
+
+	c2.Set("c", 1, 0)
+	c1.Clear()
+	eventually(t, func() bool {
+		_, ok := c2.Get("c")
+		return !ok
+	}, "expect the entries of other instance cleared")
+}
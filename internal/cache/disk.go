@@ -0,0 +1,235 @@
+package cache
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"go.etcd.io/bbolt"
+)
+
+var (
+	entryBucket  = []byte("entries")
+	expireBucket = []byte("expires")
+)
+
+// Disk is a persistent cache of bytes,
+// the entries expiring first are evicted when it's larger than maxSize.
+type Disk struct {
+	db      *bbolt.DB
+	maxSize int64
+	size    atomic.Int64
+}
+
+// OpenDisk opens the cache file, it's recreated if broken,
+// and it fails if the file is locked by another process.
+func OpenDisk(path string, maxSize int64) (*Disk, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	d, err := openDisk(path, maxSize)
+	if err != nil && !errors.Is(err, bbolt.ErrTimeout) {
+		log.Warnf("failed open disk cache, recreate it: %+v", err)
+		if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, errors.WithStack(err)
+		}
+		d, err = openDisk(path, maxSize)
+	}
+	return d, err
+}
+
+func openDisk(path string, maxSize int64) (*Disk, error) {
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	d := &Disk{db: db, maxSize: maxSize}
+	err = db.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(entryBucket)
+		if err != nil {
+			return err
+		}
+		if _, err = tx.CreateBucketIfNotExists(expireBucket); err != nil {
+			return err
+		}
+		return b.ForEach(func(k, v []byte) error {
+			d.size.Add(int64(len(k) + len(v)))
+			return nil
+		})
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, errors.WithStack(err)
+	}
+	return d, nil
+}
+
+// expireKey is sorted by the expire time
+func expireKey(expireAt uint64, key []byte) []byte {
+	k := make([]byte, 8+len(key))
+	binary.BigEndian.PutUint64(k, expireAt)
+	copy(k[8:], key)
+	return k
+}
+
+// Get returns the value and the remaining ttl
+func (d *Disk) Get(key string) ([]byte, time.Duration, bool) {
+	var value []byte
+	var expireAt uint64
+	_ = d.db.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket(entryBucket).Get([]byte(key))
+		if len(v) < 8 {
+			return nil
+		}
+		expireAt = binary.BigEndian.Uint64(v)
+		value = append([]byte{}, v[8:]...)
+		return nil
+	})
+	if value == nil {
+		return nil, 0, false
+	}
+	if expireAt == math.MaxUint64 {
+		return value, 0, true
+	}
+	ttl := time.Until(time.Unix(0, int64(expireAt)))
+	if ttl <= 0 {
+		_ = d.Del(key)
+		return nil, 0, false
+	}
+	return value, ttl, true
+}
+
+// Set stores the value, ttl <= 0 means it never expires
+func (d *Disk) Set(key string, value []byte, ttl time.Duration) error {
+	expireAt := uint64(math.MaxUint64)
+	if ttl > 0 {
+		expireAt = uint64(time.Now().Add(ttl).UnixNano())
+	}
+	v := make([]byte, 8+len(value))
+	binary.BigEndian.PutUint64(v, expireAt)
+	copy(v[8:], value)
+	var delta int64
+	err := d.db.Batch(func(tx *bbolt.Tx) error {
+		delta = 0
+		k := []byte(key)
+		n, err := del(tx, k)
+		if err != nil {
+			return err
+		}
+		delta -= n
+		if err = tx.Bucket(entryBucket).Put(k, v); err != nil {
+			return err
+		}
+		delta += int64(len(k) + len(v))
+		return tx.Bucket(expireBucket).Put(expireKey(expireAt, k), nil)
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if d.size.Add(delta) > d.maxSize && d.maxSize > 0 {
+		return d.evict()
+	}
+	return nil
+}
+
+// del deletes the entry and returns its size
+func del(tx *bbolt.Tx, key []byte) (int64, error) {
+	b := tx.Bucket(entryBucket)
+	v := b.Get(key)
+	if v == nil {
+		return 0, nil
+	}
+	size := int64(len(key) + len(v))
+	if len(v) >= 8 {
+		if err := tx.Bucket(expireBucket).Delete(expireKey(binary.BigEndian.Uint64(v), key)); err != nil {
+			return 0, err
+		}
+	}
+	return size, b.Delete(key)
+}
+
+func (d *Disk) Del(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	var deleted int64
+	err := d.db.Batch(func(tx *bbolt.Tx) error {
+		deleted = 0
+		for _, key := range keys {
+			n, err := del(tx, []byte(key))
+			if err != nil {
+				return err
+			}
+			deleted += n
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	d.size.Add(-deleted)
+	return nil
+}
+
+func (d *Disk) Clear() error {
+	err := d.db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{entryBucket, expireBucket} {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+			if _, err := tx.CreateBucket(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	d.size.Store(0)
+	return nil
+}
+
+// evict deletes the entries expiring first until the size is below 90% of maxSize
+func (d *Disk) evict() error {
+	var deleted int64
+	err := d.db.Update(func(tx *bbolt.Tx) error {
+		deleted = 0
+		target := d.size.Load() - d.maxSize*9/10
+		c := tx.Bucket(expireBucket).Cursor()
+		var keys [][]byte
+		for k, _ := c.First(); k != nil && deleted < target; k, _ = c.Next() {
+			key := append([]byte{}, k[8:]...)
+			keys = append(keys, key)
+			if v := tx.Bucket(entryBucket).Get(key); v != nil {
+				deleted += int64(len(key) + len(v))
+			}
+		}
+		// can't delete while iterating
+		for _, key := range keys {
+			if _, err := del(tx, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	d.size.Add(-deleted)
+	return nil
+}
+
+// Size returns the size of all entries in bytes
+func (d *Disk) Size() int64 {
+	return d.size.Load()
+}
+
+func (d *Disk) Close() error {
+	return d.db.Close()
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDisk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	d, err := OpenDisk(path, 0)
+	if err != nil {
+		t.Fatalf("failed to open: %+v", err)
+	}
+	if err = d.Set("a", []byte("1"), time.Minute); err != nil {
+		t.Fatalf("failed to set: %+v", err)
+	}
+	if err = d.Set("b", []byte("2"), time.Millisecond); err != nil {
+		t.Fatalf("failed to set: %+v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, _, ok := d.Get("b"); ok {
+		t.Errorf("expect expired entry missed")
+	}
+	if err = d.Close(); err != nil {
+		t.Fatalf("failed to close: %+v", err)
+	}
+
+	d, err = OpenDisk(path, 0)
+	if err != nil {
+		t.Fatalf("failed to reopen: %+v", err)
+	}
+	defer d.Close()
+	v, ttl, ok := d.Get("a")
+	if !ok || !bytes.Equal(v, []byte("1")) || ttl <= 0 || ttl > time.Minute {
+		t.Errorf("expect entry persisted, got %s, %s, %v", v, ttl, ok)
+	}
+	if err = d.Del("a"); err != nil {
+		t.Fatalf("failed to delete: %+v", err)
+	}
+	if _, _, ok = d.Get("a"); ok || d.Size() != 0 {
+		t.Errorf("expect entry deleted, size %d", d.Size())
+	}
+}
+
+func TestDiskEvict(t *testing.T) {
+	d, err := OpenDisk(filepath.Join(t.TempDir(), "cache.db"), 1000)
+	if err != nil {
+		t.Fatalf("failed to open: %+v", err)
+	}
+	defer d.Close()
+	value := make([]byte, 300)
+	for i, key := range []string{"a", "b", "c", "d"} {
+		if err = d.Set(key, value, time.Duration(i+1)*time.Minute); err != nil {
+			t.Fatalf("failed to set: %+v", err)
+		}
+	}
+	// a and b expire first, so they are evicted
+	for key, expect := range map[string]bool{"a": false, "b": false, "c": true, "d": true} {
+		if _, _, ok := d.Get(key); ok != expect {
+			t.Errorf("expect %s exists: %v", key, expect)
+		}
+	}
+	if d.Size() > 900 {
+		t.Errorf("expect size below limit, got %d", d.Size())
+	}
+}
@@ -0,0 +1,222 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	TypeMemory = "memory"
+	TypeRedis  = "redis"
+)
+
+const redisTimeout = 3 * time.Second
+
+// entry wraps the value, so nil pointers can be encoded by gob
+type entry[V any] struct {
+	Value V
+}
+
+// message is published to the other instances to drop their copies,
+// empty Keys means all keys of the cache
+type message struct {
+	Instance string   `json:"instance"`
+	Cache    string   `json:"cache"`
+	Keys     []string `json:"keys"`
+}
+
+type redisBackend struct {
+	client   redis.UniversalClient
+	prefix   string
+	instance string
+	caches   generic_sync.MapOf[string, backendCache]
+	pubsub   *redis.PubSub
+}
+
+func newRedisBackend(client redis.UniversalClient, prefix string) *redisBackend {
+	return &redisBackend{
+		client:   client,
+		prefix:   prefix,
+		instance: random.String(16),
+	}
+}
+
+func (b *redisBackend) add(c backendCache) {
+	b.caches.Store(c.getName(), c)
+	c.setBackend(b)
+}
+
+func (b *redisBackend) key(name, key string) string {
+	return b.prefix + name + ":" + key
+}
+
+func (b *redisBackend) channel() string {
+	return b.prefix + "invalidate"
+}
+
+func redisGet[V any](b *redisBackend, name, key string) (V, bool, error) {
+	var e entry[V]
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	data, err := b.client.Get(ctx, b.key(name, key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return e.Value, false, nil
+	}
+	if err != nil {
+		return e.Value, false, errors.WithStack(err)
+	}
+	if err = gob.NewDecoder(bytes.NewReader(data)).Decode(&e); err != nil {
+		return e.Value, false, errors.WithStack(err)
+	}
+	return e.Value, true, nil
+}
+
+func redisSet[V any](b *redisBackend, name, key string, value V, ttl time.Duration) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entry[V]{Value: value}); err != nil {
+		return errors.WithStack(err)
+	}
+	if ttl < 0 {
+		ttl = 0
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	return errors.WithStack(b.client.Set(ctx, b.key(name, key), buf.Bytes(), ttl).Err())
+}
+
+func (b *redisBackend) del(name string, keys []string) error {
+	redisKeys := make([]string, len(keys))
+	for i, key := range keys {
+		redisKeys[i] = b.key(name, key)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	return errors.WithStack(b.client.Del(ctx, redisKeys...).Err())
+}
+
+func (b *redisBackend) clear(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	iter := b.client.Scan(ctx, 0, b.key(name, "*"), 1000).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return errors.WithStack(err)
+	}
+	for len(keys) > 0 {
+		n := min(len(keys), 1000)
+		if err := b.client.Del(ctx, keys[:n]...).Err(); err != nil {
+			return errors.WithStack(err)
+		}
+		keys = keys[n:]
+	}
+	return nil
+}
+
+func (b *redisBackend) publish(name string, keys []string) {
+	data, err := utils.Json.Marshal(message{Instance: b.instance, Cache: name, Keys: keys})
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+		defer cancel()
+		err = b.client.Publish(ctx, b.channel(), data).Err()
+	}
+	if err != nil {
+		log.Warnf("failed publish invalidation of cache %s: %+v", name, err)
+	}
+}
+
+// subscribe receives the invalidations from the other instances
+func (b *redisBackend) subscribe(ctx context.Context) error {
+	b.pubsub = b.client.Subscribe(ctx, b.channel())
+	// wait for the subscription, so no invalidation is missed after init
+	if _, err := b.pubsub.Receive(ctx); err != nil {
+		_ = b.pubsub.Close()
+		return errors.WithStack(err)
+	}
+	go func() {
+		for msg := range b.pubsub.Channel() {
+			var m message
+			if err := utils.Json.UnmarshalFromString(msg.Payload, &m); err != nil {
+				log.Warnf("invalid cache invalidation: %s", msg.Payload)
+				continue
+			}
+			if m.Instance == b.instance {
+				continue
+			}
+			if c, ok := b.caches.Load(m.Cache); ok {
+				c.invalidate(m.Keys)
+			}
+		}
+	}()
+	return nil
+}
+
+func (b *redisBackend) close() error {
+	if b.pubsub != nil {
+		_ = b.pubsub.Close()
+	}
+	return b.client.Close()
+}
+
+// Init sets the backend of all caches by config
+func Init(c conf.Cache) error {
+	switch c.Type {
+	case "", TypeMemory:
+		return nil
+	case TypeRedis:
+	default:
+		return errors.Errorf("not supported cache type: %s", c.Type)
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:     c.Address,
+		Username: c.Username,
+		Password: c.Password,
+		DB:       c.DB,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return errors.Wrap(err, "failed connect to redis")
+	}
+	b := newRedisBackend(client, c.Prefix)
+	if err := b.subscribe(context.Background()); err != nil {
+		_ = client.Close()
+		return errors.Wrap(err, "failed subscribe redis")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, c := range registry {
+		b.add(c)
+	}
+	current = b
+	return nil
+}
+
+// Release switches the caches back to memory and closes redis
+func Release() {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if current == nil {
+		return
+	}
+	for _, c := range registry {
+		c.setBackend(nil)
+	}
+	if err := current.close(); err != nil {
+		log.Warnf("failed close redis: %+v", err)
+	}
+	current = nil
+}
@@ -26,6 +26,25 @@ type Meilisearch struct {
 	IndexPrefix string `json:"index_prefix" env:"INDEX_PREFIX"`
 }
 
+// Cache is the backend of the caches. With redis, the settings, metas, users and url links are shared
+// by all instances, while the listings and the links with readers are kept in each instance as they
+// can't be encoded, only their invalidations are shared.
+type Cache struct {
+	Type     string `json:"type" env:"TYPE"`
+	Address  string `json:"address" env:"ADDRESS"`
+	Username string `json:"username" env:"USERNAME"`
+	Password string `json:"password" env:"PASSWORD"`
+	DB       int    `json:"db" env:"DB"`
+	Prefix   string `json:"prefix" env:"PREFIX"`
+}
+
+type ListCache struct {
+	Enable bool   `json:"enable" env:"ENABLE"`
+	Path   string `json:"path" env:"PATH"`
+	// MaxSize of the cache file in MB
+	MaxSize int `json:"max_size" env:"MAX_SIZE"`
+}
+
 type Scheme struct {
 	Address      string `json:"address" env:"ADDR"`
 	HttpPort     int    `json:"http_port" env:"HTTP_PORT"`
@@ -57,6 +76,9 @@ type TasksConfig struct {
 	Transfer TaskConfig `json:"transfer" envPrefix:"TRANSFER_"`
 	Upload   TaskConfig `json:"upload" envPrefix:"UPLOAD_"`
 	Copy     TaskConfig `json:"copy" envPrefix:"COPY_"`
+	Hash     TaskConfig `json:"hash" envPrefix:"HASH_"`
+	Photo    TaskConfig `json:"photo" envPrefix:"PHOTO_"`
+	Strm     TaskConfig `json:"strm" envPrefix:"STRM_"`
 }
 
 type Cors struct {
@@ -71,14 +93,23 @@ type S3 struct {
 	SSL    bool `json:"ssl" env:"SSL"`
 }
 
+type DLNA struct {
+	Enable       bool   `json:"enable" env:"ENABLE"`
+	Port         int    `json:"port" env:"PORT"`
+	FriendlyName string `json:"friendly_name" env:"FRIENDLY_NAME"`
+}
+
 type Config struct {
 	Force                 bool        `json:"force" env:"FORCE"`
 	SiteURL               string      `json:"site_url" env:"SITE_URL"`
 	Cdn                   string      `json:"cdn" env:"CDN"`
 	JwtSecret             string      `json:"jwt_secret" env:"JWT_SECRET"`
+	MasterKey             string      `json:"master_key" env:"MASTER_KEY"`
 	TokenExpiresIn        int         `json:"token_expires_in" env:"TOKEN_EXPIRES_IN"`
 	Database              Database    `json:"database" envPrefix:"DB_"`
 	Meilisearch           Meilisearch `json:"meilisearch" envPrefix:"MEILISEARCH_"`
+	Cache                 Cache       `json:"cache" envPrefix:"CACHE_"`
+	ListCache             ListCache   `json:"list_cache" envPrefix:"LIST_CACHE_"`
 	Scheme                Scheme      `json:"scheme"`
 	TempDir               string      `json:"temp_dir" env:"TEMP_DIR"`
 	BleveDir              string      `json:"bleve_dir" env:"BLEVE_DIR"`
@@ -90,6 +121,7 @@ type Config struct {
 	Tasks                 TasksConfig `json:"tasks" envPrefix:"TASKS_"`
 	Cors                  Cors        `json:"cors" envPrefix:"CORS_"`
 	S3                    S3          `json:"s3" envPrefix:"S3_"`
+	DLNA                  DLNA        `json:"dlna" envPrefix:"DLNA_"`
 }
 
 func DefaultConfig() *Config {
@@ -97,6 +129,7 @@ func DefaultConfig() *Config {
 	indexDir := filepath.Join(flags.DataDir, "bleve")
 	logPath := filepath.Join(flags.DataDir, "log/log.log")
 	dbPath := filepath.Join(flags.DataDir, "data.db")
+	listCachePath := filepath.Join(flags.DataDir, "list_cache.db")
 	return &Config{
 		Scheme: Scheme{
 			Address:    "0.0.0.0",
@@ -108,6 +141,7 @@ func DefaultConfig() *Config {
 			KeyFile:    "",
 		},
 		JwtSecret:      random.String(16),
+		MasterKey:      random.String(32),
 		TokenExpiresIn: 48,
 		TempDir:        tempDir,
 		Database: Database{
@@ -119,6 +153,15 @@ func DefaultConfig() *Config {
 		Meilisearch: Meilisearch{
 			Host: "http://localhost:7700",
 		},
+		Cache: Cache{
+			Type:    "memory",
+			Address: "localhost:6379",
+			Prefix:  "alist:",
+		},
+		ListCache: ListCache{
+			Path:    listCachePath,
+			MaxSize: 256,
+		},
 		BleveDir: indexDir,
 		Log: LogConfig{
 			Enable:     true,
@@ -148,6 +191,15 @@ func DefaultConfig() *Config {
 				MaxRetry: 2,
 				// TaskPersistant: true,
 			},
+			Hash: TaskConfig{
+				Workers: 3,
+			},
+			Photo: TaskConfig{
+				Workers: 1,
+			},
+			Strm: TaskConfig{
+				Workers: 1,
+			},
 		},
 		Cors: Cors{
 			AllowOrigins: []string{"*"},
@@ -159,5 +211,10 @@ func DefaultConfig() *Config {
 			Port:   5246,
 			SSL:    false,
 		},
+		DLNA: DLNA{
+			Enable:       false,
+			Port:         5247,
+			FriendlyName: "AList",
+		},
 	}
 }
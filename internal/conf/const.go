@@ -44,11 +44,24 @@ const (
 	IgnoreDirectLinkParams  = "ignore_direct_link_params"
 	WebauthnLoginEnabled    = "webauthn_login_enabled"
 
+	// backup
+	BackupPath     = "backup_path"
+	BackupInterval = "backup_interval"
+	BackupKeep     = "backup_keep"
+	BackupSecrets  = "backup_secrets"
+
+	// change journal
+	ChangeJournalRetention = "change_journal_retention"
+
+	// cache warmer
+	WarmConcurrency = "warm_concurrency"
+
 	// index
 	SearchIndex     = "search_index"
 	AutoUpdateIndex = "auto_update_index"
 	IgnorePaths     = "ignore_paths"
 	MaxIndexDepth   = "max_index_depth"
+	IndexPhotoExif  = "index_photo_exif"
 
 	// aria2
 	Aria2Uri    = "aria2_uri"
@@ -93,6 +106,17 @@ const (
 	S3AccessKeyId     = "s3_access_key_id"
 	S3SecretAccessKey = "s3_secret_access_key"
 
+	// music
+	MusicPaths        = "music_paths"
+	MusicScanInterval = "music_scan_interval"
+
+	// dlna
+	DLNAPaths = "dlna_paths"
+	DLNAUser  = "dlna_user"
+
+	// wopi
+	WopiClientURL = "wopi_client_url"
+
 	// qbittorrent
 	QbittorrentUrl      = "qbittorrent_url"
 	QbittorrentSeedtime = "qbittorrent_seedtime"
@@ -111,4 +135,6 @@ const (
 // ContextKey is the type of context keys.
 const (
 	NoTaskKey = "no_task"
+	// VerifyKey makes the copy and upload tasks verify the hashes after transfer
+	VerifyKey = "verify"
 )
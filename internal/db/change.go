@@ -0,0 +1,62 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateChange(c *model.Change) error {
+	return errors.WithStack(db.Create(c).Error)
+}
+
+// GetChanges returns the changes after the cursor in order, which are in the folder path or moved into it
+func GetChanges(path string, cursor uint, limit int) ([]model.Change, error) {
+	q := db.Model(&model.Change{}).Where("id > ?", cursor)
+	if path != "/" {
+		prefix := fmt.Sprintf("%s/%%", path)
+		q = q.Where(db.Where(fmt.Sprintf("%s = ? OR %s LIKE ?", columnName("path"), columnName("path")), path, prefix).
+			Or("dst_path = ? OR dst_path LIKE ?", path, prefix))
+	}
+	var changes []model.Change
+	if err := q.Order("id").Limit(limit).Find(&changes).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get changes")
+	}
+	return changes, nil
+}
+
+// GetChangeIDRange returns the first and the last ID in the journal, both are 0 if it's empty
+func GetChangeIDRange() (uint, uint, error) {
+	var r struct {
+		FirstID uint
+		LastID  uint
+	}
+	if err := db.Model(&model.Change{}).Select("COALESCE(MIN(id), 0) AS first_id, COALESCE(MAX(id), 0) AS last_id").
+		Scan(&r).Error; err != nil {
+		return 0, 0, errors.Wrapf(err, "failed get change id range")
+	}
+	return r.FirstID, r.LastID, nil
+}
+
+// GetLastChangeID returns the ID of the last change of the type, 0 if there is none
+func GetLastChangeID(typ string) (uint, error) {
+	var id uint
+	if err := db.Model(&model.Change{}).Select("COALESCE(MAX(id), 0)").Where("type = ?", typ).
+		Scan(&id).Error; err != nil {
+		return 0, errors.Wrapf(err, "failed get last change id of %s", typ)
+	}
+	return id, nil
+}
+
+// DeleteChangesBefore deletes the changes older than t, but the last one is always kept,
+// so the first ID tells whether a cursor is expired
+func DeleteChangesBefore(t time.Time) (int64, error) {
+	_, last, err := GetChangeIDRange()
+	if err != nil || last == 0 {
+		return 0, err
+	}
+	res := db.Where("created_at < ? AND id < ?", t, last).Delete(&model.Change{})
+	return res.RowsAffected, errors.WithStack(res.Error)
+}
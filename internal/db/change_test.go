@@ -0,0 +1,73 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestChangeJournal(t *testing.T) {
+	changes := []model.Change{
+		{Type: model.ChangeCreate, Path: "/sync/a.txt", CreatedAt: time.Now().Add(-2 * time.Hour)},
+		{Type: model.ChangeCreate, Path: "/other/b.txt", CreatedAt: time.Now().Add(-2 * time.Hour)},
+		{Type: model.ChangeMove, Path: "/other/b.txt", DstPath: "/sync/b.txt"},
+		{Type: model.ChangeDelete, Path: "/synced.txt"},
+		{Type: model.ChangeMove, Path: "/sync/a.txt", DstPath: "/other/a.txt"},
+	}
+	for i := range changes {
+		if err := db.CreateChange(&changes[i]); err != nil {
+			t.Fatalf("failed create change: %+v", err)
+		}
+	}
+	types := func(cursor uint, limit int) []string {
+		res, err := db.GetChanges("/sync", cursor, limit)
+		if err != nil {
+			t.Fatalf("failed get changes: %+v", err)
+		}
+		var types []string
+		for _, c := range res {
+			types = append(types, c.Type)
+		}
+		return types
+	}
+	if got := types(0, 10); len(got) != 3 || got[0] != model.ChangeCreate || got[1] != model.ChangeMove || got[2] != model.ChangeMove {
+		t.Errorf("unexpected changes in /sync: %v", got)
+	}
+	if got := types(changes[2].ID, 1); len(got) != 1 || got[0] != model.ChangeMove {
+		t.Errorf("unexpected changes after cursor: %v", got)
+	}
+
+	n, err := db.DeleteChangesBefore(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("failed delete changes: %+v", err)
+	}
+	if n != 2 {
+		t.Errorf("expect 2 changes deleted, got %d", n)
+	}
+	first, last, err := db.GetChangeIDRange()
+	if err != nil {
+		t.Fatalf("failed get change id range: %+v", err)
+	}
+	if first != changes[2].ID || last != changes[4].ID {
+		t.Errorf("unexpected id range: %d-%d", first, last)
+	}
+	// the last change is always kept
+	if _, err = db.DeleteChangesBefore(time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("failed delete changes: %+v", err)
+	}
+	if first, last, _ = db.GetChangeIDRange(); first != last || last != changes[4].ID {
+		t.Errorf("expect only the last change kept, got %d-%d", first, last)
+	}
+	if id, err := db.GetLastChangeID(model.ChangeDisable); err != nil || id != 0 {
+		t.Errorf("expect no disable mark, got %d, %+v", id, err)
+	}
+	mark := model.Change{Type: model.ChangeDisable}
+	if err = db.CreateChange(&mark); err != nil {
+		t.Fatalf("failed create change: %+v", err)
+	}
+	if id, err := db.GetLastChangeID(model.ChangeDisable); err != nil || id != mark.ID {
+		t.Errorf("expect the disable mark %d, got %d, %+v", mark.ID, id, err)
+	}
+}
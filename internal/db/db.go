@@ -10,9 +10,12 @@ import (
 
 var db *gorm.DB
 
+// models are all tables in database
+var models = []interface{}{new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.TaskItem), new(model.IndexJob), new(model.WarmJob), new(model.MusicTrack), new(model.PhotoMeta), new(model.Tag), new(model.Change)}
+
 func Init(d *gorm.DB) {
 	db = d
-	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.TaskItem))
+	err := AutoMigrate(models...)
 	if err != nil {
 		log.Fatalf("failed migrate database: %s", err.Error())
 	}
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetIndexJobById(id uint) (*model.IndexJob, error) {
+	var j model.IndexJob
+	if err := db.First(&j, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get index job")
+	}
+	return &j, nil
+}
+
+func GetIndexJobs() ([]model.IndexJob, error) {
+	var jobs []model.IndexJob
+	if err := db.Order(columnName("id")).Find(&jobs).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get index jobs")
+	}
+	return jobs, nil
+}
+
+func CreateIndexJob(j *model.IndexJob) error {
+	return errors.WithStack(db.Create(j).Error)
+}
+
+// UpdateIndexJob updates the config of the job, progress is left untouched
+func UpdateIndexJob(j *model.IndexJob) error {
+	return errors.WithStack(db.Model(j).Select("path", "interval", "max_depth", "ignore_paths", "refresh", "disabled").Updates(j).Error)
+}
+
+func UpdateIndexJobProgress(id uint, progress *model.IndexProgress) error {
+	return errors.WithStack(db.Model(&model.IndexJob{ID: id}).
+		Select("progress_obj_count", "progress_is_done", "progress_last_done_time", "progress_error").
+		Updates(&model.IndexJob{Progress: *progress}).Error)
+}
+
+func DeleteIndexJobById(id uint) error {
+	return errors.WithStack(db.Delete(&model.IndexJob{}, id).Error)
+}
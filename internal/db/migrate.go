@@ -0,0 +1,128 @@
+package db
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+type MigrateArgs struct {
+	// Type of dst database, sqlite3, mysql or postgres
+	Type      string
+	BatchSize int
+	// Force to overwrite the tables which are not empty in dst
+	Force bool
+}
+
+// MigrateTo copies every table of the current database to dst,
+// dst should use the same table prefix
+func MigrateTo(dst *gorm.DB, args MigrateArgs) error {
+	if args.BatchSize <= 0 {
+		args.BatchSize = 1000
+	}
+	if args.Type == "mysql" {
+		dst = dst.Set("gorm:table_options", "ENGINE=InnoDB CHARSET=utf8mb4")
+	}
+	if err := dst.AutoMigrate(models...); err != nil {
+		return errors.Wrap(err, "failed migrate dst database")
+	}
+	var nonEmpty []string
+	for _, m := range models {
+		var count int64
+		if err := dst.Model(m).Count(&count).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		if count > 0 {
+			nonEmpty = append(nonEmpty, fmt.Sprintf("%s(%d)", tableName(dst, m), count))
+		}
+	}
+	if len(nonEmpty) > 0 && !args.Force {
+		return errors.Errorf("dst database is not empty: %s", strings.Join(nonEmpty, ", "))
+	}
+	for _, m := range models {
+		if err := migrateTable(dst, m, args); err != nil {
+			return errors.WithMessagef(err, "failed migrate table %s", tableName(db, m))
+		}
+	}
+	return nil
+}
+
+func parseSchema(d *gorm.DB, m interface{}) (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: d}
+	if err := stmt.Parse(m); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return stmt.Schema, nil
+}
+
+func tableName(d *gorm.DB, m interface{}) string {
+	s, err := parseSchema(d, m)
+	if err != nil {
+		return reflect.TypeOf(m).Elem().Name()
+	}
+	return s.Table
+}
+
+func migrateTable(dst *gorm.DB, m interface{}, args MigrateArgs) error {
+	s, err := parseSchema(db, m)
+	if err != nil {
+		return err
+	}
+	if args.Force {
+		if err := dst.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(m).Error; err != nil {
+			return errors.Wrap(err, "failed clear dst table")
+		}
+	}
+	// tables without primary key are ordered by all columns, so the batches are stable
+	columns := s.DBNames
+	if s.PrioritizedPrimaryField != nil {
+		columns = []string{s.PrioritizedPrimaryField.DBName}
+	}
+	var order clause.OrderBy
+	for _, column := range columns {
+		order.Columns = append(order.Columns, clause.OrderByColumn{Column: clause.Column{Name: column}})
+	}
+	var total int64
+	if err := db.Model(m).Count(&total).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	sliceType := reflect.SliceOf(reflect.TypeOf(m).Elem())
+	for offset := 0; int64(offset) < total; offset += args.BatchSize {
+		batch := reflect.New(sliceType)
+		if err := db.Model(m).Order(order).
+			Offset(offset).Limit(args.BatchSize).Find(batch.Interface()).Error; err != nil {
+			return errors.Wrap(err, "failed read batch")
+		}
+		if batch.Elem().Len() == 0 {
+			break
+		}
+		// select all columns, so zero values are not replaced by defaults
+		if err := dst.Select("*").Create(batch.Interface()).Error; err != nil {
+			return errors.Wrap(err, "failed write batch")
+		}
+	}
+	var count int64
+	if err := dst.Model(m).Count(&count).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	if count != total {
+		return errors.Errorf("expect %d rows, but got %d", total, count)
+	}
+	if args.Type == "postgres" && s.PrioritizedPrimaryField != nil && s.PrioritizedPrimaryField.AutoIncrement {
+		// ids are copied, so the sequence must be moved after them
+		pk := s.PrioritizedPrimaryField.DBName
+		err = dst.Exec(fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%s', '%s'), (SELECT COALESCE(MAX(%s), 0) + 1 FROM %s), false)",
+			s.Table, pk, pk, s.Table)).Error
+		if err != nil {
+			return errors.Wrap(err, "failed reset sequence")
+		}
+	}
+	log.Infof("migrated table %s: %d rows", s.Table, total)
+	return nil
+}
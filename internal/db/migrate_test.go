@@ -0,0 +1,44 @@
+package db_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMigrateTo(t *testing.T) {
+	for i := 0; i < 5; i++ {
+		meta := &model.Meta{Path: fmt.Sprintf("/migrate/%d", i), Password: "pwd"}
+		if err := db.CreateMeta(meta); err != nil {
+			t.Fatalf("failed to create meta: %+v", err)
+		}
+		defer db.DeleteMetaById(meta.ID)
+	}
+	dst, err := gorm.Open(sqlite.Open("file:migrate_dst?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open dst: %+v", err)
+	}
+	if err = db.MigrateTo(dst, db.MigrateArgs{Type: "sqlite3", BatchSize: 2}); err != nil {
+		t.Fatalf("failed to migrate: %+v", err)
+	}
+	var metas []model.Meta
+	if err = dst.Order("id").Find(&metas).Error; err != nil {
+		t.Fatalf("failed to find metas: %+v", err)
+	}
+	if len(metas) != 5 {
+		t.Fatalf("expect 5 metas, got %d", len(metas))
+	}
+	if metas[4].Path != "/migrate/4" || metas[4].Password != "pwd" {
+		t.Errorf("unexpected meta: %+v", metas[4])
+	}
+	if err = db.MigrateTo(dst, db.MigrateArgs{Type: "sqlite3"}); err == nil {
+		t.Errorf("expect error when dst is not empty")
+	}
+	if err = db.MigrateTo(dst, db.MigrateArgs{Type: "sqlite3", Force: true}); err != nil {
+		t.Errorf("failed to migrate with force: %+v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// musicUnder selects the tracks in the folder path
+func musicUnder(path string) *gorm.DB {
+	q := db.Model(&model.MusicTrack{})
+	if path == "/" {
+		return q
+	}
+	return q.Where(fmt.Sprintf("%s LIKE ?", columnName("path")), fmt.Sprintf("%s/%%", path))
+}
+
+func GetMusicTrackByPath(path string) (*model.MusicTrack, error) {
+	var t model.MusicTrack
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("path")), path).First(&t).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get music track")
+	}
+	return &t, nil
+}
+
+func SaveMusicTrack(t *model.MusicTrack) error {
+	return errors.WithStack(db.Save(t).Error)
+}
+
+// TouchMusicTrack marks the unchanged track as scanned
+func TouchMusicTrack(id uint, scannedAt time.Time) error {
+	return errors.WithStack(db.Model(&model.MusicTrack{ID: id}).Update("scanned_at", scannedAt).Error)
+}
+
+// DeleteMusicTracksScannedBefore deletes the tracks in path which are not found by the scan started at t
+func DeleteMusicTracksScannedBefore(path string, t time.Time) error {
+	return errors.WithStack(musicUnder(path).Where("scanned_at < ?", t).Delete(&model.MusicTrack{}).Error)
+}
+
+func GetMusicArtists(path string, pageIndex, pageSize int) ([]model.MusicArtist, int64, error) {
+	var count int64
+	if err := musicUnder(path).Distinct("album_artist").Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed count music artists")
+	}
+	var artists []model.MusicArtist
+	if err := musicUnder(path).
+		Select("album_artist AS name, COUNT(DISTINCT album) AS albums, COUNT(*) AS tracks").
+		Group("album_artist").Order("album_artist").
+		Offset((pageIndex - 1) * pageSize).Limit(pageSize).
+		Scan(&artists).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get music artists")
+	}
+	return artists, count, nil
+}
+
+// GetMusicAlbums gets the albums in path, of all artists if artist is empty
+func GetMusicAlbums(path, artist string, pageIndex, pageSize int) ([]model.MusicAlbum, int64, error) {
+	albumsDB := func() *gorm.DB {
+		q := musicUnder(path)
+		if artist != "" {
+			q = q.Where("album_artist = ?", artist)
+		}
+		return q
+	}
+	var count int64
+	if err := db.Table("(?) AS a", albumsDB().Select("album, album_artist").Group("album, album_artist")).
+		Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed count music albums")
+	}
+	var albums []model.MusicAlbum
+	if err := albumsDB().
+		Select(fmt.Sprintf("album AS name, album_artist AS artist, MAX(year) AS year, COUNT(*) AS tracks, "+
+			"MAX(CASE WHEN cover <> '' THEN %s ELSE '' END) AS cover_path", columnName("path"))).
+		Group("album, album_artist").Order("album_artist, album").
+		Offset((pageIndex - 1) * pageSize).Limit(pageSize).
+		Scan(&albums).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get music albums")
+	}
+	return albums, count, nil
+}
+
+// GetMusicTrackPaths gets the paths of the tracks in path, the empty artist or album matches all
+func GetMusicTrackPaths(path, artist, album string) ([]string, error) {
+	q := musicUnder(path)
+	if artist != "" {
+		q = q.Where("album_artist = ?", artist)
+	}
+	if album != "" {
+		q = q.Where("album = ?", album)
+	}
+	var paths []string
+	if err := q.Pluck(columnName("path"), &paths).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get music track paths")
+	}
+	return paths, nil
+}
+
+// GetMusicTracks gets the tracks in path, the empty artist or album matches all
+func GetMusicTracks(path, artist, album string, pageIndex, pageSize int) ([]model.MusicTrack, int64, error) {
+	q := musicUnder(path)
+	if artist != "" {
+		q = q.Where("album_artist = ?", artist)
+	}
+	if album != "" {
+		q = q.Where("album = ?", album)
+	}
+	var count int64
+	if err := q.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed count music tracks")
+	}
+	var tracks []model.MusicTrack
+	if err := q.Order(fmt.Sprintf("album_artist, album, disc, track, %s", columnName("path"))).
+		Offset((pageIndex - 1) * pageSize).Limit(pageSize).
+		Find(&tracks).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get music tracks")
+	}
+	return tracks, count, nil
+}
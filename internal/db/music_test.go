@@ -0,0 +1,63 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestMusicLibrary(t *testing.T) {
+	old := time.Now().Add(-time.Hour)
+	now := time.Now()
+	tracks := []model.MusicTrack{
+		{Path: "/music/a/1.mp3", Title: "1", AlbumArtist: "A", Album: "X", Track: 2, Cover: "x.jpg", ScannedAt: now},
+		{Path: "/music/a/2.mp3", Title: "2", AlbumArtist: "A", Album: "X", Track: 1, ScannedAt: now},
+		{Path: "/music/a/3.mp3", Title: "3", AlbumArtist: "A", Album: "Y", ScannedAt: now},
+		{Path: "/music/b/4.mp3", Title: "4", AlbumArtist: "B", Album: "Z", ScannedAt: old},
+		{Path: "/other/5.mp3", Title: "5", AlbumArtist: "C", Album: "W", ScannedAt: old},
+	}
+	for i := range tracks {
+		if err := db.SaveMusicTrack(&tracks[i]); err != nil {
+			t.Fatalf("failed save track: %+v", err)
+		}
+	}
+	artists, total, err := db.GetMusicArtists("/music", 1, 10)
+	if err != nil {
+		t.Fatalf("failed get artists: %+v", err)
+	}
+	if total != 2 || len(artists) != 2 || artists[0] != (model.MusicArtist{Name: "A", Albums: 2, Tracks: 3}) {
+		t.Errorf("unexpected artists: %d %+v", total, artists)
+	}
+	albums, total, err := db.GetMusicAlbums("/music", "A", 1, 10)
+	if err != nil {
+		t.Fatalf("failed get albums: %+v", err)
+	}
+	if total != 2 || len(albums) != 2 || albums[0].Name != "X" || albums[0].Tracks != 2 || albums[0].CoverPath != "/music/a/1.mp3" {
+		t.Errorf("unexpected albums: %d %+v", total, albums)
+	}
+	paths, err := db.GetMusicTrackPaths("/music", "A", "Y")
+	if err != nil {
+		t.Fatalf("failed get track paths: %+v", err)
+	}
+	if len(paths) != 1 || paths[0] != "/music/a/3.mp3" {
+		t.Errorf("unexpected track paths: %v", paths)
+	}
+	got, total, err := db.GetMusicTracks("/music", "A", "X", 1, 10)
+	if err != nil {
+		t.Fatalf("failed get tracks: %+v", err)
+	}
+	if total != 2 || got[0].Title != "2" || got[1].Title != "1" {
+		t.Errorf("unexpected tracks: %d %+v", total, got)
+	}
+	if err = db.DeleteMusicTracksScannedBefore("/music", now.Add(-time.Minute)); err != nil {
+		t.Fatalf("failed delete tracks: %+v", err)
+	}
+	if _, err = db.GetMusicTrackByPath("/music/b/4.mp3"); err == nil {
+		t.Errorf("expect the track not scanned is deleted")
+	}
+	if _, err = db.GetMusicTrackByPath("/other/5.mp3"); err != nil {
+		t.Errorf("expect the track in other path is kept: %+v", err)
+	}
+}
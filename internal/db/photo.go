@@ -0,0 +1,98 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// photoUnder selects the photos in the folder path and its sub folders
+func photoUnder(path string) *gorm.DB {
+	q := db.Model(&model.PhotoMeta{})
+	if path == "/" {
+		return q
+	}
+	return q.Where(db.Where(fmt.Sprintf("%s LIKE ?", columnName("parent")), fmt.Sprintf("%s/%%", path)).
+		Or(fmt.Sprintf("%s = ?", columnName("parent")), path))
+}
+
+func GetPhotoMeta(parent, name string) (*model.PhotoMeta, error) {
+	var p model.PhotoMeta
+	if err := db.Where(fmt.Sprintf("%s = ? AND %s = ?", columnName("parent"), columnName("name")), parent, name).
+		First(&p).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get photo meta")
+	}
+	return &p, nil
+}
+
+func SavePhotoMeta(p *model.PhotoMeta) error {
+	return errors.WithStack(db.Save(p).Error)
+}
+
+// TouchPhotoMeta marks the unchanged photo as scanned
+func TouchPhotoMeta(id uint, scannedAt time.Time) error {
+	return errors.WithStack(db.Model(&model.PhotoMeta{ID: id}).Update("scanned_at", scannedAt).Error)
+}
+
+// DeletePhotoMetasScannedBefore deletes the photos in path which are not found by the scan started at t
+func DeletePhotoMetasScannedBefore(path string, t time.Time) error {
+	return errors.WithStack(photoUnder(path).Where("scanned_at < ?", t).Delete(&model.PhotoMeta{}).Error)
+}
+
+// filterPhotos applies the filters of the request except the parent
+func filterPhotos(q *gorm.DB, req model.PhotoReq) *gorm.DB {
+	if req.TakenAfter != nil {
+		q = q.Where("taken_at >= ?", *req.TakenAfter)
+	}
+	if req.TakenBefore != nil {
+		q = q.Where("taken_at <= ?", *req.TakenBefore)
+	}
+	if req.Camera != "" {
+		camera := fmt.Sprintf("%%%s%%", req.Camera)
+		q = q.Where(db.Where(fmt.Sprintf("%s LIKE ?", columnName("make")), camera).
+			Or(fmt.Sprintf("%s LIKE ?", columnName("model")), camera))
+	}
+	if req.HasBox() {
+		q = q.Where("latitude BETWEEN ? AND ?", *req.MinLatitude, *req.MaxLatitude)
+		// the box crosses the 180th meridian if min_longitude > max_longitude
+		if *req.MinLongitude <= *req.MaxLongitude {
+			q = q.Where("longitude BETWEEN ? AND ?", *req.MinLongitude, *req.MaxLongitude)
+		} else {
+			q = q.Where("longitude >= ? OR longitude <= ?", *req.MinLongitude, *req.MaxLongitude)
+		}
+	}
+	return q
+}
+
+func GetPhotoMetas(req model.PhotoReq) ([]model.PhotoMeta, int64, error) {
+	var count int64
+	if err := filterPhotos(photoUnder(req.Parent), req).Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed count photos")
+	}
+	order := "taken_at DESC"
+	if req.OrderDirection == "asc" {
+		order = "taken_at ASC"
+	}
+	var photos []model.PhotoMeta
+	if err := filterPhotos(photoUnder(req.Parent), req).Order(order).Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).
+		Find(&photos).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get photos")
+	}
+	return photos, count, nil
+}
+
+// GetCameraPhotos gets the photos in path taken by a known camera, only the path and the camera are selected,
+// so they can be filtered by access before counting the cameras
+func GetCameraPhotos(path string) ([]model.PhotoMeta, error) {
+	var photos []model.PhotoMeta
+	if err := photoUnder(path).
+		Select(fmt.Sprintf("%s, %s, %s, %s", columnName("parent"), columnName("name"), columnName("make"), columnName("model"))).
+		Where(fmt.Sprintf("%s <> ''", columnName("model"))).
+		Find(&photos).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get camera photos")
+	}
+	return photos, nil
+}
@@ -0,0 +1,79 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestPhotoTimeline(t *testing.T) {
+	lat := func(v float64) *float64 { return &v }
+	day := func(d int) time.Time { return time.Date(2023, 1, d, 0, 0, 0, 0, time.UTC) }
+	old := time.Now().Add(-time.Hour)
+	now := time.Now()
+	photos := []model.PhotoMeta{
+		{Parent: "/photos", Name: "1.jpg", TakenAt: day(1), Make: "Canon", Model: "EOS R5", Latitude: lat(10), Longitude: lat(20), ScannedAt: now},
+		{Parent: "/photos/trip", Name: "2.jpg", TakenAt: day(2), Make: "Apple", Model: "iPhone 14", Latitude: lat(40), Longitude: lat(179), ScannedAt: now},
+		{Parent: "/photos/trip", Name: "3.jpg", TakenAt: day(3), Make: "Apple", Model: "iPhone 14", ScannedAt: old},
+		{Parent: "/photos2", Name: "4.jpg", TakenAt: day(4), Make: "Canon", Model: "EOS R5", ScannedAt: old},
+	}
+	for i := range photos {
+		if err := db.SavePhotoMeta(&photos[i]); err != nil {
+			t.Fatalf("failed save photo: %+v", err)
+		}
+	}
+	names := func(req model.PhotoReq) []string {
+		req.PageReq = model.PageReq{Page: 1, PerPage: 10}
+		res, total, err := db.GetPhotoMetas(req)
+		if err != nil {
+			t.Fatalf("failed get photos: %+v", err)
+		}
+		var names []string
+		for _, p := range res {
+			names = append(names, p.Name)
+		}
+		if int(total) != len(names) {
+			t.Errorf("expect total %d, got %d", len(names), total)
+		}
+		return names
+	}
+	equal := func(name string, got []string, want ...string) {
+		if len(got) != len(want) {
+			t.Errorf("%s: expect %v, got %v", name, want, got)
+			return
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("%s: expect %v, got %v", name, want, got)
+				return
+			}
+		}
+	}
+	after, before := day(2), day(3)
+	equal("parent", names(model.PhotoReq{Parent: "/photos"}), "3.jpg", "2.jpg", "1.jpg")
+	equal("asc", names(model.PhotoReq{Parent: "/", OrderDirection: "asc"}), "1.jpg", "2.jpg", "3.jpg", "4.jpg")
+	equal("date", names(model.PhotoReq{Parent: "/", TakenAfter: &after, TakenBefore: &before}), "3.jpg", "2.jpg")
+	equal("camera", names(model.PhotoReq{Parent: "/photos", Camera: "canon"}), "1.jpg")
+	equal("box", names(model.PhotoReq{Parent: "/",
+		MinLatitude: lat(0), MaxLatitude: lat(50), MinLongitude: lat(0), MaxLongitude: lat(30)}), "1.jpg")
+	equal("box across 180", names(model.PhotoReq{Parent: "/",
+		MinLatitude: lat(0), MaxLatitude: lat(50), MinLongitude: lat(170), MaxLongitude: lat(-170)}), "2.jpg")
+
+	cameraPhotos, err := db.GetCameraPhotos("/photos")
+	if err != nil {
+		t.Fatalf("failed get camera photos: %+v", err)
+	}
+	if len(cameraPhotos) != 3 || cameraPhotos[0].Name == "" || cameraPhotos[0].Model == "" {
+		t.Errorf("unexpected camera photos: %+v", cameraPhotos)
+	}
+
+	if err := db.DeletePhotoMetasScannedBefore("/photos", now); err != nil {
+		t.Fatalf("failed delete photos: %+v", err)
+	}
+	equal("deleted", names(model.PhotoReq{Parent: "/"}), "4.jpg", "2.jpg", "1.jpg")
+	if _, err := db.GetPhotoMeta("/photos/trip", "2.jpg"); err != nil {
+		t.Errorf("failed get photo: %+v", err)
+	}
+}
@@ -36,9 +36,10 @@ func DeleteSearchNodesByParent(path string) error {
 		return err
 	}
 	dir, name := stdpath.Split(path)
+	// the parent is stored without the trailing slash
 	return db.Where(fmt.Sprintf("%s = ? AND %s = ?",
 		columnName("parent"), columnName("name")),
-		dir, name).Delete(&model.SearchNode{}).Error
+		utils.FixAndCleanPath(dir), name).Delete(&model.SearchNode{}).Error
 }
 
 func ClearSearchNodes() error {
@@ -77,15 +78,68 @@ func SearchNode(req model.SearchReq, useFullText bool) ([]model.SearchNode, int6
 		isDir := req.Scope == 1
 		searchDB.Where(db.Where("is_dir = ?", isDir))
 	}
+	searchDB = whereSearchFilters(searchDB, req)
 
 	var count int64
 	if err := searchDB.Count(&count).Error; err != nil {
 		return nil, 0, errors.Wrapf(err, "failed get search items count")
 	}
 	var files []model.SearchNode
-	if err := searchDB.Order("name asc").Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).
+	if err := searchDB.Order(searchOrder(req)).Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).
 		Find(&files).Error; err != nil {
 		return nil, 0, err
 	}
 	return files, count, nil
 }
+
+func whereSearchFilters(searchDB *gorm.DB, req model.SearchReq) *gorm.DB {
+	if req.MinSize > 0 {
+		searchDB = searchDB.Where(fmt.Sprintf("%s >= ?", columnName("size")), req.MinSize)
+	}
+	if req.MaxSize > 0 {
+		searchDB = searchDB.Where(fmt.Sprintf("%s <= ?", columnName("size")), req.MaxSize)
+	}
+	if req.ModifiedAfter != nil {
+		searchDB = searchDB.Where(fmt.Sprintf("%s >= ?", columnName("modified")), *req.ModifiedAfter)
+	}
+	if req.ModifiedBefore != nil {
+		searchDB = searchDB.Where(fmt.Sprintf("%s <= ?", columnName("modified")), *req.ModifiedBefore)
+	}
+	if len(req.FileTypes) > 0 {
+		searchDB = searchDB.Where(fmt.Sprintf("%s IN ?", columnName("file_type")), req.FileTypes)
+	}
+	if len(req.Exts) > 0 {
+		searchDB = searchDB.Where(fmt.Sprintf("%s IN ?", columnName("ext")), req.Exts)
+	}
+	if len(req.TaggedPaths) > 0 {
+		// grouped by the parent, so the tagged objects in a folder are one term
+		var parents []string
+		names := make(map[string][]string)
+		for _, p := range req.TaggedPaths {
+			parent, name := stdpath.Split(p)
+			parent = utils.FixAndCleanPath(parent)
+			if _, ok := names[parent]; !ok {
+				parents = append(parents, parent)
+			}
+			names[parent] = append(names[parent], name)
+		}
+		taggedClause := db.Where("1 = 0")
+		for _, parent := range parents {
+			taggedClause = taggedClause.Or(fmt.Sprintf("%s = ? AND %s IN ?", columnName("parent"), columnName("name")),
+				parent, names[parent])
+		}
+		searchDB = searchDB.Where(taggedClause)
+	}
+	return searchDB
+}
+
+func searchOrder(req model.SearchReq) string {
+	orderBy, orderDirection := "name", "asc"
+	if req.OrderBy != "" {
+		orderBy = req.OrderBy
+	}
+	if req.OrderDirection != "" {
+		orderDirection = req.OrderDirection
+	}
+	return fmt.Sprintf("%s %s", columnName(orderBy), orderDirection)
+}
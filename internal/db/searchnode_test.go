@@ -0,0 +1,68 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+func TestSearchNodeFilters(t *testing.T) {
+	now := time.Now()
+	nodes := []model.SearchNode{
+		{Parent: "/a", Name: "movie.mp4", Size: 300, Modified: now, FileType: conf.VIDEO, Ext: "mp4"},
+		{Parent: "/a", Name: "movie.txt", Size: 10, Modified: now.Add(-48 * time.Hour), FileType: conf.TEXT, Ext: "txt"},
+		{Parent: "/a/b", Name: "movie.mkv", Size: 200, Modified: now.Add(-time.Hour), FileType: conf.VIDEO, Ext: "mkv"},
+		{Parent: "/a", Name: "movies", IsDir: true, Modified: now, FileType: conf.FOLDER},
+	}
+	if err := db.BatchCreateSearchNodes(&nodes); err != nil {
+		t.Fatalf("failed to create search nodes: %+v", err)
+	}
+	defer db.ClearSearchNodes()
+	dayAgo := now.Add(-24 * time.Hour)
+	var tests = []struct {
+		name  string
+		req   model.SearchReq
+		names []string
+	}{
+		{name: "types", req: model.SearchReq{FileTypes: []int{conf.VIDEO}}, names: []string{"movie.mkv", "movie.mp4"}},
+		{name: "exts", req: model.SearchReq{Exts: []string{"txt", "mkv"}}, names: []string{"movie.mkv", "movie.txt"}},
+		{name: "size", req: model.SearchReq{MinSize: 100, MaxSize: 250}, names: []string{"movie.mkv"}},
+		{name: "modified", req: model.SearchReq{ModifiedAfter: &dayAgo, Scope: 2}, names: []string{"movie.mkv", "movie.mp4"}},
+		{name: "tagged", req: model.SearchReq{Scope: 2, TaggedPaths: []string{"/a/movie.mp4", "/a/movie.txt", "/a/b/movie.mkv", "/a/b/none"}},
+			names: []string{"movie.mkv", "movie.mp4", "movie.txt"}},
+		{name: "order", req: model.SearchReq{Scope: 2, OrderBy: "size", OrderDirection: "desc"}, names: []string{"movie.mp4", "movie.mkv", "movie.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Parent = "/a"
+			tt.req.Keywords = "movie"
+			tt.req.Page, tt.req.PerPage = 1, 10
+			res, total, err := db.SearchNode(tt.req, false)
+			if err != nil {
+				t.Fatalf("failed to search: %+v", err)
+			}
+			if int(total) != len(tt.names) || len(res) != len(tt.names) {
+				t.Fatalf("expect %d nodes, got %d (total %d)", len(tt.names), len(res), total)
+			}
+			for i := range res {
+				if res[i].Name != tt.names[i] {
+					t.Errorf("expect %s at %d, got %s", tt.names[i], i, res[i].Name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// visibleTags selects the tags owned by the user and the shared ones
+func visibleTags(userID uint) *gorm.DB {
+	return db.Model(&model.Tag{}).Where("user_id IN ?", []uint{0, userID})
+}
+
+// tagsUnder selects the tags of the path and the objects in it
+func tagsUnder(tx *gorm.DB, path string) *gorm.DB {
+	q := tx.Model(&model.Tag{})
+	if path == "/" {
+		return q
+	}
+	return q.Where(db.Where(fmt.Sprintf("%s = ?", columnName("path")), path).
+		Or(fmt.Sprintf("%s LIKE ?", columnName("path")), fmt.Sprintf("%s/%%", path)))
+}
+
+// CreateTag creates the tag if the path doesn't have it yet
+func CreateTag(t *model.Tag) error {
+	return errors.WithStack(db.Where(fmt.Sprintf("%s = ? AND %s = ? AND user_id = ?", columnName("path"), columnName("name")),
+		t.Path, t.Name, t.UserID).FirstOrCreate(t).Error)
+}
+
+func DeleteTag(path, name string, userID uint) error {
+	return errors.WithStack(db.Where(fmt.Sprintf("%s = ? AND %s = ? AND user_id = ?", columnName("path"), columnName("name")),
+		path, name, userID).Delete(&model.Tag{}).Error)
+}
+
+// GetTagsByPath returns the tags of the path visible to the user
+func GetTagsByPath(path string, userID uint) ([]model.Tag, error) {
+	var tags []model.Tag
+	if err := visibleTags(userID).Where(fmt.Sprintf("%s = ?", columnName("path")), path).
+		Order(columnName("name")).Find(&tags).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get tags of path")
+	}
+	return tags, nil
+}
+
+// GetTagsByName returns the tags with the name visible to the user in the folder parent and its sub folders,
+// they are not paged as the ones the user can't access are filtered out before
+func GetTagsByName(name string, userID uint, parent string) ([]model.Tag, error) {
+	var tags []model.Tag
+	if err := tagsUnder(visibleTags(userID), parent).Where(fmt.Sprintf("%s = ?", columnName("name")), name).
+		Order(columnName("path")).Find(&tags).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get tags")
+	}
+	return tags, nil
+}
+
+// GetTaggedPaths returns all the distinct paths with the tag visible to the user in the folder parent
+func GetTaggedPaths(name string, userID uint, parent string) ([]string, error) {
+	var paths []string
+	if err := tagsUnder(visibleTags(userID), parent).Where(fmt.Sprintf("%s = ?", columnName("name")), name).
+		Distinct(columnName("path")).Pluck(columnName("path"), &paths).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get tagged paths")
+	}
+	return paths, nil
+}
+
+// GetTagsUnder returns all the tags visible to the user in the folder parent and its sub folders
+func GetTagsUnder(userID uint, parent string) ([]model.Tag, error) {
+	var tags []model.Tag
+	if err := tagsUnder(visibleTags(userID), parent).Order(columnName("name")).Find(&tags).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get tags")
+	}
+	return tags, nil
+}
+
+// MoveTags makes the tags of srcPath and the objects in it follow the object moved to dstPath,
+// the tags left at dstPath belong to the replaced object so they are dropped
+func MoveTags(srcPath, dstPath string) error {
+	if srcPath == dstPath || srcPath == "/" {
+		return nil
+	}
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		var tags []model.Tag
+		if err := tagsUnder(tx, srcPath).Find(&tags).Error; err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+		if err := deleteTagsUnder(tx, dstPath); err != nil {
+			return err
+		}
+		for _, t := range tags {
+			// LIKE may match more than the objects in srcPath if it contains wildcards
+			if t.Path != srcPath && !strings.HasPrefix(t.Path, srcPath+"/") {
+				continue
+			}
+			path := dstPath + strings.TrimPrefix(t.Path, srcPath)
+			if err := tx.Model(&model.Tag{ID: t.ID}).Update("path", path).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+func deleteTagsUnder(tx *gorm.DB, path string) error {
+	var tags []model.Tag
+	if err := tagsUnder(tx, path).Find(&tags).Error; err != nil {
+		return err
+	}
+	var ids []uint
+	for _, t := range tags {
+		if t.Path == path || strings.HasPrefix(t.Path, path+"/") {
+			ids = append(ids, t.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return tx.Delete(&model.Tag{}, ids).Error
+}
+
+// DeleteTagsUnder deletes the tags of the removed object and the objects in it
+func DeleteTagsUnder(path string) error {
+	return errors.WithStack(deleteTagsUnder(db, path))
+}
+
+func DeleteTagsByUser(userID uint) error {
+	return errors.WithStack(db.Where("user_id = ?", userID).Delete(&model.Tag{}).Error)
+}
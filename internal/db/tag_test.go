@@ -0,0 +1,88 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestTags(t *testing.T) {
+	tags := []model.Tag{
+		{Path: "/docs/a.pdf", Name: "work", UserID: 1},
+		{Path: "/docs/sub", Name: "work", UserID: 1},
+		{Path: "/docs/sub/b.pdf", Name: "work", UserID: 0},
+		{Path: "/docs/sub/b.pdf", Name: model.FavoriteTag, UserID: 1},
+		{Path: "/docs/subway.pdf", Name: "work", UserID: 2},
+		{Path: "/docs/a.pdf", Name: "work", UserID: 1},
+	}
+	for i := range tags {
+		if err := db.CreateTag(&tags[i]); err != nil {
+			t.Fatalf("failed create tag: %+v", err)
+		}
+	}
+	if tags[0].ID != tags[5].ID {
+		t.Errorf("expect the duplicated tag not to be created")
+	}
+	paths := func(name string, userID uint) []string {
+		res, err := db.GetTagsByName(name, userID, "/docs")
+		if err != nil {
+			t.Fatalf("failed get tags: %+v", err)
+		}
+		var paths []string
+		for _, tag := range res {
+			paths = append(paths, tag.Path)
+		}
+		return paths
+	}
+	if got := paths("work", 1); len(got) != 3 || got[0] != "/docs/a.pdf" || got[2] != "/docs/sub/b.pdf" {
+		t.Errorf("unexpected tagged paths of user 1: %v", got)
+	}
+	if got := paths("work", 2); len(got) != 2 || got[1] != "/docs/subway.pdf" {
+		t.Errorf("unexpected tagged paths of user 2: %v", got)
+	}
+	all, err := db.GetTagsUnder(1, "/docs/sub")
+	if err != nil {
+		t.Fatalf("failed get tags under: %+v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("expect 3 tags of user 1 under /docs/sub, got %+v", all)
+	}
+
+	if err := db.MoveTags("/docs/sub", "/archive/sub2"); err != nil {
+		t.Fatalf("failed move tags: %+v", err)
+	}
+	if got := paths("work", 2); len(got) != 1 || got[0] != "/docs/subway.pdf" {
+		t.Errorf("expect the tags of the sibling not to be moved: %v", got)
+	}
+	moved, err := db.GetTagsByPath("/archive/sub2/b.pdf", 1)
+	if err != nil {
+		t.Fatalf("failed get tags: %+v", err)
+	}
+	if len(moved) != 2 || moved[0].Name != model.FavoriteTag || moved[1].Name != "work" {
+		t.Errorf("expect the tags to follow the moved object: %+v", moved)
+	}
+	taggedPaths, err := db.GetTaggedPaths("work", 1, "/archive")
+	if err != nil {
+		t.Fatalf("failed get tagged paths: %+v", err)
+	}
+	if len(taggedPaths) != 2 {
+		t.Errorf("unexpected tagged paths: %v", taggedPaths)
+	}
+
+	if err := db.DeleteTagsUnder("/archive"); err != nil {
+		t.Fatalf("failed delete tags: %+v", err)
+	}
+	if err := db.DeleteTag("/docs/a.pdf", "work", 1); err != nil {
+		t.Fatalf("failed delete tag: %+v", err)
+	}
+	if got := paths("work", 1); len(got) != 0 {
+		t.Errorf("expect no tags left for user 1: %v", got)
+	}
+	if err := db.DeleteTagsByUser(2); err != nil {
+		t.Fatalf("failed delete tags of user: %+v", err)
+	}
+	if got := paths("work", 2); len(got) != 0 {
+		t.Errorf("expect no tags left for user 2: %v", got)
+	}
+}
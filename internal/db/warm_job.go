@@ -0,0 +1,34 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetWarmJobById(id uint) (*model.WarmJob, error) {
+	var j model.WarmJob
+	if err := db.First(&j, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get warm job")
+	}
+	return &j, nil
+}
+
+func GetWarmJobs() ([]model.WarmJob, error) {
+	var jobs []model.WarmJob
+	if err := db.Order(columnName("id")).Find(&jobs).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get warm jobs")
+	}
+	return jobs, nil
+}
+
+func CreateWarmJob(j *model.WarmJob) error {
+	return errors.WithStack(db.Create(j).Error)
+}
+
+func UpdateWarmJob(j *model.WarmJob) error {
+	return errors.WithStack(db.Model(j).Select("path", "depth", "interval", "disabled").Updates(j).Error)
+}
+
+func DeleteWarmJobById(id uint) error {
+	return errors.WithStack(db.Delete(&model.WarmJob{}, id).Error)
+}
@@ -38,10 +38,31 @@ type Reader interface {
 	Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error)
 }
 
+type ListPager interface {
+	// ListPage list at most args.Limit files after args.Token in the path,
+	// the returned token is used to get the next page and is empty after the last page
+	ListPage(ctx context.Context, dir model.Obj, args model.ListPageArgs) ([]model.Obj, string, error)
+}
+
+type ListR interface {
+	// ListR list all descendants of dir recursively, callback is called with the path of
+	// a folder relative to dir ("" for dir itself) and some of the objs in it,
+	// a folder must be passed to callback before any obj in it
+	ListR(ctx context.Context, dir model.Obj, args model.ListArgs, callback ListRCallback) error
+}
+
+// ListRCallback receives the objs found by ListR, the listing stops if it returns an error
+type ListRCallback func(parent string, objs []model.Obj) error
+
 type GetRooter interface {
 	GetRoot(ctx context.Context) (model.Obj, error)
 }
 
+type SpaceGetter interface {
+	// GetSpace get total and free space of the storage
+	GetSpace(ctx context.Context) (*model.StorageSpace, error)
+}
+
 type Getter interface {
 	// Get file by path, the path haven't been joined with root path
 	Get(ctx context.Context, path string) (model.Obj, error)
@@ -72,6 +93,16 @@ type Copy interface {
 	Copy(ctx context.Context, srcObj, dstDir model.Obj) error
 }
 
+type Peer interface {
+	// IsPeer reports whether dst is a storage of the same backend account,
+	// so that objs can be copied or moved to it on the server side
+	IsPeer(dst Driver) bool
+	// PeerCopy copies srcObj to dstDir of the peer storage dst
+	PeerCopy(ctx context.Context, srcObj model.Obj, dst Driver, dstDir model.Obj) error
+	// PeerMove moves srcObj to dstDir of the peer storage dst
+	PeerMove(ctx context.Context, srcObj model.Obj, dst Driver, dstDir model.Obj) error
+}
+
 type Remove interface {
 	Remove(ctx context.Context, obj model.Obj) error
 }
@@ -109,6 +140,19 @@ type PutResult interface {
 	Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up UpdateProgress) (model.Obj, error)
 }
 
+type DirectUpload interface {
+	// CreateUploadSession returns how the client uploads the file to dstDir without passing through alist
+	CreateUploadSession(ctx context.Context, dstDir model.Obj, args model.UploadSessionArgs) (*model.UploadSession, error)
+	// CompleteUploadSession is called after the client uploaded all parts, it returns the uploaded obj if possible
+	CompleteUploadSession(ctx context.Context, dstDir model.Obj, args model.UploadCompleteArgs) (model.Obj, error)
+}
+
+// AbortUploadSession is implemented by the DirectUpload drivers whose sessions hold resources in the storage
+type AbortUploadSession interface {
+	// AbortUploadSession is called once the session expired without being completed
+	AbortUploadSession(ctx context.Context, dstDir model.Obj, args model.UploadCompleteArgs) error
+}
+
 type UpdateProgress func(percentage float64)
 
 type Progress struct {
@@ -11,6 +11,8 @@ type Item struct {
 	Options  string `json:"options"`
 	Required bool   `json:"required"`
 	Help     string `json:"help"`
+	// encrypted in database and masked in api
+	Confidential bool `json:"confidential"`
 }
 
 type Info struct {
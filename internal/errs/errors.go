@@ -14,11 +14,13 @@ var (
 
 	MoveBetweenTwoStorages = errors.New("can't move files between two storages, try to copy")
 	UploadNotSupported     = errors.New("upload not supported")
+	UploadSessionNotFound  = errors.New("upload session not found or expired")
 
 	MetaNotFound     = errors.New("meta not found")
 	StorageNotFound  = errors.New("storage not found")
 	StreamIncomplete = errors.New("upload/download stream incomplete, possible network issue")
 	StreamPeekFail   = errors.New("StreamPeekFail")
+	HashMismatch     = errors.New("hash of the transferred file mismatch")
 )
 
 // NewErr wrap constant error with an extra message
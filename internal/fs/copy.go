@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"net/http"
 	stdpath "path"
+	"strings"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/journal"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/stream"
@@ -26,6 +29,7 @@ type CopyTask struct {
 	dstStorage   driver.Driver `json:"-"`
 	SrcStorageMp string        `json:"src_storage_mp"`
 	DstStorageMp string        `json:"dst_storage_mp"`
+	Verify       bool          `json:"verify"`
 }
 
 func (t *CopyTask) GetName() string {
@@ -63,9 +67,22 @@ func _copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed get dst storage")
 	}
+	dstPath := stdpath.Join(utils.FixAndCleanPath(dstDirPath), stdpath.Base(srcObjActualPath))
 	// copy if in the same storage, just call driver.Copy
 	if srcStorage.GetStorage() == dstStorage.GetStorage() {
-		return nil, op.Copy(ctx, srcStorage, srcObjActualPath, dstDirActualPath, lazyCache...)
+		err = op.Copy(ctx, srcStorage, srcObjActualPath, dstDirActualPath, lazyCache...)
+		if err == nil {
+			journal.Created(dstPath)
+		}
+		return nil, err
+	}
+	// copy on the server side if the storages are of the same backend account
+	err = op.PeerCopy(ctx, srcStorage, srcObjActualPath, dstStorage, dstDirActualPath, lazyCache...)
+	if !errs.IsNotSupportError(err) {
+		if err == nil {
+			journal.Created(dstPath)
+		}
+		return nil, err
 	}
 	if ctx.Value(conf.NoTaskKey) != nil {
 		srcObj, err := op.Get(ctx, srcStorage, srcObjActualPath)
@@ -89,7 +106,11 @@ func _copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool
 			if err != nil {
 				return nil, errors.WithMessagef(err, "failed get [%s] stream", srcObjPath)
 			}
-			return nil, op.Put(ctx, dstStorage, dstDirActualPath, ss, nil, false)
+			err = op.Put(ctx, dstStorage, dstDirActualPath, ss, nil, false)
+			if err == nil {
+				journal.Created(dstPath)
+			}
+			return nil, err
 		}
 	}
 	// not in the same storage
@@ -104,6 +125,7 @@ func _copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool
 		DstDirPath:   dstDirActualPath,
 		SrcStorageMp: srcStorage.GetStorage().MountPath,
 		DstStorageMp: dstStorage.GetStorage().MountPath,
+		Verify:       ctx.Value(conf.VerifyKey) != nil,
 	}
 	CopyTaskManager.Add(t)
 	return t, nil
@@ -116,6 +138,9 @@ func copyBetween2Storages(t *CopyTask, srcStorage, dstStorage driver.Driver, src
 		return errors.WithMessagef(err, "failed get src [%s] file", srcObjPath)
 	}
 	if srcObj.IsDir() {
+		if _, ok := srcStorage.(driver.ListR); ok {
+			return copyDirR(t, srcStorage, dstStorage, srcObj, srcObjPath, dstDirPath)
+		}
 		t.Status = "src object is dir, listing objs"
 		objs, err := op.List(t.Ctx(), srcStorage, srcObjPath, model.ListArgs{})
 		if err != nil {
@@ -137,6 +162,7 @@ func copyBetween2Storages(t *CopyTask, srcStorage, dstStorage driver.Driver, src
 				DstDirPath:   dstObjPath,
 				SrcStorageMp: srcStorage.GetStorage().MountPath,
 				DstStorageMp: dstStorage.GetStorage().MountPath,
+				Verify:       t.Verify,
 			})
 		}
 		t.Status = "src object is dir, added all copy tasks of objs"
@@ -145,6 +171,45 @@ func copyBetween2Storages(t *CopyTask, srcStorage, dstStorage driver.Driver, src
 	return copyFileBetween2Storages(t, srcStorage, dstStorage, srcObjPath, dstDirPath)
 }
 
+// copyDirR adds the copy tasks of all files in the src dir at once by listing it recursively,
+// instead of adding a task for every sub dir which lists it again
+func copyDirR(t *CopyTask, srcStorage, dstStorage driver.Driver, srcDir model.Obj, srcDirPath, dstDirPath string) error {
+	t.Status = "src object is dir, listing objs recursively"
+	dstDirPath = stdpath.Join(dstDirPath, srcDir.GetName())
+	err := op.ListR(t.Ctx(), srcStorage, srcDirPath, model.ListArgs{}, func(parent string, objs []model.Obj) error {
+		if utils.IsCanceled(t.Ctx()) {
+			return t.Ctx().Err()
+		}
+		dstParent := stdpath.Join(dstDirPath, strings.TrimPrefix(parent, srcDirPath))
+		for _, obj := range objs {
+			if obj.IsDir() {
+				continue
+			}
+			CopyTaskManager.Add(&CopyTask{
+				TaskWithCreator: task.TaskWithCreator{
+					Creator: t.Creator,
+				},
+				srcStorage:   srcStorage,
+				dstStorage:   dstStorage,
+				SrcObjPath:   stdpath.Join(parent, obj.GetName()),
+				DstDirPath:   dstParent,
+				SrcStorageMp: srcStorage.GetStorage().MountPath,
+				DstStorageMp: dstStorage.GetStorage().MountPath,
+				Verify:       t.Verify,
+			})
+		}
+		return nil
+	})
+	if utils.IsCanceled(t.Ctx()) {
+		return nil
+	}
+	if err != nil {
+		return errors.WithMessagef(err, "failed list src [%s] objs", srcDirPath)
+	}
+	t.Status = "src object is dir, added all copy tasks of objs"
+	return nil
+}
+
 func copyFileBetween2Storages(tsk *CopyTask, srcStorage, dstStorage driver.Driver, srcFilePath, dstDirPath string) error {
 	srcFile, err := op.Get(tsk.Ctx(), srcStorage, srcFilePath)
 	if err != nil {
@@ -165,5 +230,14 @@ func copyFileBetween2Storages(tsk *CopyTask, srcStorage, dstStorage driver.Drive
 	if err != nil {
 		return errors.WithMessagef(err, "failed get [%s] stream", srcFilePath)
 	}
-	return op.Put(tsk.Ctx(), dstStorage, dstDirPath, ss, tsk.SetProgress, true)
+	err = op.Put(tsk.Ctx(), dstStorage, dstDirPath, ss, tsk.SetProgress, true)
+	if err != nil {
+		return err
+	}
+	journal.Created(utils.GetFullPath(dstStorage.GetStorage().MountPath, stdpath.Join(dstDirPath, srcFile.GetName())))
+	if !tsk.Verify {
+		return nil
+	}
+	tsk.Status = "verifying"
+	return verifyCopy(tsk.Ctx(), srcStorage, dstStorage, srcFilePath, stdpath.Join(dstDirPath, srcFile.GetName()))
 }
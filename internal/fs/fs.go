@@ -3,9 +3,11 @@ package fs
 import (
 	"context"
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -29,6 +31,45 @@ func List(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error)
 	return res, nil
 }
 
+type ListPageArgs struct {
+	Token   string
+	Limit   int
+	Refresh bool
+	NoLog   bool
+}
+
+// ListPage list a page of files, virtual files are only in the first page.
+// The returned token is used to get the next page and is empty after the last page.
+func ListPage(ctx context.Context, path string, args *ListPageArgs) ([]model.Obj, string, error) {
+	res, next, err := listPage(ctx, path, args)
+	if err != nil {
+		if !args.NoLog {
+			log.Errorf("failed list page %s: %+v", path, err)
+		}
+		return nil, "", err
+	}
+	return res, next, nil
+}
+
+// ListPages call fn with every page of files from args.Token to the end
+func ListPages(ctx context.Context, path string, args *ListPageArgs, fn func(objs []model.Obj) error) error {
+	pageArgs := *args
+	for {
+		objs, next, err := ListPage(ctx, path, &pageArgs)
+		if err != nil {
+			return err
+		}
+		if err = fn(objs); err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+		pageArgs.Token = next
+		pageArgs.Refresh = false
+	}
+}
+
 type GetArgs struct {
 	NoLog bool
 }
@@ -101,6 +142,24 @@ func PutDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer
 	return err
 }
 
+// CreateUploadSession lets the client upload the file of dstPath to the storage directly,
+// errs.NotImplement is returned if the storage doesn't support it
+func CreateUploadSession(ctx context.Context, dstPath string, args model.UploadSessionArgs) (string, *model.UploadSession, error) {
+	token, session, err := createUploadSession(ctx, dstPath, args)
+	if err != nil && !errs.IsNotImplement(err) {
+		log.Errorf("failed create upload session %s: %+v", dstPath, err)
+	}
+	return token, session, err
+}
+
+func CompleteUploadSession(ctx context.Context, dstPath, token string, parts []model.UploadPart) error {
+	err := completeUploadSession(ctx, dstPath, token, parts)
+	if err != nil {
+		log.Errorf("failed complete upload session %s: %+v", dstPath, err)
+	}
+	return err
+}
+
 func PutAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer) (task.TaskInfoWithCreator, error) {
 	t, err := putAsTask(ctx, dstDirPath, file)
 	if err != nil {
@@ -127,3 +186,21 @@ func Other(ctx context.Context, args model.FsOtherArgs) (interface{}, error) {
 	}
 	return res, err
 }
+
+// GetHash returns the hash of the file provided by the storage or computed before,
+// it's empty if the file has to be hashed by HashAsTask
+func GetHash(ctx context.Context, path string, ht *utils.HashType) (string, error) {
+	res, err := getHash(ctx, path, ht)
+	if err != nil {
+		log.Errorf("failed get hash %s: %+v", path, err)
+	}
+	return res, err
+}
+
+func HashAsTask(ctx context.Context, path string, ht *utils.HashType) (task.TaskInfoWithCreator, error) {
+	return hashAsTask(ctx, path, ht)
+}
+
+func StrmExportAsTask(ctx context.Context, t *StrmExportTask) (task.TaskInfoWithCreator, error) {
+	return strmExportAsTask(ctx, t)
+}
@@ -0,0 +1,228 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/cache"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/xhofe/tache"
+)
+
+// hashCache keeps the hashes computed by streaming the files,
+// the size and modified time are in the key so that a changed file is hashed again
+var hashCache = cache.New[string]("hash", 16)
+
+const hashCacheExpiration = 7 * 24 * time.Hour
+
+type HashTask struct {
+	task.TaskWithCreator
+	Status string `json:"-"`
+	Path   string `json:"path"`
+	Type   string `json:"type"`
+	Hash   string `json:"hash"`
+}
+
+func (t *HashTask) GetName() string {
+	return fmt.Sprintf("compute %s of %s", t.Type, t.Path)
+}
+
+func (t *HashTask) GetStatus() string {
+	return t.Status
+}
+
+func (t *HashTask) Run() error {
+	ht, ok := utils.GetHashByName(t.Type)
+	if !ok {
+		return errors.WithStack(utils.ErrUnsupported)
+	}
+	t.Status = "hashing"
+	storage, actualPath, err := op.GetStorageAndActualPath(t.Path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	obj, err := op.Get(t.Ctx(), storage, actualPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s] file", t.Path)
+	}
+	t.Hash, err = hashObj(t.Ctx(), storage, actualPath, obj, ht, t.SetProgress)
+	if err != nil {
+		return err
+	}
+	t.Status = ht.Name + ": " + t.Hash
+	return nil
+}
+
+var HashTaskManager *tache.Manager[*HashTask]
+
+// getHash returns the hash provided by the storage or computed before,
+// it's empty if the file has to be hashed by a HashTask
+func getHash(ctx context.Context, path string, ht *utils.HashType) (string, error) {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed get storage")
+	}
+	obj, err := op.Get(ctx, storage, actualPath)
+	if err != nil {
+		return "", errors.WithMessagef(err, "failed get [%s] file", path)
+	}
+	if obj.IsDir() {
+		return "", errors.WithStack(errs.NotFile)
+	}
+	return knownHash(storage, actualPath, obj, ht), nil
+}
+
+// hashAsTask adds a HashTask, the unfinished one of the same file and type is reused
+func hashAsTask(ctx context.Context, path string, ht *utils.HashType) (task.TaskInfoWithCreator, error) {
+	path = utils.FixAndCleanPath(path)
+	running := HashTaskManager.GetByCondition(func(t *HashTask) bool {
+		return t.Path == path && t.Type == ht.Name &&
+			utils.SliceContains([]tache.State{tache.StatePending, tache.StateRunning, tache.StateWaitingRetry}, t.GetState())
+	})
+	if len(running) > 0 {
+		return running[0], nil
+	}
+	taskCreator, _ := ctx.Value("user").(*model.User) // taskCreator is nil when convert failed
+	t := &HashTask{
+		TaskWithCreator: task.TaskWithCreator{
+			Creator: taskCreator,
+		},
+		Path: path,
+		Type: ht.Name,
+	}
+	HashTaskManager.Add(t)
+	return t, nil
+}
+
+func hashKey(storage driver.Driver, path string, obj model.Obj, ht *utils.HashType) string {
+	return fmt.Sprintf("%s|%d|%d|%s", op.Key(storage, path), obj.GetSize(), obj.ModTime().UnixNano(), ht.Name)
+}
+
+func knownHash(storage driver.Driver, path string, obj model.Obj, ht *utils.HashType) string {
+	if h := obj.GetHash().GetHash(ht); h != "" {
+		return h
+	}
+	h, _ := hashCache.Get(hashKey(storage, path, obj, ht))
+	return h
+}
+
+// hashObj returns the known hash of obj or computes it by streaming the file
+func hashObj(ctx context.Context, storage driver.Driver, path string, obj model.Obj, ht *utils.HashType, up driver.UpdateProgress) (string, error) {
+	if obj.IsDir() {
+		return "", errors.WithStack(errs.NotFile)
+	}
+	if h := knownHash(storage, path, obj, ht); h != "" {
+		return h, nil
+	}
+	link, _, err := op.Link(ctx, storage, path, model.LinkArgs{
+		Header: http.Header{},
+	})
+	if err != nil {
+		return "", errors.WithMessagef(err, "failed get [%s] link", path)
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{
+		Obj: obj,
+		Ctx: ctx,
+	}, link)
+	if err != nil {
+		return "", errors.WithMessagef(err, "failed get [%s] stream", path)
+	}
+	defer ss.Close()
+	var reader io.Reader = ss
+	if up != nil {
+		reader = &progressReader{Reader: ss, size: obj.GetSize(), up: up}
+	}
+	// the size is needed by some types, like gcid
+	h, err := utils.HashReader(ht, reader, obj.GetSize())
+	if err != nil {
+		return "", err
+	}
+	hashCache.Set(hashKey(storage, path, obj, ht), h, hashCacheExpiration)
+	return h, nil
+}
+
+type progressReader struct {
+	io.Reader
+	read int64
+	size int64
+	up   driver.UpdateProgress
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.read += int64(n)
+	if r.size > 0 {
+		r.up(float64(r.read) / float64(r.size) * 100)
+	}
+	return n, err
+}
+
+// verifyCopy compares the hashes of the src file and the copied dst file.
+// The type provided by both storages is preferred, so that no file has to be streamed again.
+func verifyCopy(ctx context.Context, srcStorage, dstStorage driver.Driver, srcPath, dstPath string) error {
+	srcObj, err := op.Get(ctx, srcStorage, srcPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get src [%s] file", srcPath)
+	}
+	ht := utils.MD5
+	if dstObj, err := op.Get(ctx, dstStorage, dstPath); err == nil {
+		for t := range srcObj.GetHash().Export() {
+			if dstObj.GetHash().GetHash(t) != "" {
+				ht = t
+				break
+			}
+		}
+	}
+	expect, err := hashObj(ctx, srcStorage, srcPath, srcObj, ht, nil)
+	if err != nil {
+		return errors.WithMessagef(err, "failed hash src [%s] file", srcPath)
+	}
+	return verifyHash(ctx, dstStorage, dstPath, srcObj.GetSize(), ht, expect)
+}
+
+// verifyHash checks the dst file is of the size and hash expected. As the dst may be
+// cached before the transfer, its dir is listed again and checked once more on mismatch.
+func verifyHash(ctx context.Context, storage driver.Driver, path string, size int64, ht *utils.HashType, expect string) error {
+	var err error
+	for _, refresh := range []bool{false, true} {
+		if refresh {
+			if _, err := op.List(ctx, storage, stdpath.Dir(path), model.ListArgs{Refresh: true}); err != nil {
+				return errors.WithMessagef(err, "failed refresh dst [%s] dir", stdpath.Dir(path))
+			}
+		}
+		var obj model.Obj
+		obj, err = op.Get(ctx, storage, path)
+		if err != nil {
+			if errs.IsObjectNotFound(err) {
+				continue
+			}
+			return errors.WithMessagef(err, "failed get dst [%s] file", path)
+		}
+		if obj.GetSize() != size {
+			err = errors.Wrapf(errs.HashMismatch, "size of [%s] is %d, expect %d", path, obj.GetSize(), size)
+			continue
+		}
+		var h string
+		h, err = hashObj(ctx, storage, path, obj, ht, nil)
+		if err != nil {
+			return errors.WithMessagef(err, "failed hash dst [%s] file", path)
+		}
+		if strings.EqualFold(h, expect) {
+			return nil
+		}
+		err = errors.Wrapf(errs.HashMismatch, "%s of [%s] is %s, expect %s", ht.Name, path, h, expect)
+	}
+	return err
+}
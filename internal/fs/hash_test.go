@@ -0,0 +1,134 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+// memDriver keeps the files at root in memory, the listings are cached by op,
+// so a file changed behind it is stale until the root is refreshed
+type memDriver struct {
+	model.Storage
+	driver.RootPath
+	files map[string][]byte
+	// corrupt drops the last byte of the files put
+	corrupt bool
+}
+
+func newMemDriver(mountPath string) *memDriver {
+	return &memDriver{
+		Storage: model.Storage{MountPath: mountPath, CacheExpiration: 30, Modified: time.Now()},
+		files:   map[string][]byte{},
+	}
+}
+
+func (d *memDriver) Config() driver.Config          { return driver.Config{Name: "Mem"} }
+func (d *memDriver) GetAddition() driver.Additional { return &d.RootPath }
+func (d *memDriver) Init(ctx context.Context) error { return nil }
+func (d *memDriver) Drop(ctx context.Context) error { return nil }
+func (d *memDriver) GetRootPath() string            { return "/" }
+func (d *memDriver) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	return nil, errs.NotImplement
+}
+func (d *memDriver) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	var objs []model.Obj
+	for name, data := range d.files {
+		objs = append(objs, &model.Object{
+			Name:     name,
+			Path:     "/" + name,
+			Size:     int64(len(data)),
+			HashInfo: utils.NewHashInfo(utils.MD5, utils.HashData(utils.MD5, data)),
+		})
+	}
+	return objs, nil
+}
+
+func (d *memDriver) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	return errs.NotImplement
+}
+
+func (d *memDriver) Put(ctx context.Context, dstDir model.Obj, file model.FileStreamer, up driver.UpdateProgress) error {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	if d.corrupt && len(data) > 0 {
+		data = data[:len(data)-1]
+	}
+	d.files[file.GetName()] = data
+	return nil
+}
+
+func TestVerifyHash(t *testing.T) {
+	ctx := context.Background()
+	storage := newMemDriver("/verify_hash")
+	storage.files["a.txt"] = []byte("old")
+	data := []byte("new!")
+	expect := utils.HashData(utils.MD5, data)
+	if err := verifyHash(ctx, storage, "/a.txt", int64(len(data)), utils.MD5, expect); !errors.Is(err, errs.HashMismatch) {
+		t.Fatalf("expect hash mismatch for old file, got %+v", err)
+	}
+	// the old file is cached now, it's found by listing again
+	storage.files["a.txt"] = data
+	if err := verifyHash(ctx, storage, "/a.txt", int64(len(data)), utils.MD5, expect); err != nil {
+		t.Errorf("expect the file verified after refresh, got %+v", err)
+	}
+	if err := verifyHash(ctx, storage, "/a.txt", 10, utils.MD5, expect); !errors.Is(err, errs.HashMismatch) {
+		t.Errorf("expect hash mismatch for size, got %+v", err)
+	}
+	if err := verifyHash(ctx, storage, "/a.txt", int64(len(data)), utils.MD5, utils.HashData(utils.MD5, []byte("new?"))); !errors.Is(err, errs.HashMismatch) {
+		t.Errorf("expect hash mismatch for content, got %+v", err)
+	}
+	if err := verifyHash(ctx, storage, "/b.txt", 1, utils.MD5, expect); err == nil {
+		t.Errorf("expect error for missing file")
+	}
+}
+
+func TestUploadTaskVerify(t *testing.T) {
+	conf.Conf.TempDir = t.TempDir()
+	storage := newMemDriver("/verify_upload")
+	for name, corrupt := range map[string]bool{"a.txt": false, "b.txt": true} {
+		storage.corrupt = corrupt
+		data := []byte("hello world")
+		upload := &UploadTask{
+			storage:          storage,
+			dstDirActualPath: "/",
+			file: &stream.FileStream{
+				Obj:    &model.Object{Name: name, Size: int64(len(data))},
+				Reader: bytes.NewReader(data),
+			},
+			verify: true,
+		}
+		upload.SetCtx(context.Background())
+		err := upload.Run()
+		if corrupt && !errors.Is(err, errs.HashMismatch) {
+			t.Errorf("expect hash mismatch for corrupted upload, got %+v", err)
+		}
+		if !corrupt && err != nil {
+			t.Errorf("expect upload verified, got %+v", err)
+		}
+	}
+}
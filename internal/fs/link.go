@@ -12,11 +12,21 @@ import (
 )
 
 func link(ctx context.Context, path string, args model.LinkArgs) (*model.Link, model.Obj, error) {
-	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	storages, actualPath, err := op.GetStoragesAndActualPath(path)
 	if err != nil {
 		return nil, nil, errors.WithMessage(err, "failed get storage")
 	}
-	l, obj, err := op.Link(ctx, storage, actualPath, args)
+	var (
+		l   *model.Link
+		obj model.Obj
+	)
+	for _, storage := range storages {
+		l, obj, err = op.Link(ctx, storage, actualPath, args)
+		// fail over to the next member of the balance group
+		if !op.IsBalanceFailure(err) || ctx.Err() != nil {
+			break
+		}
+	}
 	if err != nil {
 		return nil, nil, errors.WithMessage(err, "failed link")
 	}
@@ -2,7 +2,11 @@ package fs
 
 import (
 	"context"
+	"encoding/base64"
+	stdpath "path"
+	"strings"
 
+	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -15,17 +19,23 @@ func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error)
 	meta, _ := ctx.Value("meta").(*model.Meta)
 	user, _ := ctx.Value("user").(*model.User)
 	virtualFiles := op.GetStorageVirtualFilesByPath(path)
-	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	storages, actualPath, err := op.GetStoragesAndActualPath(path)
 	if err != nil && len(virtualFiles) == 0 {
 		return nil, errors.WithMessage(err, "failed get storage")
 	}
 
 	var _objs []model.Obj
-	if storage != nil {
-		_objs, err = op.List(ctx, storage, actualPath, model.ListArgs{
-			ReqPath: path,
-			Refresh: args.Refresh,
-		})
+	if len(storages) > 0 {
+		for _, storage := range storages {
+			_objs, err = op.List(ctx, storage, actualPath, model.ListArgs{
+				ReqPath: path,
+				Refresh: args.Refresh,
+			})
+			// fail over to the next member of the balance group
+			if !op.IsBalanceFailure(err) || ctx.Err() != nil {
+				break
+			}
+		}
 		if err != nil {
 			if !args.NoLog {
 				log.Errorf("fs/list: %+v", err)
@@ -44,6 +54,88 @@ func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error)
 	return objs, nil
 }
 
+// List a page of files, the page token pins the member of the balance group
+// that the first page is listed from, since the op token only makes sense to it
+func listPage(ctx context.Context, path string, args *ListPageArgs) ([]model.Obj, string, error) {
+	meta, _ := ctx.Value("meta").(*model.Meta)
+	user, _ := ctx.Value("user").(*model.User)
+	mountPath, token, err := decodePageToken(args.Token)
+	if err != nil {
+		return nil, "", err
+	}
+	var virtualFiles []model.Obj
+	if args.Token == "" {
+		virtualFiles = op.GetStorageVirtualFilesByPath(path)
+	}
+	storages, actualPath, err := op.GetStoragesAndActualPath(path)
+	if err != nil && len(virtualFiles) == 0 {
+		return nil, "", errors.WithMessage(err, "failed get storage")
+	}
+	if mountPath != "" {
+		storages = utils.SliceFilter(storages, func(storage driver.Driver) bool {
+			return storage.GetStorage().MountPath == mountPath
+		})
+		if len(storages) == 0 {
+			return nil, "", errors.Errorf("storage of list token not found: %s", mountPath)
+		}
+	}
+
+	var _objs []model.Obj
+	var next string
+	if len(storages) > 0 {
+		for _, storage := range storages {
+			_objs, next, err = op.ListPage(ctx, storage, actualPath, model.ListPageArgs{
+				ReqPath: path,
+				Token:   token,
+				Limit:   args.Limit,
+				Refresh: args.Refresh,
+			})
+			if next != "" {
+				next = encodePageToken(storage.GetStorage().MountPath, next)
+			}
+			// fail over to the next member of the balance group,
+			// only for the first page as later pages are pinned
+			if !op.IsBalanceFailure(err) || ctx.Err() != nil || token != "" {
+				break
+			}
+		}
+		if err != nil {
+			if !args.NoLog {
+				log.Errorf("fs/list_page: %+v", err)
+			}
+			if len(virtualFiles) == 0 {
+				return nil, "", errors.WithMessage(err, "failed get objs")
+			}
+		}
+	}
+
+	om := model.NewObjMerge()
+	if whetherHide(user, meta, path) {
+		om.InitHideReg(meta.Hide)
+	}
+	objs := om.Merge(_objs, virtualFiles...)
+	return objs, next, nil
+}
+
+func encodePageToken(mountPath, token string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(mountPath + "\n" + token))
+}
+
+func decodePageToken(s string) (mountPath string, token string, err error) {
+	if s == "" {
+		return "", "", nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return "", "", errors.Errorf("invalid list token: %s", s)
+	}
+	mountPath, token, ok := strings.Cut(string(b), "\n")
+	if !ok || mountPath == "" {
+		return "", "", errors.Errorf("invalid list token: %s", s)
+	}
+	return mountPath, token, nil
+}
+
 func whetherHide(user *model.User, meta *model.Meta, path string) bool {
 	// if is admin, don't hide
 	if user == nil || user.CanSeeHides() {
@@ -64,3 +156,25 @@ func whetherHide(user *model.User, meta *model.Meta, path string) bool {
 	// if is guest, hide
 	return true
 }
+
+// IsHidden checks whether the path is hidden from the user by the metas of the folders from root to it,
+// the objs in the hidden folders are hidden too, like they are listed folder by folder
+func IsHidden(user *model.User, root, path string) bool {
+	if user == nil || user.CanSeeHides() {
+		return false
+	}
+	root, path = utils.FixAndCleanPath(root), utils.FixAndCleanPath(path)
+	for p := path; p != root && p != "/"; p = stdpath.Dir(p) {
+		parent := stdpath.Dir(p)
+		meta, _ := op.GetNearestMeta(parent)
+		if !whetherHide(user, meta, parent) {
+			continue
+		}
+		om := model.NewObjMerge()
+		om.InitHideReg(meta.Hide)
+		if len(om.Merge([]model.Obj{&model.Object{Name: stdpath.Base(p)}})) == 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package fs
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+func TestIsHidden(t *testing.T) {
+	if err := op.CreateMeta(&model.Meta{Path: "/hide", Hide: "^secret$", HSub: true}); err != nil {
+		t.Fatalf("failed create meta: %+v", err)
+	}
+	user := &model.User{}
+	for path, expect := range map[string]bool{
+		"/hide/a.txt":            false,
+		"/hide/secret":           true,
+		"/hide/secret/a.txt":     true,
+		"/hide/sub/secret/a.txt": true,
+		"/hide/secrets/a.txt":    false,
+		"/other/secret/a.txt":    false,
+	} {
+		if got := IsHidden(user, "/", path); got != expect {
+			t.Errorf("expect %s hidden %v, got %v", path, expect, got)
+		}
+	}
+	if IsHidden(user, "/hide/secret", "/hide/secret/a.txt") {
+		t.Errorf("expect the folders above root not checked")
+	}
+	if IsHidden(&model.User{Permission: 1}, "/", "/hide/secret/a.txt") {
+		t.Errorf("expect nothing hidden from the user can see hides")
+	}
+}
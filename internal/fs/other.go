@@ -2,11 +2,16 @@ package fs
 
 import (
 	"context"
+	stdpath "path"
 
+	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/journal"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 func makeDir(ctx context.Context, path string, lazyCache ...bool) error {
@@ -14,7 +19,11 @@ func makeDir(ctx context.Context, path string, lazyCache ...bool) error {
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
-	return op.MakeDir(ctx, storage, actualPath, lazyCache...)
+	err = op.MakeDir(ctx, storage, actualPath, lazyCache...)
+	if err == nil {
+		journal.Created(path)
+	}
+	return err
 }
 
 func move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) error {
@@ -27,9 +36,20 @@ func move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) er
 		return errors.WithMessage(err, "failed get dst storage")
 	}
 	if srcStorage.GetStorage() != dstStorage.GetStorage() {
-		return errors.WithStack(errs.MoveBetweenTwoStorages)
+		err = op.PeerMove(ctx, srcStorage, srcActualPath, dstStorage, dstDirActualPath, lazyCache...)
+		if errs.IsNotSupportError(err) {
+			return errors.WithStack(errs.MoveBetweenTwoStorages)
+		}
+	} else {
+		err = op.Move(ctx, srcStorage, srcActualPath, dstDirActualPath, lazyCache...)
+	}
+	if err == nil {
+		srcPath = utils.FixAndCleanPath(srcPath)
+		dstPath := stdpath.Join(utils.FixAndCleanPath(dstDirPath), stdpath.Base(srcPath))
+		moveTags(srcPath, dstPath)
+		journal.Moved(srcPath, dstPath)
 	}
-	return op.Move(ctx, srcStorage, srcActualPath, dstDirActualPath, lazyCache...)
+	return err
 }
 
 func rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) error {
@@ -37,7 +57,21 @@ func rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) err
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
-	return op.Rename(ctx, storage, srcActualPath, dstName, lazyCache...)
+	err = op.Rename(ctx, storage, srcActualPath, dstName, lazyCache...)
+	if err == nil {
+		srcPath = utils.FixAndCleanPath(srcPath)
+		dstPath := stdpath.Join(stdpath.Dir(srcPath), dstName)
+		moveTags(srcPath, dstPath)
+		journal.Moved(srcPath, dstPath)
+	}
+	return err
+}
+
+// moveTags makes the tags follow the moved or renamed object, the object is moved anyway if it fails
+func moveTags(srcPath, dstPath string) {
+	if err := db.MoveTags(srcPath, dstPath); err != nil {
+		log.Errorf("failed move tags of %s to %s: %+v", srcPath, dstPath, err)
+	}
 }
 
 func remove(ctx context.Context, path string) error {
@@ -45,7 +79,14 @@ func remove(ctx context.Context, path string) error {
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
-	return op.Remove(ctx, storage, actualPath)
+	err = op.Remove(ctx, storage, actualPath)
+	if err == nil {
+		if err := db.DeleteTagsUnder(utils.FixAndCleanPath(path)); err != nil {
+			log.Errorf("failed delete tags of %s: %+v", path, err)
+		}
+		journal.Deleted(path)
+	}
+	return err
 }
 
 func other(ctx context.Context, args model.FsOtherArgs) (interface{}, error) {
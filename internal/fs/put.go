@@ -3,11 +3,16 @@ package fs
 import (
 	"context"
 	"fmt"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/journal"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
 	"github.com/xhofe/tache"
 )
@@ -17,6 +22,7 @@ type UploadTask struct {
 	storage          driver.Driver
 	dstDirActualPath string
 	file             model.FileStreamer
+	verify           bool
 }
 
 func (t *UploadTask) GetName() string {
@@ -28,7 +34,30 @@ func (t *UploadTask) GetStatus() string {
 }
 
 func (t *UploadTask) Run() error {
-	return op.Put(t.Ctx(), t.storage, t.dstDirActualPath, t.file, t.SetProgress, true)
+	dstPath := utils.GetFullPath(t.storage.GetStorage().MountPath, stdpath.Join(t.dstDirActualPath, t.file.GetName()))
+	if !t.verify {
+		err := op.Put(t.Ctx(), t.storage, t.dstDirActualPath, t.file, t.SetProgress, true)
+		if err == nil {
+			journal.Created(dstPath)
+		}
+		return err
+	}
+	// hash the file before put, as it's closed after that
+	tmpFile, err := t.file.CacheFullInTempFile()
+	if err != nil {
+		return errors.Wrapf(err, "failed to create temp file")
+	}
+	expect, err := utils.HashFile(utils.MD5, tmpFile)
+	if err != nil {
+		return err
+	}
+	name, size := t.file.GetName(), t.file.GetSize()
+	err = op.Put(t.Ctx(), t.storage, t.dstDirActualPath, t.file, t.SetProgress, true)
+	if err != nil {
+		return err
+	}
+	journal.Created(dstPath)
+	return verifyHash(t.Ctx(), t.storage, stdpath.Join(t.dstDirActualPath, name), size, utils.MD5, expect)
 }
 
 var UploadTaskManager *tache.Manager[*UploadTask]
@@ -58,6 +87,7 @@ func putAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer)
 		storage:          storage,
 		dstDirActualPath: dstDirActualPath,
 		file:             file,
+		verify:           ctx.Value(conf.VerifyKey) != nil,
 	}
 	UploadTaskManager.Add(t)
 	return t, nil
@@ -72,5 +102,43 @@ func putDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer
 	if storage.Config().NoUpload {
 		return errors.WithStack(errs.UploadNotSupported)
 	}
-	return op.Put(ctx, storage, dstDirActualPath, file, nil, lazyCache...)
+	name := file.GetName()
+	err = op.Put(ctx, storage, dstDirActualPath, file, nil, lazyCache...)
+	if err == nil {
+		journal.Created(stdpath.Join(dstDirPath, name))
+	}
+	return err
+}
+
+// sessionStorage returns the same storage of a balance group for creating and completing a session
+func sessionStorage(dstDirPath string) (driver.Driver, string, error) {
+	storages, dstDirActualPath, err := op.GetStoragesAndActualPath(dstDirPath)
+	if err != nil {
+		return nil, "", errors.WithMessage(err, "failed get storage")
+	}
+	return storages[0], dstDirActualPath, nil
+}
+
+func createUploadSession(ctx context.Context, dstPath string, args model.UploadSessionArgs) (string, *model.UploadSession, error) {
+	storage, dstDirActualPath, err := sessionStorage(stdpath.Dir(dstPath))
+	if err != nil {
+		return "", nil, err
+	}
+	if storage.Config().NoUpload {
+		return "", nil, errors.WithStack(errs.UploadNotSupported)
+	}
+	args.Name = stdpath.Base(dstPath)
+	return op.CreateUploadSession(ctx, storage, dstDirActualPath, args)
+}
+
+func completeUploadSession(ctx context.Context, dstPath, token string, parts []model.UploadPart) error {
+	storage, dstDirActualPath, err := sessionStorage(stdpath.Dir(dstPath))
+	if err != nil {
+		return err
+	}
+	err = op.CompleteUploadSession(ctx, storage, dstDirActualPath, stdpath.Base(dstPath), token, parts)
+	if err == nil {
+		journal.Created(dstPath)
+	}
+	return err
 }
@@ -0,0 +1,358 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/alist-org/alist/v3/cmd/flags"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/xhofe/tache"
+)
+
+// strmCopyExts are the extensions of the files copied beside the strm files,
+// so that media servers can find the subtitles and metadata of the videos
+var strmCopyExts = []string{"srt", "ass", "ssa", "vtt", "sub", "idx", "sup", "smi", "nfo"}
+
+// StrmExportTask writes a strm file holding the signed /d link for each video and audio file
+// in SrcPath, into a mirrored tree in DstPath, which is an alist path or a local dir if Local
+type StrmExportTask struct {
+	task.TaskWithCreator
+	Status   string `json:"-"`
+	SrcPath  string `json:"src_path"`
+	DstPath  string `json:"dst_path"`
+	Local    bool   `json:"local"`
+	SiteURL  string `json:"site_url"`
+	MaxDepth int    `json:"max_depth"`
+}
+
+func (t *StrmExportTask) GetName() string {
+	target := "alist"
+	if t.Local {
+		target = "local"
+	}
+	return fmt.Sprintf("export strm of %s to %s [%s]", t.SrcPath, target, t.DstPath)
+}
+
+func (t *StrmExportTask) GetStatus() string {
+	return t.Status
+}
+
+func (t *StrmExportTask) Run() error {
+	var target strmTarget = &alistStrmTarget{ctx: t.Ctx(), root: t.DstPath}
+	if t.Local {
+		target = &localStrmTarget{root: t.DstPath}
+	}
+	root, err := Get(t.Ctx(), t.SrcPath, &GetArgs{})
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s]", t.SrcPath)
+	}
+	manifest := strmManifestFile(t.SrcPath, target)
+	owned, err := loadStrmManifest(manifest)
+	if err != nil {
+		return errors.WithMessage(err, "failed load strm manifest")
+	}
+	var written, copied, failed int
+	var listFailed []string
+	exported := make(map[string]struct{})
+	err = WalkFS(t.Ctx(), t.MaxDepth, t.SrcPath, root, func(reqPath string, info model.Obj) error {
+		if err := t.Ctx().Err(); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel := strings.TrimPrefix(reqPath, strings.TrimSuffix(t.SrcPath, "/"))
+		switch fileType := utils.GetFileType(info.GetName()); {
+		case fileType == conf.VIDEO || fileType == conf.AUDIO:
+			name := strings.TrimSuffix(rel, stdpath.Ext(rel)) + ".strm"
+			exported[name] = struct{}{}
+			content := []byte(fmt.Sprintf("%s/d%s?sign=%s", t.SiteURL, utils.EncodePath(reqPath, true), sign.Sign(reqPath)))
+			changed, err := writeStrm(target, name, content)
+			if err != nil {
+				failed++
+				log.Warnf("failed write strm of %s: %+v", reqPath, err)
+			} else if changed {
+				written++
+			}
+		case utils.SliceContains(strmCopyExts, strings.ToLower(utils.Ext(info.GetName()))):
+			if size, ok := target.stat(rel); ok && size == info.GetSize() {
+				break
+			}
+			if err := copyToStrmTarget(t.Ctx(), target, reqPath, rel, info); err != nil {
+				failed++
+				log.Warnf("failed copy %s: %+v", reqPath, err)
+			} else {
+				copied++
+			}
+		}
+		t.Status = fmt.Sprintf("written %d strm files, copied %d files, %d failed", written, copied, failed)
+		return nil
+	}, func(reqPath string, err error) {
+		listFailed = append(listFailed, reqPath)
+	})
+	// the strm files written are owned even if the walk is stopped
+	for name := range exported {
+		owned[name] = struct{}{}
+	}
+	if err == nil && len(listFailed) == 0 {
+		// the orphaned strm files are removed only if the src is walked completely
+		removed := removeOrphanedStrm(target, owned, exported, t.MaxDepth)
+		t.Status = fmt.Sprintf("written %d strm files, copied %d files, removed %d orphaned strm files, %d failed",
+			written, copied, removed, failed)
+	} else if err == nil {
+		t.Status = fmt.Sprintf("written %d strm files, copied %d files, %d failed, orphaned strm files are kept as %d folders failed to list",
+			written, copied, failed, len(listFailed))
+	}
+	if saveErr := saveStrmManifest(manifest, owned); saveErr != nil {
+		log.Errorf("failed save strm manifest of %s: %+v", t.DstPath, saveErr)
+	}
+	return err
+}
+
+// strmManifestFile returns the file keeping the names of the strm files written by the exports
+// of src to target, only these are removed once orphaned, so the other strm files in target are kept
+func strmManifestFile(src string, target strmTarget) string {
+	kind := "alist"
+	if _, ok := target.(*localStrmTarget); ok {
+		kind = "local"
+	}
+	sum := md5.Sum([]byte(src + "\n" + kind + "\n" + target.rootPath()))
+	return filepath.Join(flags.DataDir, "strm", hex.EncodeToString(sum[:])+".json")
+}
+
+func loadStrmManifest(file string) (map[string]struct{}, error) {
+	owned := make(map[string]struct{})
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return owned, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	var names []string
+	if err = utils.Json.Unmarshal(data, &names); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, name := range names {
+		owned[name] = struct{}{}
+	}
+	return owned, nil
+}
+
+func saveStrmManifest(file string, owned map[string]struct{}) error {
+	names := make([]string, 0, len(owned))
+	for name := range owned {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	data, err := utils.Json.Marshal(names)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err = os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.WriteFile(file, data, 0666))
+}
+
+// removeOrphanedStrm removes the owned strm files not exported by the complete walk, the ones deeper
+// than maxDepth are kept as their src files are not walked. The removed ones are dropped from owned.
+func removeOrphanedStrm(target strmTarget, owned, exported map[string]struct{}, maxDepth int) int {
+	removed := 0
+	for name := range owned {
+		if _, ok := exported[name]; ok {
+			continue
+		}
+		if maxDepth >= 0 && strings.Count(name, "/") > maxDepth {
+			continue
+		}
+		if _, ok := target.stat(name); ok {
+			if err := target.remove(name); err != nil {
+				log.Warnf("failed remove orphaned strm %s: %+v", name, err)
+				continue
+			}
+			removed++
+		}
+		delete(owned, name)
+	}
+	return removed
+}
+
+var StrmExportTaskManager *tache.Manager[*StrmExportTask]
+
+// strmTarget is where the strm tree is written, the names are relative to its root
+type strmTarget interface {
+	stat(name string) (int64, bool)
+	read(name string) ([]byte, error)
+	write(name string, r io.Reader, size int64) error
+	remove(name string) error
+	rootPath() string
+}
+
+// writeStrm writes the strm file if its content is changed
+func writeStrm(target strmTarget, name string, content []byte) (bool, error) {
+	if size, ok := target.stat(name); ok && size == int64(len(content)) {
+		if old, err := target.read(name); err == nil && bytes.Equal(old, content) {
+			return false, nil
+		}
+	}
+	return true, target.write(name, bytes.NewReader(content), int64(len(content)))
+}
+
+func copyToStrmTarget(ctx context.Context, target strmTarget, path, name string, obj model.Obj) error {
+	rc, err := openFile(ctx, path, obj)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return target.write(name, rc, obj.GetSize())
+}
+
+// openFile opens the file of alist to read
+func openFile(ctx context.Context, path string, obj model.Obj) (io.ReadCloser, error) {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage")
+	}
+	link, _, err := op.Link(ctx, storage, actualPath, model.LinkArgs{
+		Header: http.Header{},
+	})
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed get [%s] link", path)
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{
+		Obj: obj,
+		Ctx: ctx,
+	}, link)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed get [%s] stream", path)
+	}
+	return ss, nil
+}
+
+type localStrmTarget struct {
+	root string
+}
+
+func (l *localStrmTarget) path(name string) string {
+	return filepath.Join(l.root, filepath.FromSlash(name))
+}
+
+func (l *localStrmTarget) stat(name string) (int64, bool) {
+	fi, err := os.Stat(l.path(name))
+	if err != nil || fi.IsDir() {
+		return 0, false
+	}
+	return fi.Size(), true
+}
+
+func (l *localStrmTarget) read(name string) ([]byte, error) {
+	return os.ReadFile(l.path(name))
+}
+
+func (l *localStrmTarget) write(name string, r io.Reader, size int64) error {
+	p := l.path(name)
+	if err := os.MkdirAll(filepath.Dir(p), 0777); err != nil {
+		return errors.WithStack(err)
+	}
+	f, err := os.Create(p)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	_, err = utils.CopyWithBuffer(f, r)
+	return err
+}
+
+func (l *localStrmTarget) rootPath() string {
+	return l.root
+}
+
+func (l *localStrmTarget) remove(name string) error {
+	return os.Remove(l.path(name))
+}
+
+type alistStrmTarget struct {
+	ctx  context.Context
+	root string
+}
+
+func (a *alistStrmTarget) path(name string) string {
+	return stdpath.Join(a.root, name)
+}
+
+func (a *alistStrmTarget) stat(name string) (int64, bool) {
+	obj, err := Get(a.ctx, a.path(name), &GetArgs{NoLog: true})
+	if err != nil || obj.IsDir() {
+		return 0, false
+	}
+	return obj.GetSize(), true
+}
+
+func (a *alistStrmTarget) read(name string) ([]byte, error) {
+	obj, err := Get(a.ctx, a.path(name), &GetArgs{NoLog: true})
+	if err != nil {
+		return nil, err
+	}
+	rc, err := openFile(a.ctx, a.path(name), obj)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func (a *alistStrmTarget) write(name string, r io.Reader, size int64) error {
+	p := a.path(name)
+	return PutDirectly(a.ctx, stdpath.Dir(p), &stream.FileStream{
+		Ctx: a.ctx,
+		Obj: &model.Object{
+			Name: stdpath.Base(p),
+			Size: size,
+		},
+		Reader:   r,
+		Mimetype: utils.GetMimeType(p),
+	})
+}
+
+func (a *alistStrmTarget) rootPath() string {
+	return a.root
+}
+
+func (a *alistStrmTarget) remove(name string) error {
+	return Remove(a.ctx, a.path(name))
+}
+
+// strmExportAsTask adds a StrmExportTask, the dst of alist can't be in the src
+func strmExportAsTask(ctx context.Context, t *StrmExportTask) (task.TaskInfoWithCreator, error) {
+	t.SrcPath = utils.FixAndCleanPath(t.SrcPath)
+	if t.Local {
+		t.DstPath = filepath.Clean(t.DstPath)
+	} else {
+		t.DstPath = utils.FixAndCleanPath(t.DstPath)
+		if utils.IsSubPath(t.SrcPath, t.DstPath) {
+			return nil, errors.New("the dst path can't be in the src path")
+		}
+	}
+	t.Creator, _ = ctx.Value("user").(*model.User) // taskCreator is nil when convert failed
+	StrmExportTaskManager.Add(t)
+	return t, nil
+}
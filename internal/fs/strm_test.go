@@ -0,0 +1,82 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalStrmTarget(t *testing.T) {
+	target := &localStrmTarget{root: filepath.Join(t.TempDir(), "strm")}
+	if _, ok := target.stat("/movies/a.strm"); ok {
+		t.Fatalf("expect no strm in missing root")
+	}
+	content := []byte("http://localhost/d/movies/a.mkv?sign=x")
+	for i, expect := range []bool{true, false} {
+		changed, err := writeStrm(target, "/movies/a.strm", content)
+		if err != nil {
+			t.Fatalf("failed write strm: %+v", err)
+		}
+		if changed != expect {
+			t.Errorf("write %d: expect changed %v, got %v", i, expect, changed)
+		}
+	}
+	if changed, _ := writeStrm(target, "/movies/a.strm", []byte("http://localhost/d/movies/a.mkv?sign=y")); !changed {
+		t.Errorf("expect the changed content to be written")
+	}
+	if _, err := writeStrm(target, "/b.strm", content); err != nil {
+		t.Fatalf("failed write strm: %+v", err)
+	}
+	if err := os.WriteFile(filepath.Join(target.root, "movies", "a.srt"), []byte("1"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if size, ok := target.stat("/movies/a.strm"); !ok || size != int64(len(content)) {
+		t.Errorf("unexpected strm size: %d %v", size, ok)
+	}
+	if err := target.remove("/b.strm"); err != nil {
+		t.Fatalf("failed remove strm: %+v", err)
+	}
+	if _, ok := target.stat("/b.strm"); ok {
+		t.Errorf("expect b.strm removed")
+	}
+}
+
+func TestRemoveOrphanedStrm(t *testing.T) {
+	target := &localStrmTarget{root: t.TempDir()}
+	for _, name := range []string{"/a.strm", "/old.strm", "/hand.strm", "/x/y/deep.strm"} {
+		if _, err := writeStrm(target, name, []byte(name)); err != nil {
+			t.Fatalf("failed write strm: %+v", err)
+		}
+	}
+	manifest := filepath.Join(t.TempDir(), "strm", "manifest.json")
+	owned, err := loadStrmManifest(manifest)
+	if err != nil || len(owned) != 0 {
+		t.Fatalf("expect empty manifest, got %v %+v", owned, err)
+	}
+	owned = map[string]struct{}{"/a.strm": {}, "/old.strm": {}, "/gone.strm": {}, "/x/y/deep.strm": {}}
+	exported := map[string]struct{}{"/a.strm": {}}
+	if removed := removeOrphanedStrm(target, owned, exported, 2); removed != 1 {
+		t.Errorf("expect 1 strm removed, got %d", removed)
+	}
+	for name, expect := range map[string]bool{"/a.strm": true, "/old.strm": false, "/hand.strm": true, "/x/y/deep.strm": true} {
+		if _, ok := target.stat(name); ok != expect {
+			t.Errorf("expect %s exists %v, got %v", name, expect, ok)
+		}
+	}
+	if err = saveStrmManifest(manifest, owned); err != nil {
+		t.Fatalf("failed save manifest: %+v", err)
+	}
+	owned, err = loadStrmManifest(manifest)
+	if err != nil {
+		t.Fatalf("failed load manifest: %+v", err)
+	}
+	// the deep one beyond the depth is still owned, the removed and missing ones are dropped
+	if len(owned) != 2 {
+		t.Errorf("unexpected owned strm files: %v", owned)
+	}
+	for _, name := range []string{"/a.strm", "/x/y/deep.strm"} {
+		if _, ok := owned[name]; !ok {
+			t.Errorf("expect %s owned", name)
+		}
+	}
+}
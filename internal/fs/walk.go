@@ -4,17 +4,25 @@ import (
 	"context"
 	"path"
 	"path/filepath"
+	"strings"
 
+	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
 )
 
+// ListErrFn is called with the folder WalkFS failed to list, the folder is skipped
+type ListErrFn func(reqPath string, err error)
+
 // WalkFS traverses filesystem fs starting at name up to depth levels.
 //
 // WalkFS will stop when current depth > `depth`. For each visited node,
 // WalkFS calls walkFn. If a visited file system node is a directory and
 // walkFn returns path.SkipDir, walkFS will skip traversal of this node.
-func WalkFS(ctx context.Context, depth int, name string, info model.Obj, walkFn func(reqPath string, info model.Obj) error) error {
+// The folders failed to list are skipped and reported to listErrFn if given.
+func WalkFS(ctx context.Context, depth int, name string, info model.Obj, walkFn func(reqPath string, info model.Obj) error, listErrFn ...ListErrFn) error {
 	// This implementation is based on Walk's code in the standard path/path package.
 	walkFnErr := walkFn(name, info)
 	if walkFnErr != nil {
@@ -26,15 +34,26 @@ func WalkFS(ctx context.Context, depth int, name string, info model.Obj, walkFn
 	if !info.IsDir() || depth == 0 {
 		return nil
 	}
+	// the whole sub tree can be listed at once if no other storage is mounted in it
+	if len(op.GetStorageVirtualFilesByPath(name)) == 0 {
+		storage, actualPath, err := op.GetStorageAndActualPath(name)
+		if _, ok := storage.(driver.ListR); ok && err == nil {
+			return walkR(ctx, depth, name, storage, actualPath, walkFn, listErrFn...)
+		}
+	}
 	meta, _ := op.GetNearestMeta(name)
 	// Read directory names.
-	objs, err := List(context.WithValue(ctx, "meta", meta), name, &ListArgs{})
+	objs, err := List(context.WithValue(ctx, "meta", meta), name, &ListArgs{NoLog: true})
 	if err != nil {
-		return walkFnErr
+		log.Warnf("failed list %s: %+v", name, err)
+		for _, fn := range listErrFn {
+			fn(name, err)
+		}
+		return nil
 	}
 	for _, fileInfo := range objs {
 		filename := path.Join(name, fileInfo.GetName())
-		if err := WalkFS(ctx, depth-1, filename, fileInfo, walkFn); err != nil {
+		if err := WalkFS(ctx, depth-1, filename, fileInfo, walkFn, listErrFn...); err != nil {
 			if err == filepath.SkipDir {
 				break
 			}
@@ -43,3 +62,77 @@ func WalkFS(ctx context.Context, depth int, name string, info model.Obj, walkFn
 	}
 	return nil
 }
+
+// walkR is like WalkFS but lists the sub tree of name by op.ListR,
+// which is much faster than listing folder by folder on some storages
+func walkR(ctx context.Context, depth int, name string, storage driver.Driver, actualPath string, walkFn func(reqPath string, info model.Obj) error, listErrFn ...ListErrFn) error {
+	user, _ := ctx.Value("user").(*model.User)
+	// the folders whose remaining objs should not be walked
+	skipped := make(map[string]struct{})
+	isSkipped := func(p string) bool {
+		for ; p != name && p != "/"; p = path.Dir(p) {
+			if _, ok := skipped[p]; ok {
+				return true
+			}
+		}
+		_, ok := skipped[name]
+		return ok
+	}
+	var walkFnErr error
+	err := op.ListR(ctx, storage, actualPath, model.ListArgs{ReqPath: name}, func(parent string, objs []model.Obj) error {
+		parent = path.Join(name, strings.TrimPrefix(parent, actualPath))
+		if depth > 0 && parent != name && strings.Count(strings.TrimPrefix(parent, utils.PathAddSeparatorSuffix(name)), "/") >= depth-1 {
+			return nil
+		}
+		if isSkipped(parent) {
+			return nil
+		}
+		if user != nil && !user.CanSeeHides() {
+			meta, _ := op.GetNearestMeta(parent)
+			if whetherHide(user, meta, parent) {
+				om := model.NewObjMerge()
+				om.InitHideReg(meta.Hide)
+				shown := om.Merge(objs)
+				names := make(map[string]struct{}, len(shown))
+				for _, obj := range shown {
+					names[obj.GetName()] = struct{}{}
+				}
+				for _, obj := range objs {
+					// the objs in hidden folders are hidden too
+					if _, ok := names[obj.GetName()]; !ok && obj.IsDir() {
+						skipped[path.Join(parent, obj.GetName())] = struct{}{}
+					}
+				}
+				objs = shown
+			}
+		}
+		for _, obj := range objs {
+			objPath := path.Join(parent, obj.GetName())
+			walkFnErr = walkFn(objPath, obj)
+			if walkFnErr == filepath.SkipDir {
+				walkFnErr = nil
+				if obj.IsDir() {
+					skipped[objPath] = struct{}{}
+					continue
+				}
+				skipped[parent] = struct{}{}
+				return nil
+			}
+			if walkFnErr != nil {
+				return walkFnErr
+			}
+		}
+		return nil
+	})
+	if walkFnErr != nil {
+		return walkFnErr
+	}
+	if err != nil {
+		// the sub tree may be walked partly, it's skipped like the folder WalkFS failed to list
+		log.Warnf("failed list %s recursively: %+v", name, err)
+		for _, fn := range listErrFn {
+			fn(name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package journal
+
+import (
+	"container/list"
+	stdpath "path"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/cache"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+var purgeCron *cron.Cron
+
+// retention returns how long the changes are kept, 0 means the journal is disabled
+func retention() time.Duration {
+	return time.Duration(setting.GetInt(conf.ChangeJournalRetention, 168)) * time.Hour
+}
+
+func Enabled() bool {
+	return retention() > 0
+}
+
+// InitCron purges the expired changes every hour
+func InitCron() {
+	if purgeCron != nil {
+		purgeCron.Stop()
+	}
+	purgeCron = cron.NewCron(time.Hour)
+	purgeCron.Do(func() {
+		r := retention()
+		if r <= 0 {
+			return
+		}
+		n, err := db.DeleteChangesBefore(time.Now().Add(-r))
+		if err != nil {
+			log.Errorf("failed purge change journal: %+v", err)
+			return
+		}
+		log.Debugf("purged %d changes from journal", n)
+	})
+}
+
+// Epoch returns the ID of the last disable mark, the cursors before it are expired
+// as the changes made while the journal is disabled are missing
+func Epoch() (uint, error) {
+	return db.GetLastChangeID(model.ChangeDisable)
+}
+
+// disable marks the journal is disabled, unless the last change is the mark already
+func disable() error {
+	_, last, err := db.GetChangeIDRange()
+	if err != nil {
+		return err
+	}
+	epoch, err := Epoch()
+	if err != nil {
+		return err
+	}
+	if last > 0 && epoch == last {
+		return nil
+	}
+	return db.CreateChange(&model.Change{Type: model.ChangeDisable})
+}
+
+func record(typ, path, dstPath string) {
+	if !Enabled() {
+		return
+	}
+	// the folders are listed again after the change, so their snapshots are dropped to not record it twice
+	delSnapshot(stdpath.Dir(path))
+	if dstPath != "" {
+		delSnapshot(stdpath.Dir(dstPath))
+	}
+	c := &model.Change{Type: typ, Path: path, DstPath: dstPath}
+	if err := db.CreateChange(c); err != nil {
+		log.Errorf("failed record change %s of %s: %+v", typ, path, err)
+	}
+}
+
+// Created records the object of the path is created or overwritten
+func Created(path string) {
+	record(model.ChangeCreate, utils.FixAndCleanPath(path), "")
+}
+
+func Deleted(path string) {
+	record(model.ChangeDelete, utils.FixAndCleanPath(path), "")
+}
+
+// Moved records the object is moved or renamed from srcPath to dstPath
+func Moved(srcPath, dstPath string) {
+	record(model.ChangeMove, utils.FixAndCleanPath(srcPath), utils.FixAndCleanPath(dstPath))
+}
+
+// objState is what a change of the object is detected by
+type objState struct {
+	IsDir    bool
+	Size     int64
+	Modified int64
+}
+
+const (
+	// snapshotTTL is how long the snapshot of a folder is kept, the changes made outside alist
+	// are not found if the folder is not listed again in time
+	snapshotTTL = time.Hour
+	// maxSnapshots bounds the snapshots kept by an instance, the least recently listed are dropped
+	maxSnapshots = 10000
+)
+
+// snapshots are the objects of the folders listed last time, the changes made outside alist
+// are found by comparing them with the objects listed again. They're kept by each instance,
+// only the drops after the changes made by alist are shared.
+var (
+	snapshots    = cache.NewLocal[map[string]objState]("journal_snapshot", 16)
+	snapshotKeys = newKeyLRU(maxSnapshots)
+)
+
+func setSnapshot(parent string, now map[string]objState) {
+	snapshots.Set(parent, now, snapshotTTL)
+	if evicted := snapshotKeys.touch(parent); len(evicted) > 0 {
+		snapshots.Drop(evicted...)
+	}
+}
+
+func delSnapshot(parent string) {
+	snapshots.Del(parent)
+	snapshotKeys.remove(parent)
+}
+
+// keyLRU keeps the keys in the order of use, at most size of them
+type keyLRU struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	keys  map[string]*list.Element
+}
+
+func newKeyLRU(size int) *keyLRU {
+	return &keyLRU{size: size, order: list.New(), keys: make(map[string]*list.Element)}
+}
+
+// touch marks the key as the most recently used, it returns the keys evicted
+func (l *keyLRU) touch(key string) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.keys[key]; ok {
+		l.order.MoveToFront(e)
+		return nil
+	}
+	l.keys[key] = l.order.PushFront(key)
+	var evicted []string
+	for l.order.Len() > l.size {
+		e := l.order.Back()
+		l.order.Remove(e)
+		k := e.Value.(string)
+		delete(l.keys, k)
+		evicted = append(evicted, k)
+	}
+	return evicted
+}
+
+func (l *keyLRU) remove(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.keys[key]; ok {
+		l.order.Remove(e)
+		delete(l.keys, key)
+	}
+}
+
+func update(parent string, objs []model.Obj) {
+	if !Enabled() {
+		return
+	}
+	now := make(map[string]objState, len(objs))
+	for _, obj := range objs {
+		now[obj.GetName()] = objState{IsDir: obj.IsDir(), Size: obj.GetSize(), Modified: obj.ModTime().UnixNano()}
+	}
+	old, ok := snapshots.Get(parent)
+	setSnapshot(parent, now)
+	if !ok {
+		return
+	}
+	for _, changed := range diff(old, now) {
+		for _, name := range changed.names {
+			c := &model.Change{Type: changed.typ, Path: stdpath.Join(parent, name)}
+			if err := db.CreateChange(c); err != nil {
+				log.Errorf("failed record change %s of %s: %+v", c.Type, c.Path, err)
+				return
+			}
+		}
+	}
+}
+
+type changedNames struct {
+	typ   string
+	names []string
+}
+
+// diff returns the names deleted, created and updated in order,
+// the folders are not updated as their modified time changes with the children
+func diff(old, now map[string]objState) []changedNames {
+	deleted := changedNames{typ: model.ChangeDelete}
+	created := changedNames{typ: model.ChangeCreate}
+	updated := changedNames{typ: model.ChangeUpdate}
+	for name := range old {
+		if _, ok := now[name]; !ok {
+			deleted.names = append(deleted.names, name)
+		}
+	}
+	for name, s := range now {
+		o, ok := old[name]
+		switch {
+		case !ok:
+			created.names = append(created.names, name)
+		case o.IsDir != s.IsDir:
+			// replaced by an object of the other type
+			deleted.names = append(deleted.names, name)
+			created.names = append(created.names, name)
+		case !s.IsDir && (o.Size != s.Size || o.Modified != s.Modified):
+			updated.names = append(updated.names, name)
+		}
+	}
+	for _, c := range []changedNames{deleted, created, updated} {
+		sort.Strings(c.names)
+	}
+	return []changedNames{deleted, created, updated}
+}
+
+func init() {
+	op.RegisterObjsUpdateHook(update)
+	op.RegisterSettingItemHook(conf.ChangeJournalRetention, func(item *model.SettingItem) error {
+		if hours, err := strconv.Atoi(item.Value); err == nil && hours <= 0 {
+			return disable()
+		}
+		return nil
+	})
+}
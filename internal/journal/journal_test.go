@@ -0,0 +1,56 @@
+package journal
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestDiff(t *testing.T) {
+	old := map[string]objState{
+		"a.txt":  {Size: 1, Modified: 1},
+		"b.txt":  {Size: 1, Modified: 1},
+		"c.txt":  {Size: 1, Modified: 1},
+		"dir":    {IsDir: true, Modified: 1},
+		"dir2":   {IsDir: true, Modified: 1},
+		"swap":   {Size: 1, Modified: 1},
+		"same":   {Size: 2, Modified: 2},
+		"gone":   {IsDir: true},
+		"resize": {Size: 1, Modified: 1},
+	}
+	now := map[string]objState{
+		"a.txt":  {Size: 1, Modified: 1},
+		"b.txt":  {Size: 1, Modified: 2},
+		"dir":    {IsDir: true, Modified: 2},
+		"dir2":   {IsDir: true, Modified: 1},
+		"swap":   {IsDir: true},
+		"same":   {Size: 2, Modified: 2},
+		"new":    {Size: 3},
+		"resize": {Size: 2, Modified: 1},
+	}
+	expect := []changedNames{
+		{typ: model.ChangeDelete, names: []string{"c.txt", "gone", "swap"}},
+		{typ: model.ChangeCreate, names: []string{"new", "swap"}},
+		{typ: model.ChangeUpdate, names: []string{"b.txt", "resize"}},
+	}
+	if got := diff(old, now); !reflect.DeepEqual(got, expect) {
+		t.Errorf("expect %+v, got %+v", expect, got)
+	}
+}
+
+func TestKeyLRU(t *testing.T) {
+	l := newKeyLRU(2)
+	for _, key := range []string{"/a", "/b", "/a"} {
+		if evicted := l.touch(key); len(evicted) != 0 {
+			t.Errorf("expect nothing evicted by %s, got %v", key, evicted)
+		}
+	}
+	if evicted := l.touch("/c"); !reflect.DeepEqual(evicted, []string{"/b"}) {
+		t.Errorf("expect the least recently used evicted, got %v", evicted)
+	}
+	l.remove("/a")
+	if evicted := l.touch("/d"); len(evicted) != 0 {
+		t.Errorf("expect nothing evicted after removed, got %v", evicted)
+	}
+}
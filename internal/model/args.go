@@ -16,6 +16,28 @@ type ListArgs struct {
 	Refresh           bool
 }
 
+type ListPageArgs struct {
+	ReqPath string
+	// Token returned with the previous page, empty for the first page
+	Token   string
+	Limit   int
+	Refresh bool
+}
+
+type UploadSessionArgs struct {
+	Name     string
+	Size     int64
+	Mimetype string
+}
+
+type UploadCompleteArgs struct {
+	Name string
+	Size int64
+	// ID of the UploadSession
+	ID    string
+	Parts []UploadPart
+}
+
 type LinkArgs struct {
 	IP      string
 	Header  http.Header
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+const (
+	ChangeCreate = "create"
+	ChangeUpdate = "update"
+	ChangeDelete = "delete"
+	ChangeMove   = "move"
+	// ChangeDisable marks the journal is disabled, the changes are not recorded after it until enabled again
+	ChangeDisable = "disable"
+)
+
+// Change is an event of the change journal, the ID is the cursor of the sync clients
+type Change struct {
+	ID uint `json:"id" gorm:"primaryKey"`
+	// create also covers overwriting an existing object, as it's unknown to some storages
+	Type string `json:"type"`
+	Path string `json:"path" gorm:"index"`
+	// DstPath is the new path of the moved or renamed object
+	DstPath   string    `json:"dst_path,omitempty" gorm:"index"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import "strings"
+
+type IndexJob struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Path string `json:"path" gorm:"unique" binding:"required"`
+	// interval in minutes, 0 means the job only runs manually
+	Interval int `json:"interval"`
+	// 0 means use max_index_depth setting
+	MaxDepth int `json:"max_depth"`
+	// paths to ignore, split by \n
+	IgnorePaths string `json:"ignore_paths"`
+	// list from storage instead of cache
+	Refresh  bool          `json:"refresh"`
+	Disabled bool          `json:"disabled"`
+	Progress IndexProgress `json:"progress" gorm:"embedded;embeddedPrefix:progress_"`
+}
+
+func (j *IndexJob) GetIgnorePaths() []string {
+	var paths []string
+	for _, p := range strings.Split(j.IgnorePaths, "\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
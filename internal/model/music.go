@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// MusicTrack is an audio file scanned into the music library
+type MusicTrack struct {
+	ID       uint      `json:"id" gorm:"primaryKey"`
+	Path     string    `json:"path" gorm:"unique"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	Title    string    `json:"title"`
+	Artist   string    `json:"artist"`
+	Album    string    `json:"album" gorm:"index"`
+	// AlbumArtist is the artist of the album, it's the artist of the track if not tagged
+	AlbumArtist string `json:"album_artist" gorm:"index"`
+	Track       int    `json:"track"`
+	Disc        int    `json:"disc"`
+	Year        int    `json:"year"`
+	Genre       string `json:"genre"`
+	// Duration in seconds, 0 means unknown
+	Duration float64 `json:"duration"`
+	// Cover is the file name of the embedded cover saved, empty if there is no cover
+	Cover     string    `json:"-"`
+	ScannedAt time.Time `json:"-"`
+}
+
+type MusicArtist struct {
+	Name   string `json:"name"`
+	Albums int64  `json:"albums"`
+	Tracks int64  `json:"tracks"`
+}
+
+type MusicAlbum struct {
+	Name   string `json:"name"`
+	Artist string `json:"artist"`
+	Year   int    `json:"year"`
+	Tracks int64  `json:"tracks"`
+	// CoverPath is the path of a track of the album which has the cover
+	CoverPath string `json:"-"`
+}
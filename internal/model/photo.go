@@ -0,0 +1,76 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// PhotoMeta is the exif of an image file, it's stored by the parent and name like SearchNode
+type PhotoMeta struct {
+	ID       uint      `json:"-" gorm:"primaryKey"`
+	Parent   string    `json:"parent" gorm:"uniqueIndex:idx_photo_meta_path"`
+	Name     string    `json:"name" gorm:"uniqueIndex:idx_photo_meta_path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	// TakenAt is the capture date in exif, it's the modified time if not found
+	TakenAt     time.Time `json:"taken_at" gorm:"index"`
+	Make        string    `json:"make"`
+	Model       string    `json:"model"`
+	Orientation int       `json:"orientation"`
+	// the location is nil if there is no gps info
+	Latitude  *float64  `json:"latitude"`
+	Longitude *float64  `json:"longitude"`
+	ScannedAt time.Time `json:"-"`
+}
+
+type PhotoCamera struct {
+	Make   string `json:"make"`
+	Model  string `json:"model"`
+	Photos int    `json:"photos"`
+}
+
+type PhotoReq struct {
+	Parent string `json:"parent"`
+	// capture date range, nil means no limit
+	TakenAfter  *time.Time `json:"taken_after"`
+	TakenBefore *time.Time `json:"taken_before"`
+	// Camera matches the make or model
+	Camera string `json:"camera"`
+	// location bounding box, all nil means no limit
+	MinLatitude  *float64 `json:"min_latitude"`
+	MaxLatitude  *float64 `json:"max_latitude"`
+	MinLongitude *float64 `json:"min_longitude"`
+	MaxLongitude *float64 `json:"max_longitude"`
+	// asc or desc by the capture date, desc by default
+	OrderDirection string `json:"order_direction"`
+	PageReq
+}
+
+// HasBox reports whether the photos are filtered by location
+func (p *PhotoReq) HasBox() bool {
+	return p.MinLatitude != nil || p.MaxLatitude != nil || p.MinLongitude != nil || p.MaxLongitude != nil
+}
+
+func (p *PhotoReq) Validate() error {
+	if p.Page < 1 {
+		return fmt.Errorf("page can't < 1")
+	}
+	if p.PerPage < 1 {
+		return fmt.Errorf("per_page can't < 1")
+	}
+	if p.TakenAfter != nil && p.TakenBefore != nil && p.TakenAfter.After(*p.TakenBefore) {
+		return fmt.Errorf("taken_after can't be after taken_before")
+	}
+	if p.HasBox() && (p.MinLatitude == nil || p.MaxLatitude == nil || p.MinLongitude == nil || p.MaxLongitude == nil) {
+		return fmt.Errorf("the location box needs all of min_latitude, max_latitude, min_longitude and max_longitude")
+	}
+	if p.HasBox() && *p.MinLatitude > *p.MaxLatitude {
+		return fmt.Errorf("min_latitude can't > max_latitude")
+	}
+	switch p.OrderDirection {
+	case "", "asc", "desc":
+	default:
+		return fmt.Errorf("not support order_direction: %s", p.OrderDirection)
+	}
+	return nil
+}
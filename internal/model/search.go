@@ -17,14 +17,35 @@ type SearchReq struct {
 	Keywords string `json:"keywords"`
 	// 0 for all, 1 for dir, 2 for file
 	Scope int `json:"scope"`
+	// size range in bytes, 0 means no limit
+	MinSize int64 `json:"min_size"`
+	MaxSize int64 `json:"max_size"`
+	// modified time range, nil means no limit
+	ModifiedAfter  *time.Time `json:"modified_after"`
+	ModifiedBefore *time.Time `json:"modified_before"`
+	// file types, see conf.FOLDER, conf.VIDEO...
+	FileTypes []int `json:"file_types"`
+	// lower case extensions without dot
+	Exts []string `json:"exts"`
+	// name, size or modified
+	OrderBy string `json:"order_by"`
+	// asc or desc
+	OrderDirection string `json:"order_direction"`
+	// Tag limits the results to the objects with the tag visible to the user
+	Tag string `json:"tag"`
+	// TaggedPaths are the paths with the tag resolved by the search package
+	TaggedPaths []string `json:"-"`
 	PageReq
 }
 
 type SearchNode struct {
-	Parent string `json:"parent" gorm:"index"`
-	Name   string `json:"name"`
-	IsDir  bool   `json:"is_dir"`
-	Size   int64  `json:"size"`
+	Parent   string    `json:"parent" gorm:"index"`
+	Name     string    `json:"name"`
+	IsDir    bool      `json:"is_dir"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	FileType int       `json:"file_type"`
+	Ext      string    `json:"ext"`
 }
 
 func (p *SearchReq) Validate() error {
@@ -34,6 +55,25 @@ func (p *SearchReq) Validate() error {
 	if p.PerPage < 1 {
 		return fmt.Errorf("per_page can't < 1")
 	}
+	if p.MinSize < 0 || p.MaxSize < 0 {
+		return fmt.Errorf("size can't < 0")
+	}
+	if p.MaxSize != 0 && p.MinSize > p.MaxSize {
+		return fmt.Errorf("min_size can't > max_size")
+	}
+	if p.ModifiedAfter != nil && p.ModifiedBefore != nil && p.ModifiedAfter.After(*p.ModifiedBefore) {
+		return fmt.Errorf("modified_after can't be after modified_before")
+	}
+	switch p.OrderBy {
+	case "", "name", "size", "modified":
+	default:
+		return fmt.Errorf("not support order_by: %s", p.OrderBy)
+	}
+	switch p.OrderDirection {
+	case "", "asc", "desc":
+	default:
+		return fmt.Errorf("not support order_direction: %s", p.OrderDirection)
+	}
 	return nil
 }
 
@@ -8,6 +8,7 @@ type Storage struct {
 	Order           int       `json:"order"`                                       // use to sort
 	Driver          string    `json:"driver"`                                      // driver used
 	CacheExpiration int       `json:"cache_expiration"`                            // cache expire time
+	NoDiskCache     bool      `json:"no_disk_cache"`                               // not persist the list cache to disk
 	Status          string    `json:"status"`
 	Addition        string    `json:"addition" gorm:"type:text"` // Additional information, defined in the corresponding driver
 	Remark          string    `json:"remark"`
@@ -16,6 +17,7 @@ type Storage struct {
 	EnableSign      bool      `json:"enable_sign"`
 	Sort
 	Proxy
+	Balance
 }
 
 type Sort struct {
@@ -31,6 +33,17 @@ type Proxy struct {
 	DownProxyUrl string `json:"down_proxy_url"`
 }
 
+type Balance struct {
+	// BalanceMode of the balance group, only the mode of the main storage is used
+	BalanceMode   string `json:"balance_mode"`
+	BalanceWeight int    `json:"balance_weight"`
+}
+
+type StorageSpace struct {
+	Total int64 `json:"total"`
+	Free  int64 `json:"free"`
+}
+
 func (s *Storage) GetStorage() *Storage {
 	return s
 }
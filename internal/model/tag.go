@@ -0,0 +1,39 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FavoriteTag is the reserved tag of the favorites, it's always owned by a user
+const FavoriteTag = "favorite"
+
+// Tag marks the object of the path, it's shared with all users if UserID is 0
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Path      string    `json:"path" gorm:"uniqueIndex:idx_tag_path_name_user"`
+	Name      string    `json:"name" gorm:"uniqueIndex:idx_tag_path_name_user;index"`
+	UserID    uint      `json:"user_id" gorm:"uniqueIndex:idx_tag_path_name_user"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (t *Tag) Shared() bool {
+	return t.UserID == 0
+}
+
+type TagCount struct {
+	Name    string `json:"name"`
+	Shared  bool   `json:"shared"`
+	Objects int    `json:"objects"`
+}
+
+func ValidateTagName(name string) error {
+	if name == "" || strings.TrimSpace(name) != name {
+		return fmt.Errorf("invalid tag name: %q", name)
+	}
+	if len(name) > 64 {
+		return fmt.Errorf("tag name can't be longer than 64")
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// UploadSession tells the client how to upload a file to the storage directly.
+// The file is split into parts of PartSize, and part i is sent to URLs[i],
+// or all parts are sent to URLs[0] with a Content-Range header if RangeHeader is set.
+type UploadSession struct {
+	// ID is kept by the server and given back to the driver on completion
+	ID          string            `json:"-"`
+	Method      string            `json:"method"`
+	URLs        []string          `json:"urls"`
+	PartSize    int64             `json:"part_size"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	RangeHeader bool              `json:"range_header"`
+	Expires     time.Time         `json:"expires"`
+}
+
+// UploadPart is a part uploaded by the client, ETag is the header of the response
+type UploadPart struct {
+	Number int    `json:"number"`
+	ETag   string `json:"etag"`
+}
@@ -0,0 +1,11 @@
+package model
+
+type WarmJob struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Path string `json:"path" gorm:"unique" binding:"required"`
+	// depth of folders to warm, 1 means only the path itself
+	Depth int `json:"depth"`
+	// interval in minutes, 0 means before the list cache of the storage expires
+	Interval int  `json:"interval"`
+	Disabled bool `json:"disabled"`
+}
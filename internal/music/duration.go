@@ -0,0 +1,143 @@
+package music
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/dhowden/tag"
+)
+
+// the tags don't contain the duration, so it's got from the headers of the audio stream.
+// Only flac and mp3 are supported, the duration of other types is unknown.
+func getDuration(r io.ReadSeeker, fileType tag.FileType, size int64) float64 {
+	switch fileType {
+	case tag.FLAC:
+		return flacDuration(r)
+	case tag.MP3:
+		return mp3Duration(r, size)
+	}
+	return 0
+}
+
+// flacDuration reads the STREAMINFO block, which is always the first metadata block
+func flacDuration(r io.ReadSeeker) float64 {
+	buf := make([]byte, 4+4+34)
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return 0
+	}
+	if _, err := io.ReadFull(r, buf); err != nil || string(buf[:4]) != "fLaC" || buf[4]&0x7f != 0 {
+		return 0
+	}
+	info := buf[8:]
+	sampleRate := uint32(info[10])<<12 | uint32(info[11])<<4 | uint32(info[12])>>4
+	samples := uint64(info[13]&0x0f)<<32 | uint64(binary.BigEndian.Uint32(info[14:18]))
+	if sampleRate == 0 {
+		return 0
+	}
+	return float64(samples) / float64(sampleRate)
+}
+
+var (
+	mp3Bitrates = [2][16]int{
+		// MPEG 1 layer 3
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+		// MPEG 2 and 2.5 layer 3
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+	}
+	mp3SampleRates = [3]int{44100, 48000, 32000}
+)
+
+// mp3FrameSearchSize is how many bytes after the id3v2 tag are searched for the first frame
+const mp3FrameSearchSize = 16 * 1024
+
+// mp3Duration gets the duration by the frame count in the Xing/Info or VBRI header of the first frame,
+// or estimates it by the bitrate of the first frame if there is no such header, as a CBR file.
+func mp3Duration(r io.ReadSeeker, size int64) float64 {
+	start, err := id3v2Size(r)
+	if err != nil {
+		return 0
+	}
+	if _, err = r.Seek(start, io.SeekStart); err != nil {
+		return 0
+	}
+	buf := make([]byte, mp3FrameSearchSize)
+	n, _ := io.ReadFull(r, buf)
+	buf = buf[:n]
+	for i := 0; i+4 <= len(buf); i++ {
+		if buf[i] != 0xff || buf[i+1]&0xe0 != 0xe0 {
+			continue
+		}
+		version := (buf[i+1] >> 3) & 0x03 // 0: MPEG 2.5, 2: MPEG 2, 3: MPEG 1
+		layer := (buf[i+1] >> 1) & 0x03   // 1: layer 3
+		bitrateIndex := buf[i+2] >> 4
+		sampleRateIndex := (buf[i+2] >> 2) & 0x03
+		if version == 1 || layer != 1 || bitrateIndex == 0 || bitrateIndex == 15 || sampleRateIndex == 3 {
+			continue
+		}
+		mono := buf[i+3]>>6 == 3
+		table, samplesPerFrame := 0, 1152
+		sampleRate := mp3SampleRates[sampleRateIndex]
+		if version != 3 {
+			table, samplesPerFrame = 1, 576
+			sampleRate /= 2
+			if version == 0 {
+				sampleRate /= 2
+			}
+		}
+		frame := buf[i:]
+		if frames := vbrFrames(frame, version == 3, mono); frames > 0 {
+			return float64(frames) * float64(samplesPerFrame) / float64(sampleRate)
+		}
+		bitrate := mp3Bitrates[table][bitrateIndex] * 1000
+		return float64(size-start-int64(i)) * 8 / float64(bitrate)
+	}
+	return 0
+}
+
+// vbrFrames returns the frame count in the Xing/Info or VBRI header of the frame, 0 if not found
+func vbrFrames(frame []byte, mpeg1, mono bool) uint32 {
+	// the Xing header is after the side info
+	offset := 4 + 17
+	if mpeg1 && !mono {
+		offset = 4 + 32
+	} else if !mpeg1 && mono {
+		offset = 4 + 9
+	}
+	if len(frame) >= offset+12 {
+		id := frame[offset : offset+4]
+		if bytes.Equal(id, []byte("Xing")) || bytes.Equal(id, []byte("Info")) {
+			flags := binary.BigEndian.Uint32(frame[offset+4:])
+			if flags&0x01 != 0 {
+				return binary.BigEndian.Uint32(frame[offset+8:])
+			}
+			return 0
+		}
+	}
+	// the VBRI header is always 32 bytes after the frame header
+	if len(frame) >= 4+32+18 && bytes.Equal(frame[36:40], []byte("VBRI")) {
+		return binary.BigEndian.Uint32(frame[36+14:])
+	}
+	return 0
+}
+
+// id3v2Size returns the size of the id3v2 tag at the start of the file, 0 if there is no tag
+func id3v2Size(r io.ReadSeeker) (int64, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, err
+	}
+	if string(header[:3]) != "ID3" {
+		return 0, nil
+	}
+	// the size is a syncsafe integer, which doesn't contain the header and footer
+	size := int64(header[6]&0x7f)<<21 | int64(header[7]&0x7f)<<14 | int64(header[8]&0x7f)<<7 | int64(header[9]&0x7f)
+	size += 10
+	if header[5]&0x10 != 0 {
+		size += 10
+	}
+	return size, nil
+}
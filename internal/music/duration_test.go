@@ -0,0 +1,42 @@
+package music
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/dhowden/tag"
+)
+
+func TestFlacDuration(t *testing.T) {
+	data := make([]byte, 4+4+34)
+	copy(data, "fLaC")
+	info := data[8:]
+	// 44100 Hz, 441000 samples
+	info[10], info[11], info[12] = 44100>>12, (44100>>4)&0xff, (44100&0x0f)<<4
+	binary.BigEndian.PutUint32(info[14:], 441000)
+	r := bytes.NewReader(data)
+	if d := getDuration(r, tag.FLAC, int64(len(data))); d != 10 {
+		t.Errorf("expect 10s, got %v", d)
+	}
+}
+
+func TestMp3Duration(t *testing.T) {
+	// id3v2 tag of 100 bytes, and a MPEG 1 layer 3 frame of 128 kbps, 44100 Hz, stereo
+	data := make([]byte, 10+100+160000)
+	copy(data, "ID3")
+	data[3], data[9] = 3, 100
+	copy(data[110:], []byte{0xff, 0xfb, 0x90, 0x00})
+	r := bytes.NewReader(data)
+	if d := getDuration(r, tag.MP3, int64(len(data))); d != 10 {
+		t.Errorf("expect 10s of cbr, got %v", d)
+	}
+	// Xing header with 383 frames
+	copy(data[110+36:], "Xing")
+	binary.BigEndian.PutUint32(data[110+40:], 1)
+	binary.BigEndian.PutUint32(data[110+44:], 383)
+	if d := getDuration(r, tag.MP3, int64(len(data))); math.Abs(d-383*1152/44100.0) > 1e-9 {
+		t.Errorf("expect duration by frames, got %v", d)
+	}
+}
@@ -0,0 +1,280 @@
+package music
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/cmd/flags"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/dhowden/tag"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// maxErrors of a scan kept in the status
+	maxErrors = 20
+	// scanDepth is the max depth of folders scanned under a music path
+	scanDepth = 32
+)
+
+type Status struct {
+	Running     bool       `json:"running"`
+	LastRunTime *time.Time `json:"last_run_time"`
+	LastCost    string     `json:"last_cost"`
+	TrackCount  int        `json:"track_count"`
+	Errors      []string   `json:"errors"`
+}
+
+var (
+	mu         sync.Mutex
+	status     Status
+	cancelScan context.CancelFunc
+	scanCron   *cron.Cron
+)
+
+// GetStatus returns the status of the last or current scan
+func GetStatus() Status {
+	mu.Lock()
+	defer mu.Unlock()
+	s := status
+	s.Errors = append([]string(nil), status.Errors...)
+	return s
+}
+
+func addError(err error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if len(status.Errors) < maxErrors {
+		status.Errors = append(status.Errors, err.Error())
+	}
+}
+
+func addTrack() {
+	mu.Lock()
+	defer mu.Unlock()
+	status.TrackCount++
+}
+
+// InitCron checks every minute whether the library should be scanned by the interval setting
+func InitCron() {
+	if scanCron != nil {
+		scanCron.Stop()
+	}
+	scanCron = cron.NewCron(time.Minute)
+	scanCron.Do(func() {
+		interval := setting.GetInt(conf.MusicScanInterval, 0)
+		if interval <= 0 {
+			return
+		}
+		s := GetStatus()
+		if !s.Running && (s.LastRunTime == nil || time.Since(*s.LastRunTime) >= time.Duration(interval)*time.Hour) {
+			go Scan(context.Background())
+		}
+	})
+}
+
+// Paths returns the paths scanned into the library
+func Paths() []string {
+	var paths []string
+	for _, p := range strings.Split(setting.GetStr(conf.MusicPaths), "\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, utils.FixAndCleanPath(p))
+		}
+	}
+	return paths
+}
+
+// Scan reads the tags of the audio files in the music paths, the unchanged files are skipped.
+// It returns immediately if a scan is running.
+func Scan(ctx context.Context) {
+	mu.Lock()
+	if status.Running {
+		mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	start := time.Now()
+	cancelScan = cancel
+	status = Status{Running: true, LastRunTime: &start}
+	mu.Unlock()
+
+	for _, path := range Paths() {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := scanPath(ctx, path, start); err != nil {
+			addError(fmt.Errorf("%s: %w", path, err))
+		}
+	}
+
+	mu.Lock()
+	status.Running = false
+	status.LastCost = time.Since(start).String()
+	cancelScan = nil
+	mu.Unlock()
+	log.Debugf("music scan done, %d tracks", GetStatus().TrackCount)
+}
+
+// Stop cancels the running scan
+func Stop() {
+	mu.Lock()
+	defer mu.Unlock()
+	if cancelScan != nil {
+		cancelScan()
+	}
+}
+
+func scanPath(ctx context.Context, path string, start time.Time) error {
+	root, err := fs.Get(ctx, path, &fs.GetArgs{})
+	if err != nil {
+		return err
+	}
+	err = fs.WalkFS(ctx, scanDepth, path, root, func(reqPath string, info model.Obj) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if info.IsDir() || utils.GetFileType(info.GetName()) != conf.AUDIO {
+			return nil
+		}
+		if err := scanFile(ctx, reqPath, info, start); err != nil {
+			addError(fmt.Errorf("%s: %w", reqPath, err))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// the tracks not found are removed only if the path is scanned completely
+	return db.DeleteMusicTracksScannedBefore(path, start)
+}
+
+func scanFile(ctx context.Context, path string, obj model.Obj, start time.Time) error {
+	old, err := db.GetMusicTrackByPath(path)
+	if err == nil && old.Size == obj.GetSize() && old.Modified.Unix() == obj.ModTime().Unix() {
+		addTrack()
+		return db.TouchMusicTrack(old.ID, start)
+	}
+	track, err := readTrack(ctx, path, obj)
+	if err != nil {
+		return err
+	}
+	if old != nil {
+		track.ID = old.ID
+	}
+	track.ScannedAt = start
+	addTrack()
+	return db.SaveMusicTrack(track)
+}
+
+// readTrack reads the tags of the file by ranged reads
+func readTrack(ctx context.Context, path string, obj model.Obj) (*model.MusicTrack, error) {
+	track := &model.MusicTrack{
+		Path:     path,
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		Title:    strings.TrimSuffix(obj.GetName(), stdpath.Ext(obj.GetName())),
+	}
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage")
+	}
+	link, _, err := op.Link(ctx, storage, actualPath, model.LinkArgs{
+		Header: http.Header{},
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get link")
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{
+		Obj: obj,
+		Ctx: ctx,
+	}, link)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get stream")
+	}
+	defer ss.Close()
+	r := stream.NewRangeReadSeeker(ss, obj.GetSize())
+	defer r.Close()
+	fileType := tag.FileType(strings.ToUpper(utils.Ext(obj.GetName())))
+	m, err := tag.ReadFrom(r)
+	if err == nil {
+		if m.FileType() != tag.UnknownFileType {
+			fileType = m.FileType()
+		}
+		if m.Title() != "" {
+			track.Title = m.Title()
+		}
+		track.Artist = m.Artist()
+		track.Album = m.Album()
+		track.AlbumArtist = m.AlbumArtist()
+		track.Track, _ = m.Track()
+		track.Disc, _ = m.Disc()
+		track.Year = m.Year()
+		track.Genre = m.Genre()
+		if p := m.Picture(); p != nil && len(p.Data) > 0 {
+			track.Cover, err = saveCover(track, p)
+			if err != nil {
+				log.Warnf("failed save cover of %s: %+v", path, err)
+			}
+		}
+	} else if !errors.Is(err, tag.ErrNoTagsFound) {
+		log.Warnf("failed read tags of %s: %+v", path, err)
+	}
+	if track.AlbumArtist == "" {
+		track.AlbumArtist = track.Artist
+	}
+	track.Duration = getDuration(r, fileType, obj.GetSize())
+	return track, nil
+}
+
+func coverDir() string {
+	return filepath.Join(flags.DataDir, "music_covers")
+}
+
+// saveCover saves the cover in the data dir, the tracks of an album share the same cover file
+func saveCover(track *model.MusicTrack, p *tag.Picture) (string, error) {
+	key := track.Path
+	if track.Album != "" {
+		key = track.AlbumArtist + "\n" + track.Album
+		if track.AlbumArtist == "" {
+			key = track.Artist + "\n" + track.Album
+		}
+	}
+	sum := md5.Sum([]byte(key))
+	ext := strings.ToLower(p.Ext)
+	if ext == "" {
+		ext = "jpg"
+	}
+	name := hex.EncodeToString(sum[:]) + "." + ext
+	if err := os.MkdirAll(coverDir(), 0777); err != nil {
+		return "", err
+	}
+	return name, os.WriteFile(filepath.Join(coverDir(), name), p.Data, 0666)
+}
+
+// GetCover returns the file of the embedded cover of the track, empty if it's not in the library or has no cover
+func GetCover(path string) string {
+	track, err := db.GetMusicTrackByPath(utils.FixAndCleanPath(path))
+	if err != nil || track.Cover == "" {
+		return ""
+	}
+	return filepath.Join(coverDir(), track.Cover)
+}
@@ -0,0 +1,210 @@
+package op
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	pkgerr "github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	BalanceRoundRobin   = "round_robin"
+	BalanceWeighted     = "weighted"
+	BalanceLeastLatency = "least_latency"
+)
+
+const (
+	// a member is ejected after so many failures in a row
+	balanceMaxFails = 3
+	// or when its error rate is too high
+	balanceMaxErrRate = 0.5
+	balanceMinSamples = 10
+	balanceCoolDown   = time.Minute
+	balanceEWMAAlpha  = 0.2
+	// least_latency picks a member in turn every so many times, so latency of other members is refreshed
+	balanceProbeInterval = 16
+)
+
+type storageHealth struct {
+	mu           sync.Mutex
+	fails        int
+	samples      int
+	errRate      float64
+	latency      time.Duration
+	ejectedUntil time.Time
+}
+
+func (h *storageHealth) report(latency time.Duration, failed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.samples++
+	errVal := 0.0
+	if failed {
+		errVal = 1
+		h.fails++
+	} else {
+		h.fails = 0
+		if h.latency == 0 {
+			h.latency = latency
+		} else {
+			h.latency = time.Duration(balanceEWMAAlpha*float64(latency) + (1-balanceEWMAAlpha)*float64(h.latency))
+		}
+	}
+	h.errRate = balanceEWMAAlpha*errVal + (1-balanceEWMAAlpha)*h.errRate
+	if failed && (h.fails >= balanceMaxFails || (h.samples >= balanceMinSamples && h.errRate > balanceMaxErrRate)) {
+		h.ejectedUntil = time.Now().Add(balanceCoolDown)
+	}
+}
+
+func (h *storageHealth) healthy() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return time.Now().After(h.ejectedUntil)
+}
+
+func (h *storageHealth) getLatency() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.latency
+}
+
+var (
+	balanceMap    generic_sync.MapOf[string, int]
+	balanceHealth generic_sync.MapOf[string, *storageHealth]
+)
+
+func getHealth(mountPath string) *storageHealth {
+	h, _ := balanceHealth.LoadOrStore(mountPath, &storageHealth{})
+	return h
+}
+
+func resetHealth(mountPath string) {
+	balanceHealth.Delete(mountPath)
+}
+
+// IsBalanceFailure reports whether the err means the storage is unhealthy,
+// so the request should be retried on another member of the balance group
+func IsBalanceFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	cause := pkgerr.Cause(err)
+	return !errs.IsObjectNotFound(err) &&
+		!pkgerr.Is(cause, errs.NotFolder) &&
+		!pkgerr.Is(cause, errs.NotFile) &&
+		!pkgerr.Is(cause, context.Canceled)
+}
+
+// reportBalance records the result of a request to the storage
+func reportBalance(storage driver.Driver, latency time.Duration, err error) {
+	if err != nil && !IsBalanceFailure(err) {
+		return
+	}
+	h := getHealth(storage.GetStorage().MountPath)
+	h.report(latency, err != nil)
+	if err != nil && !h.healthy() {
+		log.Warnf("storage %s is ejected from balance for %s: %+v", storage.GetStorage().MountPath, balanceCoolDown, err)
+	}
+}
+
+func isAvailable(storage driver.Driver) bool {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return false
+	}
+	return getHealth(storage.GetStorage().MountPath).healthy()
+}
+
+// GetBalancedStorage get storage by path
+func GetBalancedStorage(path string) driver.Driver {
+	storages := GetBalancedStorages(path)
+	if len(storages) == 0 {
+		return nil
+	}
+	return storages[0]
+}
+
+// GetBalancedStorages get all storages of the balance group by path,
+// the chosen one goes first, then the other available ones to fail over to,
+// and the unavailable ones last.
+func GetBalancedStorages(path string) []driver.Driver {
+	path = utils.FixAndCleanPath(path)
+	storages := getStoragesByPath(path)
+	if len(storages) <= 1 {
+		return storages
+	}
+	var available, unavailable []driver.Driver
+	for _, storage := range storages {
+		if isAvailable(storage) {
+			available = append(available, storage)
+		} else {
+			unavailable = append(unavailable, storage)
+		}
+	}
+	if len(available) == 0 {
+		// all members are down, just try them in turn
+		available, unavailable = storages, nil
+	}
+	virtualPath := utils.GetActualMountPath(storages[0].GetStorage().MountPath)
+	i, _ := balanceMap.LoadOrStore(virtualPath, 0)
+	i++
+	balanceMap.Store(virtualPath, i)
+	var chosen int
+	switch storages[0].GetStorage().BalanceMode {
+	case BalanceWeighted:
+		chosen = pickWeighted(available, i)
+	case BalanceLeastLatency:
+		if i%balanceProbeInterval == 0 {
+			chosen = i % len(available)
+		} else {
+			chosen = pickLeastLatency(available)
+		}
+	default:
+		chosen = i % len(available)
+	}
+	res := make([]driver.Driver, 0, len(storages))
+	for j := 0; j < len(available); j++ {
+		res = append(res, available[(chosen+j)%len(available)])
+	}
+	return append(res, unavailable...)
+}
+
+func getWeight(storage driver.Driver) int {
+	if w := storage.GetStorage().BalanceWeight; w > 0 {
+		return w
+	}
+	return 1
+}
+
+func pickWeighted(storages []driver.Driver, i int) int {
+	total := 0
+	for _, storage := range storages {
+		total += getWeight(storage)
+	}
+	n := i % total
+	for j, storage := range storages {
+		n -= getWeight(storage)
+		if n < 0 {
+			return j
+		}
+	}
+	return 0
+}
+
+func pickLeastLatency(storages []driver.Driver) int {
+	chosen := 0
+	var minLatency time.Duration = -1
+	for j, storage := range storages {
+		// members without latency yet are tried first
+		latency := getHealth(storage.GetStorage().MountPath).getLatency()
+		if minLatency < 0 || latency < minLatency {
+			chosen, minLatency = j, latency
+		}
+	}
+	return chosen
+}
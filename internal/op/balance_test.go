@@ -0,0 +1,54 @@
+package op
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/pkg/errors"
+)
+
+func TestStorageHealth(t *testing.T) {
+	h := &storageHealth{}
+	h.report(100*time.Millisecond, false)
+	for i := 0; i < balanceMaxFails-1; i++ {
+		h.report(0, true)
+	}
+	if !h.healthy() {
+		t.Errorf("expected healthy before %d failures in a row", balanceMaxFails)
+	}
+	h.report(0, true)
+	if h.healthy() {
+		t.Errorf("expected ejected after %d failures in a row", balanceMaxFails)
+	}
+	h.ejectedUntil = time.Now().Add(-time.Second)
+	if !h.healthy() {
+		t.Errorf("expected healthy after cool down")
+	}
+	h.report(0, true)
+	if h.healthy() {
+		t.Errorf("expected ejected again after failing once more")
+	}
+	if h.getLatency() != 100*time.Millisecond {
+		t.Errorf("expected latency not changed by failures, got %s", h.getLatency())
+	}
+}
+
+func TestIsBalanceFailure(t *testing.T) {
+	var tests = []struct {
+		err     error
+		failure bool
+	}{
+		{err: nil, failure: false},
+		{err: errors.WithStack(errs.ObjectNotFound), failure: false},
+		{err: errors.WithMessage(errs.NotFolder, "failed get dir"), failure: false},
+		{err: errors.Wrap(context.Canceled, "failed get link"), failure: false},
+		{err: errors.New("token expired"), failure: true},
+	}
+	for _, test := range tests {
+		if got := IsBalanceFailure(test.err); got != test.failure {
+			t.Errorf("IsBalanceFailure(%v) = %v, expected %v", test.err, got, test.failure)
+		}
+	}
+}
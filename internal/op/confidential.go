@@ -0,0 +1,210 @@
+package op
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	encryptedPrefix = "enc:v1:"
+	// ConfidentialMask replaces the confidential values in api,
+	// the old value is kept if it's sent back unchanged
+	ConfidentialMask = "******"
+)
+
+func getConfidentialFields(driverName string) []string {
+	var fields []string
+	for _, item := range driverInfoMap[driverName].Additional {
+		if item.Confidential {
+			fields = append(fields, item.Name)
+		}
+	}
+	return fields
+}
+
+func newGCM(key string) (cipher.AEAD, error) {
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func encryptValue(value, key string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	data := gcm.Seal(nonce, nonce, []byte(value), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+func decryptValue(value, key string) (string, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+	if key == "" {
+		return "", errors.New("master key is required to decrypt the storage")
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil {
+		return "", errors.Wrap(err, "invalid encrypted value")
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("invalid encrypted value")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("failed to decrypt, the master key may be wrong")
+	}
+	return string(plain), nil
+}
+
+// convertAddition applies fn to the string values of the confidential fields
+func convertAddition(driverName, addition string, fn func(name, value string) (string, error)) (string, error) {
+	fields := getConfidentialFields(driverName)
+	if len(fields) == 0 || addition == "" {
+		return addition, nil
+	}
+	var m map[string]json.RawMessage
+	if err := utils.Json.UnmarshalFromString(addition, &m); err != nil {
+		return "", errors.Wrap(err, "failed unmarshal addition")
+	}
+	for _, name := range fields {
+		raw, ok := m[name]
+		if !ok {
+			continue
+		}
+		var value string
+		if err := utils.Json.Unmarshal(raw, &value); err != nil || value == "" {
+			continue
+		}
+		value, err := fn(name, value)
+		if err != nil {
+			return "", errors.WithMessagef(err, "field %s", name)
+		}
+		if m[name], err = utils.Json.Marshal(value); err != nil {
+			return "", err
+		}
+	}
+	return utils.Json.MarshalToString(m)
+}
+
+// EncryptAddition encrypts the confidential fields of the addition with the master key,
+// the addition is kept as is if no master key is set
+func EncryptAddition(driverName, addition string) (string, error) {
+	return encryptAddition(driverName, addition, conf.Conf.MasterKey)
+}
+
+func encryptAddition(driverName, addition, key string) (string, error) {
+	if key == "" {
+		return addition, nil
+	}
+	return convertAddition(driverName, addition, func(_, value string) (string, error) {
+		if strings.HasPrefix(value, encryptedPrefix) {
+			return value, nil
+		}
+		return encryptValue(value, key)
+	})
+}
+
+// DecryptAddition decrypts the confidential fields of the addition with the master key
+func DecryptAddition(driverName, addition string) (string, error) {
+	return decryptAddition(driverName, addition, conf.Conf.MasterKey)
+}
+
+func decryptAddition(driverName, addition, key string) (string, error) {
+	return convertAddition(driverName, addition, func(_, value string) (string, error) {
+		return decryptValue(value, key)
+	})
+}
+
+// RekeyAddition decrypts the addition with oldKey and encrypts it with newKey
+func RekeyAddition(driverName, addition, oldKey, newKey string) (string, error) {
+	plain, err := decryptAddition(driverName, addition, oldKey)
+	if err != nil {
+		return "", err
+	}
+	return encryptAddition(driverName, plain, newKey)
+}
+
+// MaskStorage replaces the confidential fields of the storage with ConfidentialMask
+func MaskStorage(storage *model.Storage) {
+	masked, err := convertAddition(storage.Driver, storage.Addition, func(_, _ string) (string, error) {
+		return ConfidentialMask, nil
+	})
+	if err == nil {
+		storage.Addition = masked
+	}
+}
+
+// RestoreMasked replaces the masked fields of addition with the values in old addition
+func RestoreMasked(driverName, addition, old string) (string, error) {
+	var oldMap map[string]json.RawMessage
+	if old != "" {
+		if err := utils.Json.UnmarshalFromString(old, &oldMap); err != nil {
+			return "", errors.Wrap(err, "failed unmarshal old addition")
+		}
+	}
+	return convertAddition(driverName, addition, func(name, value string) (string, error) {
+		if value != ConfidentialMask {
+			return value, nil
+		}
+		var oldValue string
+		_ = utils.Json.Unmarshal(oldMap[name], &oldValue)
+		return oldValue, nil
+	})
+}
+
+// EncryptPlainStorages encrypts the confidential fields saved in plaintext before the master key is set,
+// it returns the number of storages updated
+func EncryptPlainStorages() (int, error) {
+	if conf.Conf.MasterKey == "" {
+		return 0, nil
+	}
+	storages, _, err := db.GetStorages(1, -1)
+	if err != nil {
+		return 0, err
+	}
+	updated := 0
+	for i := range storages {
+		plain := false
+		_, err = convertAddition(storages[i].Driver, storages[i].Addition, func(_, value string) (string, error) {
+			plain = plain || !strings.HasPrefix(value, encryptedPrefix)
+			return value, nil
+		})
+		if err != nil || !plain {
+			continue
+		}
+		storages[i].Addition, err = EncryptAddition(storages[i].Driver, storages[i].Addition)
+		if err != nil {
+			return updated, errors.WithMessagef(err, "failed encrypt storage [%s]", storages[i].MountPath)
+		}
+		if err = db.UpdateStorage(&storages[i]); err != nil {
+			return updated, errors.WithMessagef(err, "failed update storage [%s]", storages[i].MountPath)
+		}
+		updated++
+	}
+	return updated, nil
+}
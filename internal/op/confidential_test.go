@@ -0,0 +1,65 @@
+package op_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+func TestEncryptAddition(t *testing.T) {
+	addition := `{"password":"secret","salt":"","remote_path":"/a"}`
+	encrypted, err := op.EncryptAddition("Crypt", addition)
+	if err != nil {
+		t.Fatalf("failed to encrypt: %+v", err)
+	}
+	if strings.Contains(encrypted, "secret") || !strings.Contains(encrypted, `"remote_path":"/a"`) {
+		t.Errorf("expected only confidential fields encrypted, got: %s", encrypted)
+	}
+	again, err := op.EncryptAddition("Crypt", encrypted)
+	if err != nil || again != encrypted {
+		t.Errorf("expected encrypted addition not changed, got: %s, %+v", again, err)
+	}
+	decrypted, err := op.DecryptAddition("Crypt", encrypted)
+	if err != nil {
+		t.Fatalf("failed to decrypt: %+v", err)
+	}
+	var m map[string]string
+	if err = utils.Json.UnmarshalFromString(decrypted, &m); err != nil || m["password"] != "secret" {
+		t.Errorf("expected password decrypted, got: %s", decrypted)
+	}
+
+	rekeyed, err := op.RekeyAddition("Crypt", encrypted, "", "new key")
+	if err == nil {
+		t.Errorf("expected failed to rekey without the old key, got: %s", rekeyed)
+	}
+
+	storage := model.Storage{Driver: "Crypt", Addition: encrypted}
+	op.MaskStorage(&storage)
+	if err = utils.Json.UnmarshalFromString(storage.Addition, &m); err != nil || m["password"] != op.ConfidentialMask || m["salt"] != "" {
+		t.Errorf("expected password masked, got: %s", storage.Addition)
+	}
+}
+
+func TestEncryptPlainStorages(t *testing.T) {
+	storage := model.Storage{Driver: "Crypt", MountPath: "/plain_crypt", Addition: `{"password":"secret","remote_path":"/a"}`}
+	if err := db.CreateStorage(&storage); err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	if n, err := op.EncryptPlainStorages(); err != nil || n != 1 {
+		t.Fatalf("expected 1 storage encrypted, got %d, %+v", n, err)
+	}
+	saved, err := db.GetStorageByMountPath("/plain_crypt")
+	if err != nil {
+		t.Fatalf("failed to get storage: %+v", err)
+	}
+	if strings.Contains(saved.Addition, "secret") {
+		t.Errorf("expected password encrypted, got: %s", saved.Addition)
+	}
+	if n, err := op.EncryptPlainStorages(); err != nil || n != 0 {
+		t.Errorf("expected nothing encrypted again, got %d, %+v", n, err)
+	}
+}
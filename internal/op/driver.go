@@ -79,6 +79,10 @@ func getMainItems(config driver.Config) []driver.Item {
 			Default:  "30",
 			Required: true,
 			Help:     "The cache expiration time for this storage",
+		}, driver.Item{
+			Name: "no_disk_cache",
+			Type: conf.TypeBool,
+			Help: "Do not persist the list cache of this storage to disk",
 		})
 	}
 	if !config.OnlyProxy && !config.OnlyLocal {
@@ -139,6 +143,18 @@ func getMainItems(config driver.Config) []driver.Item {
 		Default:  "false",
 		Required: true,
 	})
+	items = append(items, []driver.Item{{
+		Name:    "balance_mode",
+		Type:    conf.TypeSelect,
+		Options: "round_robin,weighted,least_latency",
+		Default: "round_robin",
+		Help:    "How to choose storage in the balance group, only the mode of the main storage is used",
+	}, {
+		Name:    "balance_weight",
+		Type:    conf.TypeNumber,
+		Default: "1",
+		Help:    "Weight of this storage in the weighted balance group",
+	}}...)
 	return items
 }
 func getAdditionalItems(t reflect.Type, defaultRoot string) []driver.Item {
@@ -156,12 +172,13 @@ func getAdditionalItems(t reflect.Type, defaultRoot string) []driver.Item {
 			continue
 		}
 		item := driver.Item{
-			Name:     name,
-			Type:     strings.ToLower(field.Type.Name()),
-			Default:  tag.Get("default"),
-			Options:  tag.Get("options"),
-			Required: tag.Get("required") == "true",
-			Help:     tag.Get("help"),
+			Name:         name,
+			Type:         strings.ToLower(field.Type.Name()),
+			Default:      tag.Get("default"),
+			Options:      tag.Get("options"),
+			Required:     tag.Get("required") == "true",
+			Help:         tag.Get("help"),
+			Confidential: tag.Get("confidential") == "true",
 		}
 		if tag.Get("type") != "" {
 			item.Type = tag.Get("type")
@@ -0,0 +1,31 @@
+package op
+
+import (
+	"context"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+// fakeDriver is the minimal driver embedded by the test drivers of the optional interfaces,
+// it lists objs at the root
+type fakeDriver struct {
+	model.Storage
+	driver.RootPath
+	objs []model.Obj
+}
+
+func (d *fakeDriver) Config() driver.Config          { return driver.Config{Name: "Fake"} }
+func (d *fakeDriver) GetAddition() driver.Additional { return &d.RootPath }
+func (d *fakeDriver) Init(ctx context.Context) error { return nil }
+func (d *fakeDriver) Drop(ctx context.Context) error { return nil }
+func (d *fakeDriver) GetRootPath() string            { return "/" }
+func (d *fakeDriver) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	return nil, errs.NotImplement
+}
+func (d *fakeDriver) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	return d.objs, nil
+}
+
+var _ driver.Driver = (*fakeDriver)(nil)
@@ -2,10 +2,11 @@ package op
 
 import (
 	"context"
+	"net/http"
 	stdpath "path"
 	"time"
 
-	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/cache"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -18,12 +19,12 @@ import (
 
 // In order to facilitate adding some other things before and after file op
 
-var listCache = cache.NewMemCache(cache.WithShards[[]model.Obj](64))
+var listCache = cache.NewLocal[[]model.Obj]("list", 64, onListInvalidate)
 var listG singleflight.Group[[]model.Obj]
 
 func updateCacheObj(storage driver.Driver, path string, oldObj model.Obj, newObj model.Obj) {
 	key := Key(storage, path)
-	objs, ok := listCache.Get(key)
+	objs, ok := getListCache(storage, key)
 	if ok {
 		for i, obj := range objs {
 			if obj.GetName() == oldObj.GetName() {
@@ -31,13 +32,13 @@ func updateCacheObj(storage driver.Driver, path string, oldObj model.Obj, newObj
 				break
 			}
 		}
-		listCache.Set(key, objs, cache.WithEx[[]model.Obj](time.Minute*time.Duration(storage.GetStorage().CacheExpiration)))
+		updateListCache(storage, key, objs)
 	}
 }
 
 func delCacheObj(storage driver.Driver, path string, obj model.Obj) {
 	key := Key(storage, path)
-	objs, ok := listCache.Get(key)
+	objs, ok := getListCache(storage, key)
 	if ok {
 		for i, oldObj := range objs {
 			if oldObj.GetName() == obj.GetName() {
@@ -45,7 +46,7 @@ func delCacheObj(storage driver.Driver, path string, obj model.Obj) {
 				break
 			}
 		}
-		listCache.Set(key, objs, cache.WithEx[[]model.Obj](time.Minute*time.Duration(storage.GetStorage().CacheExpiration)))
+		updateListCache(storage, key, objs)
 	}
 }
 
@@ -53,11 +54,12 @@ var addSortDebounceMap generic_sync.MapOf[string, func(func())]
 
 func addCacheObj(storage driver.Driver, path string, newObj model.Obj) {
 	key := Key(storage, path)
-	objs, ok := listCache.Get(key)
+	objs, ok := getListCache(storage, key)
 	if ok {
 		for i, obj := range objs {
 			if obj.GetName() == newObj.GetName() {
 				objs[i] = newObj
+				updateListCache(storage, key, objs)
 				return
 			}
 		}
@@ -79,20 +81,26 @@ func addCacheObj(storage driver.Driver, path string, newObj model.Obj) {
 			})
 		}
 
-		listCache.Set(key, objs, cache.WithEx[[]model.Obj](time.Minute*time.Duration(storage.GetStorage().CacheExpiration)))
+		updateListCache(storage, key, objs)
 	}
 }
 
 func ClearCache(storage driver.Driver, path string) {
-	objs, ok := listCache.Get(Key(storage, path))
+	delListCache(cacheKeys(storage, path)...)
+}
+
+// cacheKeys returns the keys of path and its cached sub folders
+func cacheKeys(storage driver.Driver, path string) []string {
+	keys := []string{Key(storage, path)}
+	objs, ok := getListCache(storage, keys[0])
 	if ok {
 		for _, obj := range objs {
 			if obj.IsDir() {
-				ClearCache(storage, stdpath.Join(path, obj.GetName()))
+				keys = append(keys, cacheKeys(storage, stdpath.Join(path, obj.GetName()))...)
 			}
 		}
 	}
-	listCache.Del(Key(storage, path))
+	return keys
 }
 
 func Key(storage driver.Driver, path string) string {
@@ -108,7 +116,7 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 	log.Debugf("op.List %s", path)
 	key := Key(storage, path)
 	if !args.Refresh {
-		if files, ok := listCache.Get(key); ok {
+		if files, ok := getListCache(storage, key); ok {
 			log.Debugf("use cache when list %s", path)
 			return files, nil
 		}
@@ -122,7 +130,9 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 		return nil, errors.WithStack(errs.NotFolder)
 	}
 	objs, err, _ := listG.Do(key, func() ([]model.Obj, error) {
+		start := time.Now()
 		files, err := storage.List(ctx, dir, args)
+		reportBalance(storage, time.Since(start), err)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to list objs")
 		}
@@ -146,12 +156,12 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 		model.ExtractFolder(files, storage.GetStorage().ExtractFolder)
 
 		if !storage.Config().NoCache {
-			if len(files) > 0 {
+			if len(files) > 0 && storage.GetStorage().CacheExpiration > 0 {
 				log.Debugf("set cache: %s => %+v", key, files)
-				listCache.Set(key, files, cache.WithEx[[]model.Obj](time.Minute*time.Duration(storage.GetStorage().CacheExpiration)))
+				setListCache(storage, key, files)
 			} else {
-				log.Debugf("del cache: %s", key)
-				listCache.Del(key)
+				log.Debugf("drop cache: %s", key)
+				dropListCache(key)
 			}
 		}
 		return files, nil
@@ -235,9 +245,42 @@ func GetUnwrap(ctx context.Context, storage driver.Driver, path string) (model.O
 	return model.UnwrapObj(obj), err
 }
 
-var linkCache = cache.NewMemCache(cache.WithShards[*model.Link](16))
+var linkCache = cache.NewLocal[*model.Link]("link", 16)
 var linkG singleflight.Group[*model.Link]
 
+// urlLink is the link only made of the url, it's encodable so shared by all instances,
+// while the links with readers are only kept in linkCache of the instance
+type urlLink struct {
+	URL         string
+	Header      http.Header
+	Concurrency int
+	PartSize    int
+}
+
+var urlLinkCache = cache.New[urlLink]("url_link", 16)
+
+func getLinkCache(key string) (*model.Link, bool) {
+	if link, ok := linkCache.Get(key); ok {
+		return link, true
+	}
+	if l, ok := urlLinkCache.Get(key); ok {
+		return &model.Link{URL: l.URL, Header: l.Header, Concurrency: l.Concurrency, PartSize: l.PartSize}, true
+	}
+	return nil, false
+}
+
+func setLinkCache(key string, link *model.Link, ttl time.Duration) {
+	linkCache.Set(key, link, ttl)
+	if link.URL != "" && link.RangeReadCloser == nil && link.MFile == nil {
+		urlLinkCache.Set(key, urlLink{URL: link.URL, Header: link.Header, Concurrency: link.Concurrency, PartSize: link.PartSize}, ttl)
+	}
+}
+
+func delLinkCache(key string) {
+	linkCache.Del(key)
+	urlLinkCache.Del(key)
+}
+
 // Link get link, if is an url. should have an expiry time
 func Link(ctx context.Context, storage driver.Driver, path string, args model.LinkArgs) (*model.Link, model.Obj, error) {
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
@@ -251,19 +294,21 @@ func Link(ctx context.Context, storage driver.Driver, path string, args model.Li
 		return nil, nil, errors.WithStack(errs.NotFile)
 	}
 	key := Key(storage, path)
-	if link, ok := linkCache.Get(key); ok {
+	if link, ok := getLinkCache(key); ok {
 		return link, file, nil
 	}
 	fn := func() (*model.Link, error) {
+		start := time.Now()
 		link, err := storage.Link(ctx, file, args)
+		reportBalance(storage, time.Since(start), err)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed get link")
 		}
-		if link.Expiration != nil {
+		if link.Expiration != nil && *link.Expiration > 0 {
 			if link.IPCacheKey {
 				key = key + ":" + args.IP
 			}
-			linkCache.Set(key, link, cache.WithEx[*model.Link](*link.Expiration))
+			setLinkCache(key, link, *link.Expiration)
 		}
 		return link, nil
 	}
@@ -466,6 +511,57 @@ func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	return errors.WithStack(err)
 }
 
+// PeerCopy copies srcPath of srcStorage to dstDirPath of dstStorage on the server side,
+// errs.NotSupport is returned if dstStorage is not a peer of srcStorage
+func PeerCopy(ctx context.Context, srcStorage driver.Driver, srcPath string, dstStorage driver.Driver, dstDirPath string, lazyCache ...bool) error {
+	peer, srcObj, dstDir, err := getPeer(ctx, srcStorage, srcPath, dstStorage, dstDirPath)
+	if err != nil {
+		return err
+	}
+	err = peer.PeerCopy(ctx, model.UnwrapObj(srcObj), dstStorage, dstDir)
+	if err == nil && !utils.IsBool(lazyCache...) {
+		ClearCache(dstStorage, dstDirPath)
+	}
+	return errors.WithStack(err)
+}
+
+// PeerMove is like PeerCopy but moves the obj
+func PeerMove(ctx context.Context, srcStorage driver.Driver, srcPath string, dstStorage driver.Driver, dstDirPath string, lazyCache ...bool) error {
+	peer, srcObj, dstDir, err := getPeer(ctx, srcStorage, srcPath, dstStorage, dstDirPath)
+	if err != nil {
+		return err
+	}
+	err = peer.PeerMove(ctx, model.UnwrapObj(srcObj), dstStorage, dstDir)
+	if err == nil {
+		delCacheObj(srcStorage, stdpath.Dir(utils.FixAndCleanPath(srcPath)), srcObj)
+		if !utils.IsBool(lazyCache...) {
+			ClearCache(dstStorage, dstDirPath)
+		}
+	}
+	return errors.WithStack(err)
+}
+
+func getPeer(ctx context.Context, srcStorage driver.Driver, srcPath string, dstStorage driver.Driver, dstDirPath string) (driver.Peer, model.Obj, model.Obj, error) {
+	peer, ok := srcStorage.(driver.Peer)
+	if !ok || !peer.IsPeer(dstStorage) {
+		return nil, nil, nil, errs.NotSupport
+	}
+	for _, storage := range []driver.Driver{srcStorage, dstStorage} {
+		if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+			return nil, nil, nil, errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+		}
+	}
+	srcObj, err := Get(ctx, srcStorage, srcPath)
+	if err != nil {
+		return nil, nil, nil, errors.WithMessage(err, "failed to get src object")
+	}
+	dstDir, err := GetUnwrap(ctx, dstStorage, dstDirPath)
+	if err != nil {
+		return nil, nil, nil, errors.WithMessage(err, "failed to get dst dir")
+	}
+	return peer, srcObj, dstDir, nil
+}
+
 func Remove(ctx context.Context, storage driver.Driver, path string) error {
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
@@ -580,7 +676,7 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 				return err
 			} else {
 				key := Key(storage, stdpath.Join(dstDirPath, file.GetName()))
-				linkCache.Del(key)
+				delLinkCache(key)
 			}
 		}
 	}
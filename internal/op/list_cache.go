@@ -0,0 +1,154 @@
+package op
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/cache"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// listDiskCache is the second level of listCache, so the listings survive restarts
+var listDiskCache *cache.Disk
+
+// diskCacheObjTypes are the types of objs can be persisted,
+// the listings containing other types are only cached in memory
+var diskCacheObjTypes = map[reflect.Type]struct{}{}
+
+// RegisterDiskCacheObj registers the obj types which can be encoded by gob without losing fields,
+// it should be called in init of the driver. The drivers returning the generic model objs need nothing,
+// the ones with own obj types (115, 123, OneDrive, OneDrive APP, WoPan, MediaTrack) register them,
+// the listings of the other drivers are only cached in memory.
+func RegisterDiskCacheObj(objs ...model.Obj) {
+	for _, obj := range objs {
+		gob.Register(obj)
+		diskCacheObjTypes[reflect.TypeOf(obj)] = struct{}{}
+	}
+}
+
+func init() {
+	RegisterDiskCacheObj(&model.Object{}, &model.ObjThumb{}, &model.ObjectURL{}, &model.ObjThumbURL{})
+}
+
+func InitListDiskCache(path string, maxSize int64) error {
+	d, err := cache.OpenDisk(path, maxSize)
+	if err != nil {
+		return err
+	}
+	listDiskCache = d
+	return nil
+}
+
+func CloseListDiskCache() {
+	if listDiskCache == nil {
+		return
+	}
+	if err := listDiskCache.Close(); err != nil {
+		log.Errorf("failed close list disk cache: %+v", err)
+	}
+	listDiskCache = nil
+}
+
+type diskCacheEntry struct {
+	// Version of the storage, the entry is dropped once the storage is updated
+	Version string
+	Objs    []model.Obj
+}
+
+func storageVersion(storage driver.Driver) string {
+	return fmt.Sprintf("%d-%d", storage.GetStorage().ID, storage.GetStorage().Modified.UnixNano())
+}
+
+func useDiskCache(storage driver.Driver) bool {
+	return listDiskCache != nil && !storage.GetStorage().NoDiskCache
+}
+
+func listCacheExpiration(storage driver.Driver) time.Duration {
+	return time.Minute * time.Duration(storage.GetStorage().CacheExpiration)
+}
+
+func getListCache(storage driver.Driver, key string) ([]model.Obj, bool) {
+	if objs, ok := listCache.Get(key); ok {
+		return objs, true
+	}
+	if !useDiskCache(storage) {
+		return nil, false
+	}
+	data, ttl, ok := listDiskCache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	var e diskCacheEntry
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&e); err != nil || e.Version != storageVersion(storage) {
+		delDiskCache(key)
+		return nil, false
+	}
+	model.WrapObjsName(e.Objs)
+	listCache.Set(key, e.Objs, ttl)
+	return e.Objs, true
+}
+
+func setListCache(storage driver.Driver, key string, objs []model.Obj) {
+	listCache.Set(key, objs, listCacheExpiration(storage))
+	if !useDiskCache(storage) {
+		return
+	}
+	e := diskCacheEntry{Version: storageVersion(storage), Objs: make([]model.Obj, 0, len(objs))}
+	for _, obj := range objs {
+		obj = model.UnwrapObj(obj)
+		if _, ok := diskCacheObjTypes[reflect.TypeOf(obj)]; !ok {
+			return
+		}
+		e.Objs = append(e.Objs, obj)
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(e); err != nil {
+		log.Warnf("failed encode list cache of %s: %+v", key, err)
+		return
+	}
+	if err := listDiskCache.Set(key, buf.Bytes(), listCacheExpiration(storage)); err != nil {
+		log.Warnf("failed set list disk cache of %s: %+v", key, err)
+	}
+}
+
+// updateListCache stores the objs changed by a write, the persisted one is dropped
+func updateListCache(storage driver.Driver, key string, objs []model.Obj) {
+	listCache.Update(key, objs, listCacheExpiration(storage))
+	delDiskCache(key)
+}
+
+func delListCache(keys ...string) {
+	listCache.Del(keys...)
+	delDiskCache(keys...)
+}
+
+// dropListCache deletes the listings found stale by a read, the other instances are not notified
+func dropListCache(keys ...string) {
+	listCache.Drop(keys...)
+	delDiskCache(keys...)
+}
+
+func delDiskCache(keys ...string) {
+	if listDiskCache == nil || len(keys) == 0 {
+		return
+	}
+	if err := listDiskCache.Del(keys...); err != nil {
+		log.Warnf("failed delete list disk cache: %+v", err)
+	}
+}
+
+// onListInvalidate drops the persisted listings invalidated by the other instances
+func onListInvalidate(keys []string) {
+	if len(keys) > 0 {
+		delDiskCache(keys...)
+	} else if listDiskCache != nil {
+		if err := listDiskCache.Clear(); err != nil {
+			log.Warnf("failed clear list disk cache: %+v", err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package op
+
+import (
+	"context"
+	stdpath "path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// page tokens are prefixed with the way the page is got,
+// so that a token is never passed to the wrong place
+const (
+	offsetTokenPrefix = "o:"
+	pagerTokenPrefix  = "p:"
+)
+
+// ListPage list at most args.Limit files in storage, not contains virtual file.
+// The storage is listed page by page if it implements driver.ListPager and the
+// folder is not cached, otherwise the whole folder is listed by List and sliced.
+// If the list can't be cached either, the whole folder is returned as one page,
+// as listing it again for every page would be much worse.
+// The returned token is empty after the last page.
+func ListPage(ctx context.Context, storage driver.Driver, path string, args model.ListPageArgs) ([]model.Obj, string, error) {
+	_, isPager := storage.(driver.ListPager)
+	if args.Limit <= 0 || !isPager && (storage.Config().NoCache || storage.GetStorage().CacheExpiration <= 0) {
+		if args.Token != "" {
+			return nil, "", errors.Errorf("invalid list token: %s", args.Token)
+		}
+		objs, err := List(ctx, storage, path, model.ListArgs{ReqPath: args.ReqPath, Refresh: args.Refresh})
+		return objs, "", err
+	}
+	path = utils.FixAndCleanPath(path)
+	if pager, ok := storage.(driver.ListPager); ok && !strings.HasPrefix(args.Token, offsetTokenPrefix) {
+		key := Key(storage, path)
+		_, cached := getListCache(storage, key)
+		if args.Token != "" || args.Refresh || !cached {
+			if args.Refresh && cached {
+				dropListCache(key)
+			}
+			return listPage(ctx, storage, pager, path, args)
+		}
+	}
+	offset := 0
+	if args.Token != "" {
+		var err error
+		offset, err = strconv.Atoi(strings.TrimPrefix(args.Token, offsetTokenPrefix))
+		if err != nil || offset < 0 {
+			return nil, "", errors.Errorf("invalid list token: %s", args.Token)
+		}
+	}
+	objs, err := List(ctx, storage, path, model.ListArgs{
+		ReqPath: args.ReqPath,
+		Refresh: args.Refresh && offset == 0,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if offset >= len(objs) {
+		return nil, "", nil
+	}
+	if args.Limit >= len(objs)-offset {
+		return objs[offset:], "", nil
+	}
+	end := offset + args.Limit
+	return objs[offset:end], offsetTokenPrefix + strconv.Itoa(end), nil
+}
+
+func listPage(ctx context.Context, storage driver.Driver, pager driver.ListPager, path string, args model.ListPageArgs) ([]model.Obj, string, error) {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return nil, "", errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	log.Debugf("op.ListPage %s, token: %s", path, args.Token)
+	dir, err := GetUnwrap(ctx, storage, path)
+	if err != nil {
+		return nil, "", errors.WithMessage(err, "failed get dir")
+	}
+	if !dir.IsDir() {
+		return nil, "", errors.WithStack(errs.NotFolder)
+	}
+	args.Token = strings.TrimPrefix(args.Token, pagerTokenPrefix)
+	start := time.Now()
+	files, next, err := pager.ListPage(ctx, dir, args)
+	reportBalance(storage, time.Since(start), err)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to list objs")
+	}
+	for _, f := range files {
+		if s, ok := f.(model.SetPath); ok && f.GetPath() == "" && dir.GetPath() != "" {
+			s.SetPath(stdpath.Join(dir.GetPath(), f.GetName()))
+		}
+	}
+	model.WrapObjsName(files)
+	// only sort inside the page, the order between pages is decided by the driver
+	if storage.Config().LocalSort {
+		model.SortFiles(files, storage.GetStorage().OrderBy, storage.GetStorage().OrderDirection)
+	}
+	model.ExtractFolder(files, storage.GetStorage().ExtractFolder)
+	if next != "" {
+		next = pagerTokenPrefix + next
+	}
+	return files, next, nil
+}
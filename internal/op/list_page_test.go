@@ -0,0 +1,100 @@
+package op_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+func TestListPage(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 5; i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.txt", i)), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	_, err := op.CreateStorage(ctx, model.Storage{
+		Driver:    "Local",
+		MountPath: "/list_page",
+		Addition:  fmt.Sprintf(`{"root_folder_path":%q}`, dir),
+	})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	storage, err := op.GetStorageByMountPath("/list_page")
+	if err != nil {
+		t.Fatalf("failed to get storage: %+v", err)
+	}
+	var names []string
+	var pages int
+	token := ""
+	for {
+		objs, next, err := op.ListPage(ctx, storage, "/", model.ListPageArgs{Token: token, Limit: 2})
+		if err != nil {
+			t.Fatalf("failed to list page: %+v", err)
+		}
+		if len(objs) > 2 {
+			t.Errorf("expect at most 2 objs in a page, got %d", len(objs))
+		}
+		for _, obj := range objs {
+			names = append(names, obj.GetName())
+		}
+		pages++
+		if next == "" {
+			break
+		}
+		token = next
+	}
+	if pages != 3 {
+		t.Errorf("expect 3 pages, got %d", pages)
+	}
+	// the pages of local are in the order of the folder
+	sort.Strings(names)
+	expect := []string{"0.txt", "1.txt", "2.txt", "3.txt", "4.txt"}
+	if fmt.Sprint(names) != fmt.Sprint(expect) {
+		t.Errorf("expect %v, got %v", expect, names)
+	}
+	if _, _, err = op.ListPage(ctx, storage, "/", model.ListPageArgs{Token: "o:x", Limit: 2}); err == nil {
+		t.Errorf("expect error for invalid token")
+	}
+	if _, _, err = op.ListPage(ctx, storage, "/", model.ListPageArgs{Token: token, Limit: 2}); err == nil {
+		t.Errorf("expect error for a used token")
+	}
+}
+
+func TestListPageMaxLimit(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 3; i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.txt", i)), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	_, err := op.CreateStorage(ctx, model.Storage{
+		Driver:    "Local",
+		MountPath: "/list_page_max",
+		Addition:  fmt.Sprintf(`{"root_folder_path":%q}`, dir),
+	})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	storage, err := op.GetStorageByMountPath("/list_page_max")
+	if err != nil {
+		t.Fatalf("failed to get storage: %+v", err)
+	}
+	objs, next, err := op.ListPage(ctx, storage, "/", model.ListPageArgs{Token: "o:1", Limit: math.MaxInt})
+	if err != nil {
+		t.Fatalf("failed to list page: %+v", err)
+	}
+	if len(objs) != 2 || next != "" {
+		t.Errorf("expect the last 2 objs without next token, got %d objs and %q", len(objs), next)
+	}
+}
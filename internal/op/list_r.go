@@ -0,0 +1,84 @@
+package op
+
+import (
+	"context"
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// ListR list all descendants of path in storage, not contains virtual file.
+// fn is called with the actual path of a folder and some of the objs in it,
+// a folder is always passed to fn before the objs in it.
+// Storages not implementing driver.ListR are listed folder by folder with List.
+func ListR(ctx context.Context, storage driver.Driver, path string, args model.ListArgs, fn func(parent string, objs []model.Obj) error) error {
+	path = utils.FixAndCleanPath(path)
+	lister, ok := storage.(driver.ListR)
+	if !ok {
+		return listR(ctx, storage, path, args, fn)
+	}
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	log.Debugf("op.ListR %s", path)
+	dir, err := GetUnwrap(ctx, storage, path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get dir")
+	}
+	if !dir.IsDir() {
+		return errors.WithStack(errs.NotFolder)
+	}
+	// errors of fn are returned as is, they are not failures of the storage
+	var fnErr error
+	start := time.Now()
+	err = lister.ListR(ctx, dir, args, func(parent string, objs []model.Obj) error {
+		for _, f := range objs {
+			if s, ok := f.(model.SetPath); ok && f.GetPath() == "" && dir.GetPath() != "" {
+				s.SetPath(stdpath.Join(dir.GetPath(), parent, f.GetName()))
+			}
+		}
+		model.WrapObjsName(objs)
+		fnErr = fn(stdpath.Join(path, parent), objs)
+		return fnErr
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	reportBalance(storage, time.Since(start), err)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list objs recursively")
+	}
+	return nil
+}
+
+func listR(ctx context.Context, storage driver.Driver, path string, args model.ListArgs, fn func(parent string, objs []model.Obj) error) error {
+	if utils.IsCanceled(ctx) {
+		return ctx.Err()
+	}
+	objs, err := List(ctx, storage, path, args)
+	if err != nil {
+		return err
+	}
+	if err = fn(path, objs); err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		if !obj.IsDir() {
+			continue
+		}
+		subArgs := args
+		if args.ReqPath != "" {
+			subArgs.ReqPath = stdpath.Join(args.ReqPath, obj.GetName())
+		}
+		if err = listR(ctx, storage, stdpath.Join(path, obj.GetName()), subArgs, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package op_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+func TestListR(t *testing.T) {
+	dir := t.TempDir()
+	for _, p := range []string{"a/b/c.txt", "a/d.txt", "e.txt"} {
+		p = filepath.Join(dir, p)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	_, err := op.CreateStorage(ctx, model.Storage{
+		Driver:    "Local",
+		MountPath: "/list_r",
+		Addition:  fmt.Sprintf(`{"root_folder_path":%q}`, dir),
+	})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	storage, err := op.GetStorageByMountPath("/list_r")
+	if err != nil {
+		t.Fatalf("failed to get storage: %+v", err)
+	}
+	seen := map[string]bool{"/": true}
+	var paths []string
+	err = op.ListR(ctx, storage, "/", model.ListArgs{}, func(parent string, objs []model.Obj) error {
+		if !seen[parent] {
+			t.Errorf("objs in %s are listed before the folder", parent)
+		}
+		for _, obj := range objs {
+			p := path.Join(parent, obj.GetName())
+			seen[p] = true
+			paths = append(paths, p)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to list recursively: %+v", err)
+	}
+	sort.Strings(paths)
+	expect := []string{"/a", "/a/b", "/a/b/c.txt", "/a/d.txt", "/e.txt"}
+	if fmt.Sprint(paths) != fmt.Sprint(expect) {
+		t.Errorf("expect %v, got %v", expect, paths)
+	}
+}
@@ -4,7 +4,7 @@ import (
 	stdpath "path"
 	"time"
 
-	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/cache"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -14,7 +14,7 @@ import (
 	"gorm.io/gorm"
 )
 
-var metaCache = cache.NewMemCache(cache.WithShards[*model.Meta](2))
+var metaCache = cache.New[*model.Meta]("meta", 2)
 
 // metaG maybe not needed
 var metaG singleflight.Group[*model.Meta]
@@ -51,12 +51,12 @@ func getMetaByPath(path string) (*model.Meta, error) {
 		_meta, err := db.GetMetaByPath(path)
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
-				metaCache.Set(path, nil)
+				metaCache.Set(path, nil, 0)
 				return nil, errs.MetaNotFound
 			}
 			return nil, err
 		}
-		metaCache.Set(path, _meta, cache.WithEx[*model.Meta](time.Hour))
+		metaCache.Set(path, _meta, time.Hour)
 		return _meta, nil
 	})
 	return meta, err
@@ -27,3 +27,21 @@ func GetStorageAndActualPath(rawPath string) (storage driver.Driver, actualPath
 	actualPath = utils.FixAndCleanPath(strings.TrimPrefix(rawPath, mountPath))
 	return
 }
+
+// GetStoragesAndActualPath is like GetStorageAndActualPath,
+// but returns all storages of the balance group to fail over in order
+func GetStoragesAndActualPath(rawPath string) (storages []driver.Driver, actualPath string, err error) {
+	rawPath = utils.FixAndCleanPath(rawPath)
+	storages = GetBalancedStorages(rawPath)
+	if len(storages) == 0 {
+		if rawPath == "/" {
+			err = errs.NewErr(errs.StorageNotFound, "please add a storage first")
+			return
+		}
+		err = errs.NewErr(errs.StorageNotFound, "rawPath: %s", rawPath)
+		return
+	}
+	mountPath := utils.GetActualMountPath(storages[0].GetStorage().MountPath)
+	actualPath = utils.FixAndCleanPath(strings.TrimPrefix(rawPath, mountPath))
+	return
+}
@@ -0,0 +1,51 @@
+package op
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+var peerObjs = []model.Obj{&model.Object{Name: "a.txt", Path: "/a.txt", Size: 1}}
+
+type fakePeer struct {
+	fakeDriver
+	account string
+	copied  []string
+}
+
+func (d *fakePeer) IsPeer(dst driver.Driver) bool {
+	p, ok := dst.(*fakePeer)
+	return ok && p.account == d.account
+}
+
+func (d *fakePeer) PeerCopy(ctx context.Context, srcObj model.Obj, dst driver.Driver, dstDir model.Obj) error {
+	d.copied = append(d.copied, srcObj.GetPath()+">"+dst.GetStorage().MountPath+dstDir.GetPath())
+	return nil
+}
+
+func (d *fakePeer) PeerMove(ctx context.Context, srcObj model.Obj, dst driver.Driver, dstDir model.Obj) error {
+	return d.PeerCopy(ctx, srcObj, dst, dstDir)
+}
+
+func TestPeerCopy(t *testing.T) {
+	ctx := context.Background()
+	src := &fakePeer{fakeDriver: fakeDriver{Storage: model.Storage{MountPath: "/peer_src"}, objs: peerObjs}, account: "a"}
+	dst := &fakePeer{fakeDriver: fakeDriver{Storage: model.Storage{MountPath: "/peer_dst"}, objs: peerObjs}, account: "a"}
+	other := &fakePeer{fakeDriver: fakeDriver{Storage: model.Storage{MountPath: "/peer_other"}, objs: peerObjs}, account: "b"}
+	if err := PeerCopy(ctx, src, "/a.txt", dst, "/"); err != nil {
+		t.Fatalf("failed to copy to peer: %+v", err)
+	}
+	if err := PeerMove(ctx, src, "/a.txt", dst, "/"); err != nil {
+		t.Fatalf("failed to move to peer: %+v", err)
+	}
+	if len(src.copied) != 2 || src.copied[0] != "/a.txt>/peer_dst" {
+		t.Errorf("unexpected peer copies: %v", src.copied)
+	}
+	if err := PeerCopy(ctx, src, "/a.txt", other, "/"); !errs.IsNotSupportError(err) {
+		t.Errorf("expect not support for storage of other account, got %+v", err)
+	}
+}
@@ -6,7 +6,7 @@ import (
 	"strings"
 	"time"
 
-	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/cache"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/singleflight"
@@ -14,16 +14,16 @@ import (
 	"github.com/pkg/errors"
 )
 
-var settingCache = cache.NewMemCache(cache.WithShards[*model.SettingItem](4))
+var settingCache = cache.New[*model.SettingItem]("setting", 4)
 var settingG singleflight.Group[*model.SettingItem]
 var settingCacheF = func(item *model.SettingItem) {
-	settingCache.Set(item.Key, item, cache.WithEx[*model.SettingItem](time.Hour))
+	settingCache.Set(item.Key, item, time.Hour)
 }
 
-var settingGroupCache = cache.NewMemCache(cache.WithShards[[]model.SettingItem](4))
+var settingGroupCache = cache.New[[]model.SettingItem]("setting_group", 4)
 var settingGroupG singleflight.Group[[]model.SettingItem]
 var settingGroupCacheF = func(key string, item []model.SettingItem) {
-	settingGroupCache.Set(key, item, cache.WithEx[[]model.SettingItem](time.Hour))
+	settingGroupCache.Set(key, item, time.Hour)
 }
 
 func settingCacheUpdate() {
@@ -53,11 +53,17 @@ func CreateStorage(ctx context.Context, storage model.Storage) (uint, error) {
 		return 0, errors.WithMessage(err, "failed get driver new")
 	}
 	storageDriver := driverNew()
-	// insert storage to database
+	// insert storage to database, with confidential fields encrypted
+	addition := storage.Addition
+	storage.Addition, err = EncryptAddition(driverName, addition)
+	if err != nil {
+		return 0, errors.WithMessage(err, "failed encrypt addition")
+	}
 	err = db.CreateStorage(&storage)
 	if err != nil {
 		return storage.ID, errors.WithMessage(err, "failed create storage in database")
 	}
+	storage.Addition = addition
 	// already has an id
 	err = initStorage(ctx, storage, storageDriver)
 	go callStorageHooks("add", storageDriver)
@@ -78,6 +84,10 @@ func LoadStorage(ctx context.Context, storage model.Storage) error {
 		return errors.WithMessage(err, "failed get driver new")
 	}
 	storageDriver := driverNew()
+	storage.Addition, err = DecryptAddition(driverName, storage.Addition)
+	if err != nil {
+		return errors.WithMessage(err, "failed decrypt addition")
+	}
 
 	err = initStorage(ctx, storage, storageDriver)
 	go callStorageHooks("add", storageDriver)
@@ -110,6 +120,7 @@ func initStorage(ctx context.Context, storage model.Storage, storageDriver drive
 		err = storageDriver.Init(ctx)
 	}
 	storagesMap.Store(driverStorage.MountPath, storageDriver)
+	resetHealth(driverStorage.MountPath)
 	if err != nil {
 		driverStorage.SetStatus(err.Error())
 		err = errors.Wrap(err, "failed init storage")
@@ -164,6 +175,7 @@ func DisableStorage(ctx context.Context, id uint) error {
 		return errors.WithMessage(err, "failed update storage in db")
 	}
 	storagesMap.Delete(storage.MountPath)
+	resetHealth(storage.MountPath)
 	go callStorageHooks("del", storageDriver)
 	return nil
 }
@@ -181,10 +193,24 @@ func UpdateStorage(ctx context.Context, storage model.Storage) error {
 	}
 	storage.Modified = time.Now()
 	storage.MountPath = utils.FixAndCleanPath(storage.MountPath)
+	// the masked confidential fields are not changed
+	oldAddition, err := DecryptAddition(oldStorage.Driver, oldStorage.Addition)
+	if err != nil {
+		return errors.WithMessage(err, "failed decrypt old addition")
+	}
+	addition, err := RestoreMasked(storage.Driver, storage.Addition, oldAddition)
+	if err != nil {
+		return errors.WithMessage(err, "failed restore masked addition")
+	}
+	storage.Addition, err = EncryptAddition(storage.Driver, addition)
+	if err != nil {
+		return errors.WithMessage(err, "failed encrypt addition")
+	}
 	err = db.UpdateStorage(&storage)
 	if err != nil {
 		return errors.WithMessage(err, "failed update storage in database")
 	}
+	storage.Addition = addition
 	if storage.Disabled {
 		return nil
 	}
@@ -192,6 +218,7 @@ func UpdateStorage(ctx context.Context, storage model.Storage) error {
 	if oldStorage.MountPath != storage.MountPath {
 		// mount path renamed, need to drop the storage
 		storagesMap.Delete(oldStorage.MountPath)
+		resetHealth(oldStorage.MountPath)
 	}
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage driver")
@@ -223,6 +250,7 @@ func DeleteStorageById(ctx context.Context, id uint) error {
 		}
 		// delete the storage in the memory
 		storagesMap.Delete(storage.MountPath)
+		resetHealth(storage.MountPath)
 		go callStorageHooks("del", storageDriver)
 	}
 	// delete the storage in the database
@@ -232,6 +260,21 @@ func DeleteStorageById(ctx context.Context, id uint) error {
 	return nil
 }
 
+// UnloadStorage drops the storage and removes it from memory if it's loaded,
+// the storage in database is not changed
+func UnloadStorage(ctx context.Context, mountPath string) {
+	storageDriver, err := GetStorageByMountPath(mountPath)
+	if err != nil {
+		return
+	}
+	if err := storageDriver.Drop(ctx); err != nil {
+		log.Warnf("failed drop storage [%s]: %+v", mountPath, err)
+	}
+	storagesMap.Delete(mountPath)
+	resetHealth(mountPath)
+	go callStorageHooks("del", storageDriver)
+}
+
 // MustSaveDriverStorage call from specific driver
 func MustSaveDriverStorage(driver driver.Driver) {
 	err := saveDriverStorage(driver)
@@ -248,7 +291,13 @@ func saveDriverStorage(driver driver.Driver) error {
 		return errors.Wrap(err, "error while marshal addition")
 	}
 	storage.Addition = str
-	err = db.UpdateStorage(storage)
+	// keep the addition in memory decrypted
+	dbStorage := *storage
+	dbStorage.Addition, err = EncryptAddition(storage.Driver, str)
+	if err != nil {
+		return errors.WithMessage(err, "failed encrypt addition")
+	}
+	err = db.UpdateStorage(&dbStorage)
 	if err != nil {
 		return errors.WithMessage(err, "failed update storage in database")
 	}
@@ -317,24 +366,3 @@ func GetStorageVirtualFilesByPath(prefix string) []model.Obj {
 	}
 	return files
 }
-
-var balanceMap generic_sync.MapOf[string, int]
-
-// GetBalancedStorage get storage by path
-func GetBalancedStorage(path string) driver.Driver {
-	path = utils.FixAndCleanPath(path)
-	storages := getStoragesByPath(path)
-	storageNum := len(storages)
-	switch storageNum {
-	case 0:
-		return nil
-	case 1:
-		return storages[0]
-	default:
-		virtualPath := utils.GetActualMountPath(storages[0].GetStorage().MountPath)
-		i, _ := balanceMap.LoadOrStore(virtualPath, 0)
-		i = (i + 1) % storageNum
-		balanceMap.Store(virtualPath, i)
-		return storages[i]
-	}
-}
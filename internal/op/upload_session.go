@@ -0,0 +1,135 @@
+package op
+
+import (
+	"context"
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/cache"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// uploadSession is what the server remembers about an upload session until it's completed
+type uploadSession struct {
+	MountPath  string
+	DstDirPath string
+	Name       string
+	Size       int64
+	ID         string
+}
+
+var uploadSessionCache = cache.New[uploadSession]("upload_session", 16)
+
+// uploadSessionTimers abort the sessions not completed before expired. The sessions completed by
+// other instances are aborted too, which the storage refuses harmlessly.
+var uploadSessionTimers generic_sync.MapOf[string, *time.Timer]
+
+// CreateUploadSession lets the client upload the file to the storage directly,
+// it returns the token used to complete the session and how to upload the file.
+// errs.NotImplement is returned if the storage doesn't support it.
+func CreateUploadSession(ctx context.Context, storage driver.Driver, dstDirPath string, args model.UploadSessionArgs) (string, *model.UploadSession, error) {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return "", nil, errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	uploader, ok := storage.(driver.DirectUpload)
+	if !ok {
+		return "", nil, errs.NotImplement
+	}
+	dstDirPath = utils.FixAndCleanPath(dstDirPath)
+	err := MakeDir(ctx, storage, dstDirPath)
+	if err != nil {
+		return "", nil, errors.WithMessagef(err, "failed to make dir [%s]", dstDirPath)
+	}
+	parentDir, err := GetUnwrap(ctx, storage, dstDirPath)
+	if err != nil {
+		return "", nil, errors.WithMessagef(err, "failed to get dir [%s]", dstDirPath)
+	}
+	session, err := uploader.CreateUploadSession(ctx, parentDir, args)
+	if err != nil {
+		return "", nil, errors.WithMessage(err, "failed create upload session")
+	}
+	ttl := time.Hour
+	if !session.Expires.IsZero() {
+		ttl = time.Until(session.Expires)
+	}
+	token := random.Token()
+	uploadSessionCache.Set(token, uploadSession{
+		MountPath:  storage.GetStorage().MountPath,
+		DstDirPath: dstDirPath,
+		Name:       args.Name,
+		Size:       args.Size,
+		ID:         session.ID,
+	}, ttl)
+	if aborter, ok := storage.(driver.AbortUploadSession); ok && session.ID != "" {
+		abortArgs := model.UploadCompleteArgs{Name: args.Name, Size: args.Size, ID: session.ID}
+		uploadSessionTimers.Store(token, time.AfterFunc(ttl, func() {
+			uploadSessionTimers.Delete(token)
+			abortUploadSession(storage, aborter, dstDirPath, abortArgs)
+		}))
+	}
+	return token, session, nil
+}
+
+func abortUploadSession(storage driver.Driver, aborter driver.AbortUploadSession, dstDirPath string, args model.UploadCompleteArgs) {
+	ctx := context.Background()
+	parentDir, err := GetUnwrap(ctx, storage, dstDirPath)
+	if err == nil {
+		err = aborter.AbortUploadSession(ctx, parentDir, args)
+	}
+	if err != nil {
+		log.Warnf("failed abort expired upload session of [%s]: %+v", stdpath.Join(dstDirPath, args.Name), err)
+		return
+	}
+	log.Debugf("expired upload session of [%s] aborted", stdpath.Join(dstDirPath, args.Name))
+}
+
+// CompleteUploadSession is called after the client uploaded all parts of the file,
+// the session of token must be created for the same file.
+func CompleteUploadSession(ctx context.Context, storage driver.Driver, dstDirPath, name, token string, parts []model.UploadPart) error {
+	uploader, ok := storage.(driver.DirectUpload)
+	if !ok {
+		return errs.NotImplement
+	}
+	dstDirPath = utils.FixAndCleanPath(dstDirPath)
+	session, ok := uploadSessionCache.Get(token)
+	if !ok || session.MountPath != storage.GetStorage().MountPath ||
+		session.DstDirPath != dstDirPath || session.Name != name {
+		return errors.WithStack(errs.UploadSessionNotFound)
+	}
+	parentDir, err := GetUnwrap(ctx, storage, dstDirPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to get dir [%s]", dstDirPath)
+	}
+	newObj, err := uploader.CompleteUploadSession(ctx, parentDir, model.UploadCompleteArgs{
+		Name:  session.Name,
+		Size:  session.Size,
+		ID:    session.ID,
+		Parts: parts,
+	})
+	if err != nil {
+		return errors.WithMessage(err, "failed complete upload session")
+	}
+	uploadSessionCache.Del(token)
+	if timer, ok := uploadSessionTimers.Load(token); ok {
+		timer.Stop()
+		uploadSessionTimers.Delete(token)
+	}
+	log.Debugf("direct upload file [%s] done", name)
+	if newObj == nil {
+		ClearCache(storage, dstDirPath)
+		return nil
+	}
+	if s, ok := newObj.(model.SetPath); ok && newObj.GetPath() == "" && parentDir.GetPath() != "" {
+		s.SetPath(stdpath.Join(parentDir.GetPath(), newObj.GetName()))
+	}
+	addCacheObj(storage, dstDirPath, model.WrapObjName(newObj))
+	delLinkCache(Key(storage, stdpath.Join(dstDirPath, name)))
+	return nil
+}
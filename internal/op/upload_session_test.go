@@ -0,0 +1,92 @@
+package op
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+type fakeUploader struct {
+	fakeDriver
+	expires   time.Time
+	completed model.UploadCompleteArgs
+	aborted   chan model.UploadCompleteArgs
+}
+
+func (d *fakeUploader) CreateUploadSession(ctx context.Context, dstDir model.Obj, args model.UploadSessionArgs) (*model.UploadSession, error) {
+	return &model.UploadSession{ID: "upload-id", Method: "PUT", URLs: []string{"http://example.com/" + args.Name}, Expires: d.expires}, nil
+}
+
+func (d *fakeUploader) CompleteUploadSession(ctx context.Context, dstDir model.Obj, args model.UploadCompleteArgs) (model.Obj, error) {
+	d.completed = args
+	return &model.Object{Name: args.Name, Size: args.Size}, nil
+}
+
+func (d *fakeUploader) AbortUploadSession(ctx context.Context, dstDir model.Obj, args model.UploadCompleteArgs) error {
+	d.aborted <- args
+	return nil
+}
+
+func TestUploadSession(t *testing.T) {
+	ctx := context.Background()
+	storage := &fakeUploader{fakeDriver: fakeDriver{Storage: model.Storage{MountPath: "/fake_upload"}}}
+	token, session, err := CreateUploadSession(ctx, storage, "/", model.UploadSessionArgs{Name: "a.bin", Size: 10})
+	if err != nil {
+		t.Fatalf("failed to create upload session: %+v", err)
+	}
+	if token == "" || len(session.URLs) != 1 {
+		t.Fatalf("unexpected session: %s %+v", token, session)
+	}
+	err = CompleteUploadSession(ctx, storage, "/", "b.bin", token, nil)
+	if !errors.Is(errors.Cause(err), errs.UploadSessionNotFound) {
+		t.Errorf("expect session not found for other file, got %+v", err)
+	}
+	parts := []model.UploadPart{{Number: 1, ETag: "etag"}}
+	if err = CompleteUploadSession(ctx, storage, "/", "a.bin", token, parts); err != nil {
+		t.Fatalf("failed to complete upload session: %+v", err)
+	}
+	if storage.completed.ID != "upload-id" || storage.completed.Size != 10 || len(storage.completed.Parts) != 1 {
+		t.Errorf("unexpected complete args: %+v", storage.completed)
+	}
+	err = CompleteUploadSession(ctx, storage, "/", "a.bin", token, parts)
+	if !errors.Is(errors.Cause(err), errs.UploadSessionNotFound) {
+		t.Errorf("expect session not found after completed, got %+v", err)
+	}
+}
+
+func TestUploadSessionAbort(t *testing.T) {
+	ctx := context.Background()
+	storage := &fakeUploader{
+		fakeDriver: fakeDriver{Storage: model.Storage{MountPath: "/fake_abort"}},
+		expires:    time.Now().Add(100 * time.Millisecond),
+		aborted:    make(chan model.UploadCompleteArgs, 2),
+	}
+	token, _, err := CreateUploadSession(ctx, storage, "/", model.UploadSessionArgs{Name: "done.bin", Size: 10})
+	if err != nil {
+		t.Fatalf("failed to create upload session: %+v", err)
+	}
+	if err = CompleteUploadSession(ctx, storage, "/", "done.bin", token, nil); err != nil {
+		t.Fatalf("failed to complete upload session: %+v", err)
+	}
+	storage.expires = time.Now().Add(100 * time.Millisecond)
+	if _, _, err = CreateUploadSession(ctx, storage, "/", model.UploadSessionArgs{Name: "expired.bin", Size: 10}); err != nil {
+		t.Fatalf("failed to create upload session: %+v", err)
+	}
+	select {
+	case args := <-storage.aborted:
+		if args.Name != "expired.bin" || args.ID != "upload-id" {
+			t.Errorf("unexpected abort args: %+v", args)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expired upload session not aborted")
+	}
+	select {
+	case args := <-storage.aborted:
+		t.Errorf("completed upload session aborted: %+v", args)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -3,7 +3,7 @@ package op
 import (
 	"time"
 
-	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/cache"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -11,7 +11,7 @@ import (
 	"github.com/alist-org/alist/v3/pkg/utils"
 )
 
-var userCache = cache.NewMemCache(cache.WithShards[*model.User](2))
+var userCache = cache.New[*model.User]("user", 2)
 var userG singleflight.Group[*model.User]
 var guestUser *model.User
 var adminUser *model.User
@@ -54,7 +54,7 @@ func GetUserByName(username string) (*model.User, error) {
 		if err != nil {
 			return nil, err
 		}
-		userCache.Set(username, _user, cache.WithEx[*model.User](time.Hour))
+		userCache.Set(username, _user, time.Hour)
 		return _user, nil
 	})
 	return user, err
@@ -82,6 +82,9 @@ func DeleteUserById(id uint) error {
 		return errs.DeleteAdminOrGuest
 	}
 	userCache.Del(old.Username)
+	if err := db.DeleteTagsByUser(id); err != nil {
+		return err
+	}
 	return db.DeleteUserById(id)
 }
 
@@ -0,0 +1,248 @@
+package photo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+var errNoExif = errors.New("no exif found")
+
+// the tiff based raw files keep the ifds at the start, so only the head is read
+const tiffHeadSize = 1024 * 1024
+
+type exifInfo struct {
+	TakenAt     *time.Time
+	Make        string
+	Model       string
+	Orientation int
+	Latitude    *float64
+	Longitude   *float64
+}
+
+const (
+	tagMake               = 0x010f
+	tagModel              = 0x0110
+	tagOrientation        = 0x0112
+	tagDateTime           = 0x0132
+	tagExifIFD            = 0x8769
+	tagGPSIFD             = 0x8825
+	tagDateTimeOriginal   = 0x9003
+	tagOffsetTimeOriginal = 0x9011
+	tagGPSLatitudeRef     = 0x0001
+	tagGPSLatitude        = 0x0002
+	tagGPSLongitudeRef    = 0x0003
+	tagGPSLongitude       = 0x0004
+)
+
+// readExif reads the exif of a jpeg or tiff based file
+func readExif(r io.ReadSeeker) (*exifInfo, error) {
+	head := make([]byte, 4)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return nil, err
+	}
+	switch {
+	case head[0] == 0xff && head[1] == 0xd8:
+		return readJpegExif(r, head[2:])
+	case string(head) == "II*\x00" || string(head) == "MM\x00*":
+		b := make([]byte, tiffHeadSize)
+		copy(b, head)
+		n, err := io.ReadFull(r, b[4:])
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, err
+		}
+		return parseTiff(b[:4+n])
+	}
+	return nil, errNoExif
+}
+
+// readJpegExif finds the APP1 segment of exif before the image data
+func readJpegExif(r io.ReadSeeker, marker []byte) (*exifInfo, error) {
+	header := make([]byte, 4)
+	copy(header, marker)
+	if _, err := io.ReadFull(r, header[2:]); err != nil {
+		return nil, err
+	}
+	for {
+		if header[0] != 0xff {
+			return nil, errNoExif
+		}
+		length := int64(binary.BigEndian.Uint16(header[2:])) - 2
+		switch {
+		// start of scan, there is no more metadata
+		case header[1] == 0xda || length < 0:
+			return nil, errNoExif
+		case header[1] == 0xe1 && length > 6:
+			data := make([]byte, length)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return nil, err
+			}
+			if bytes.HasPrefix(data, []byte("Exif\x00\x00")) {
+				return parseTiff(data[6:])
+			}
+		default:
+			if _, err := r.Seek(length, io.SeekCurrent); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, err
+		}
+	}
+}
+
+type ifdEntry struct {
+	typ   uint16
+	count uint32
+	// value is the value itself if it fits in 4 bytes, otherwise the offset of the value
+	value []byte
+}
+
+type tiff struct {
+	b  []byte
+	bo binary.ByteOrder
+}
+
+func (t *tiff) readIFD(offset uint32) map[uint16]ifdEntry {
+	entries := make(map[uint16]ifdEntry)
+	if int64(offset)+2 > int64(len(t.b)) {
+		return entries
+	}
+	n := int(t.bo.Uint16(t.b[offset:]))
+	for i := 0; i < n; i++ {
+		p := int(offset) + 2 + i*12
+		if p+12 > len(t.b) {
+			break
+		}
+		entries[t.bo.Uint16(t.b[p:])] = ifdEntry{
+			typ:   t.bo.Uint16(t.b[p+2:]),
+			count: t.bo.Uint32(t.b[p+4:]),
+			value: t.b[p+8 : p+12],
+		}
+	}
+	return entries
+}
+
+// data returns the bytes of the value of the entry, nil if it's out of range
+func (t *tiff) data(e ifdEntry, size int) []byte {
+	total := int64(e.count) * int64(size)
+	if total <= 4 {
+		return e.value[:total]
+	}
+	offset := int64(t.bo.Uint32(e.value))
+	if offset+total > int64(len(t.b)) {
+		return nil
+	}
+	return t.b[offset : offset+total]
+}
+
+func (t *tiff) str(entries map[uint16]ifdEntry, tag uint16) string {
+	e, ok := entries[tag]
+	if !ok || e.typ != 2 {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(t.data(e, 1)), "\x00"))
+}
+
+func (t *tiff) uint(entries map[uint16]ifdEntry, tag uint16) (uint32, bool) {
+	e, ok := entries[tag]
+	if !ok || e.count < 1 {
+		return 0, false
+	}
+	switch e.typ {
+	case 3:
+		return uint32(t.bo.Uint16(e.value)), true
+	case 4:
+		return t.bo.Uint32(e.value), true
+	}
+	return 0, false
+}
+
+func (t *tiff) rationals(entries map[uint16]ifdEntry, tag uint16) []float64 {
+	e, ok := entries[tag]
+	if !ok || e.typ != 5 {
+		return nil
+	}
+	b := t.data(e, 8)
+	var res []float64
+	for i := 0; i+8 <= len(b); i += 8 {
+		num, den := t.bo.Uint32(b[i:]), t.bo.Uint32(b[i+4:])
+		if den == 0 {
+			return nil
+		}
+		res = append(res, float64(num)/float64(den))
+	}
+	return res
+}
+
+func parseTiff(b []byte) (*exifInfo, error) {
+	if len(b) < 8 {
+		return nil, errNoExif
+	}
+	t := &tiff{b: b}
+	switch string(b[:2]) {
+	case "II":
+		t.bo = binary.LittleEndian
+	case "MM":
+		t.bo = binary.BigEndian
+	default:
+		return nil, errNoExif
+	}
+	ifd0 := t.readIFD(t.bo.Uint32(b[4:]))
+	info := &exifInfo{
+		Make:  t.str(ifd0, tagMake),
+		Model: t.str(ifd0, tagModel),
+	}
+	if o, ok := t.uint(ifd0, tagOrientation); ok {
+		info.Orientation = int(o)
+	}
+	date, offset := t.str(ifd0, tagDateTime), ""
+	if p, ok := t.uint(ifd0, tagExifIFD); ok {
+		exif := t.readIFD(p)
+		if d := t.str(exif, tagDateTimeOriginal); d != "" {
+			date, offset = d, t.str(exif, tagOffsetTimeOriginal)
+		}
+	}
+	info.TakenAt = parseExifTime(date, offset)
+	if p, ok := t.uint(ifd0, tagGPSIFD); ok {
+		gps := t.readIFD(p)
+		info.Latitude = gpsCoord(t.rationals(gps, tagGPSLatitude), t.str(gps, tagGPSLatitudeRef), "S")
+		info.Longitude = gpsCoord(t.rationals(gps, tagGPSLongitude), t.str(gps, tagGPSLongitudeRef), "W")
+	}
+	return info, nil
+}
+
+// parseExifTime parses the time without zone in the offset, or in the local zone if the offset is unknown
+func parseExifTime(date, offset string) *time.Time {
+	if date == "" {
+		return nil
+	}
+	var (
+		t   time.Time
+		err error
+	)
+	if offset != "" {
+		t, err = time.Parse("2006:01:02 15:04:05-07:00", date+offset)
+	}
+	if offset == "" || err != nil {
+		t, err = time.ParseInLocation("2006:01:02 15:04:05", date, time.Local)
+	}
+	if err != nil || t.Year() <= 1 {
+		return nil
+	}
+	return &t
+}
+
+func gpsCoord(dms []float64, ref, negativeRef string) *float64 {
+	if len(dms) != 3 {
+		return nil
+	}
+	v := dms[0] + dms[1]/60 + dms[2]/3600
+	if ref == negativeRef {
+		v = -v
+	}
+	return &v
+}
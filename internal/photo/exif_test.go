@@ -0,0 +1,133 @@
+package photo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+// buildTiff builds a little endian tiff with the ifd0, exif and gps ifds at fixed offsets
+func buildTiff() []byte {
+	bo := binary.LittleEndian
+	b := make([]byte, 1024)
+	copy(b, "II*\x00")
+	bo.PutUint32(b[4:], 8)
+	// the values are put after 512
+	dataOffset := uint32(512)
+	putData := func(v []byte) uint32 {
+		offset := dataOffset
+		copy(b[offset:], v)
+		dataOffset += uint32(len(v))
+		return offset
+	}
+	writeIFD := func(offset uint32, entries [][3]uint32, values map[uint16][]byte) {
+		bo.PutUint16(b[offset:], uint16(len(entries)))
+		for i, e := range entries {
+			p := offset + 2 + uint32(i)*12
+			bo.PutUint16(b[p:], uint16(e[0]))
+			bo.PutUint16(b[p+2:], uint16(e[1]))
+			bo.PutUint32(b[p+4:], e[2])
+			if v, ok := values[uint16(e[0])]; ok {
+				if len(v) <= 4 {
+					copy(b[p+8:], v)
+				} else {
+					bo.PutUint32(b[p+8:], putData(v))
+				}
+			}
+		}
+	}
+	u32 := func(v uint32) []byte {
+		r := make([]byte, 4)
+		bo.PutUint32(r, v)
+		return r
+	}
+	u16 := func(v uint16) []byte {
+		r := make([]byte, 2)
+		bo.PutUint16(r, v)
+		return r
+	}
+	rationals := func(vs ...uint32) []byte {
+		var r []byte
+		for i := 0; i < len(vs); i += 2 {
+			r = append(r, u32(vs[i])...)
+			r = append(r, u32(vs[i+1])...)
+		}
+		return r
+	}
+	writeIFD(8, [][3]uint32{
+		{tagMake, 2, 6},
+		{tagModel, 2, 8},
+		{tagOrientation, 3, 1},
+		{tagDateTime, 2, 20},
+		{tagExifIFD, 4, 1},
+		{tagGPSIFD, 4, 1},
+	}, map[uint16][]byte{
+		tagMake:        []byte("Canon\x00"),
+		tagModel:       []byte("EOS R5 \x00"),
+		tagOrientation: u16(6),
+		tagDateTime:    []byte("2020:01:01 00:00:00\x00"),
+		tagExifIFD:     u32(200),
+		tagGPSIFD:      u32(300),
+	})
+	writeIFD(200, [][3]uint32{
+		{tagDateTimeOriginal, 2, 20},
+		{tagOffsetTimeOriginal, 2, 7},
+	}, map[uint16][]byte{
+		tagDateTimeOriginal:   []byte("2023:06:15 10:20:30\x00"),
+		tagOffsetTimeOriginal: []byte("+08:00\x00"),
+	})
+	writeIFD(300, [][3]uint32{
+		{tagGPSLatitudeRef, 2, 2},
+		{tagGPSLatitude, 5, 3},
+		{tagGPSLongitudeRef, 2, 2},
+		{tagGPSLongitude, 5, 3},
+	}, map[uint16][]byte{
+		tagGPSLatitudeRef:  []byte("S\x00"),
+		tagGPSLatitude:     rationals(33, 1, 30, 1, 0, 1),
+		tagGPSLongitudeRef: []byte("E\x00"),
+		tagGPSLongitude:    rationals(151, 1, 12, 1, 3600, 100),
+	})
+	return b
+}
+
+func TestReadExif(t *testing.T) {
+	tiff := buildTiff()
+	app1 := append([]byte("Exif\x00\x00"), tiff...)
+	var jpeg bytes.Buffer
+	jpeg.Write([]byte{0xff, 0xd8})
+	// an APP0 segment before the exif
+	jpeg.Write([]byte{0xff, 0xe0, 0x00, 0x04, 0x00, 0x00})
+	jpeg.Write([]byte{0xff, 0xe1})
+	_ = binary.Write(&jpeg, binary.BigEndian, uint16(len(app1)+2))
+	jpeg.Write(app1)
+	jpeg.Write([]byte{0xff, 0xda, 0x00, 0x02})
+
+	for name, data := range map[string][]byte{"jpeg": jpeg.Bytes(), "tiff": tiff} {
+		info, err := readExif(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: failed read exif: %+v", name, err)
+		}
+		if info.Make != "Canon" || info.Model != "EOS R5" || info.Orientation != 6 {
+			t.Errorf("%s: unexpected camera: %+v", name, info)
+		}
+		want := time.Date(2023, 6, 15, 2, 20, 30, 0, time.UTC)
+		if info.TakenAt == nil || !info.TakenAt.Equal(want) {
+			t.Errorf("%s: expect taken at %s, got %v", name, want, info.TakenAt)
+		}
+		if info.Latitude == nil || math.Abs(*info.Latitude+33.5) > 1e-9 {
+			t.Errorf("%s: unexpected latitude: %v", name, info.Latitude)
+		}
+		if info.Longitude == nil || math.Abs(*info.Longitude-151.21) > 1e-9 {
+			t.Errorf("%s: unexpected longitude: %v", name, info.Longitude)
+		}
+	}
+
+	if _, err := readExif(bytes.NewReader([]byte{0xff, 0xd8, 0xff, 0xda, 0x00, 0x02})); err != errNoExif {
+		t.Errorf("expect no exif, got %v", err)
+	}
+	if _, err := readExif(bytes.NewReader([]byte("\x89PNG\r\n\x1a\n"))); err != errNoExif {
+		t.Errorf("expect no exif, got %v", err)
+	}
+}
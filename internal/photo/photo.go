@@ -0,0 +1,162 @@
+package photo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	stdpath "path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/xhofe/tache"
+)
+
+// ScanTask reads the exif of the images in the path into the photo timeline
+type ScanTask struct {
+	task.TaskWithCreator
+	Status      string   `json:"-"`
+	Path        string   `json:"path"`
+	MaxDepth    int      `json:"max_depth"`
+	IgnorePaths []string `json:"ignore_paths"`
+	scanned     int
+	failed      int
+}
+
+func (t *ScanTask) GetName() string {
+	return fmt.Sprintf("scan photos in %s", t.Path)
+}
+
+func (t *ScanTask) GetStatus() string {
+	return t.Status
+}
+
+func (t *ScanTask) Run() error {
+	start := time.Now()
+	t.scanned, t.failed = 0, 0
+	root, err := fs.Get(t.Ctx(), t.Path, &fs.GetArgs{})
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s]", t.Path)
+	}
+	err = fs.WalkFS(t.Ctx(), t.MaxDepth, t.Path, root, func(reqPath string, info model.Obj) error {
+		if err := t.Ctx().Err(); err != nil {
+			return err
+		}
+		for _, ignorePath := range t.IgnorePaths {
+			if strings.HasPrefix(reqPath, ignorePath) {
+				return filepath.SkipDir
+			}
+		}
+		if info.IsDir() || utils.GetFileType(info.GetName()) != conf.IMAGE {
+			return nil
+		}
+		if err := scanFile(t.Ctx(), reqPath, info, start); err != nil {
+			log.Warnf("failed scan photo %s: %+v", reqPath, err)
+			t.failed++
+		} else {
+			t.scanned++
+		}
+		t.Status = fmt.Sprintf("scanned %d photos, %d failed", t.scanned, t.failed)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// the photos not found are removed only if the path is scanned completely
+	return db.DeletePhotoMetasScannedBefore(t.Path, start)
+}
+
+var ScanTaskManager *tache.Manager[*ScanTask]
+
+// AddScanTask scans the photos in path, the running scan of the same path is returned if exists
+func AddScanTask(ctx context.Context, path string, maxDepth int, ignorePaths []string) task.TaskInfoWithCreator {
+	path = utils.FixAndCleanPath(path)
+	running := ScanTaskManager.GetByCondition(func(t *ScanTask) bool {
+		return t.Path == path &&
+			utils.SliceContains([]tache.State{tache.StatePending, tache.StateRunning, tache.StateWaitingRetry}, t.GetState())
+	})
+	if len(running) > 0 {
+		return running[0]
+	}
+	taskCreator, _ := ctx.Value("user").(*model.User) // taskCreator is nil when convert failed
+	t := &ScanTask{
+		TaskWithCreator: task.TaskWithCreator{
+			Creator: taskCreator,
+		},
+		Path:        path,
+		MaxDepth:    maxDepth,
+		IgnorePaths: ignorePaths,
+	}
+	ScanTaskManager.Add(t)
+	return t
+}
+
+func scanFile(ctx context.Context, path string, obj model.Obj, start time.Time) error {
+	parent, name := stdpath.Split(path)
+	parent = utils.FixAndCleanPath(parent)
+	old, err := db.GetPhotoMeta(parent, name)
+	if err == nil && old.Size == obj.GetSize() && old.Modified.Unix() == obj.ModTime().Unix() {
+		return db.TouchPhotoMeta(old.ID, start)
+	}
+	meta := &model.PhotoMeta{
+		Parent:    parent,
+		Name:      name,
+		Size:      obj.GetSize(),
+		Modified:  obj.ModTime(),
+		TakenAt:   obj.ModTime(),
+		ScannedAt: start,
+	}
+	if old != nil {
+		meta.ID = old.ID
+	}
+	info, err := readFileExif(ctx, path, obj)
+	if err != nil && !errors.Is(err, errNoExif) {
+		return err
+	}
+	if info != nil {
+		if info.TakenAt != nil {
+			meta.TakenAt = *info.TakenAt
+		}
+		meta.Make = info.Make
+		meta.Model = info.Model
+		meta.Orientation = info.Orientation
+		meta.Latitude = info.Latitude
+		meta.Longitude = info.Longitude
+	}
+	return db.SavePhotoMeta(meta)
+}
+
+// readFileExif reads the exif of the file by ranged reads
+func readFileExif(ctx context.Context, path string, obj model.Obj) (*exifInfo, error) {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage")
+	}
+	link, _, err := op.Link(ctx, storage, actualPath, model.LinkArgs{
+		Header: http.Header{},
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get link")
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{
+		Obj: obj,
+		Ctx: ctx,
+	}, link)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get stream")
+	}
+	defer ss.Close()
+	r := stream.NewRangeReadSeeker(ss, obj.GetSize())
+	defer r.Close()
+	return readExif(r)
+}
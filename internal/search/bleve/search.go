@@ -3,6 +3,8 @@ package bleve
 import (
 	"context"
 	"os"
+	"path"
+	"time"
 
 	query2 "github.com/blevesearch/bleve/v2/search/query"
 
@@ -35,28 +37,154 @@ func (b *Bleve) Search(ctx context.Context, req model.SearchReq) ([]model.Search
 		isDirQuery := bleve.NewBoolFieldQuery(isDir)
 		queries = append(queries, isDirQuery)
 	}
+	queries = append(queries, filterQueries(req)...)
 	reqQuery := bleve.NewConjunctionQuery(queries...)
 	search := bleve.NewSearchRequest(reqQuery)
-	search.SortBy([]string{"name"})
+	search.SortBy([]string{sortField(req)})
 	search.From = (req.Page - 1) * req.PerPage
 	search.Size = req.PerPage
 	search.Fields = []string{"*"}
+	tagged := len(req.TaggedPaths) > 0
+	if tagged {
+		// the tagged paths are matched loosely as the parent is analyzed,
+		// so all hits are got and filtered by the exact paths before paginated
+		search.Size = 0
+		countResults, err := b.BIndex.Search(search)
+		if err != nil {
+			log.Errorf("search error: %+v", err)
+			return nil, 0, err
+		}
+		search.From, search.Size = 0, int(countResults.Total)
+	}
 	searchResults, err := b.BIndex.Search(search)
 	if err != nil {
 		log.Errorf("search error: %+v", err)
 		return nil, 0, err
 	}
 	res, err := utils.SliceConvert(searchResults.Hits, func(src *search2.DocumentMatch) (model.SearchNode, error) {
-		return model.SearchNode{
+		node := model.SearchNode{
 			Parent: src.Fields["parent"].(string),
 			Name:   src.Fields["name"].(string),
 			IsDir:  src.Fields["is_dir"].(bool),
 			Size:   int64(src.Fields["size"].(float64)),
-		}, nil
+		}
+		// fields below may be absent in index built by old version
+		if modified, ok := src.Fields["modified"].(string); ok {
+			node.Modified, _ = time.Parse(time.RFC3339, modified)
+		}
+		if fileType, ok := src.Fields["file_type"].(float64); ok {
+			node.FileType = int(fileType)
+		}
+		if ext, ok := src.Fields["ext"].(string); ok {
+			node.Ext = ext
+		}
+		return node, nil
 	})
+	if tagged {
+		return filterTagged(res, req)
+	}
 	return res, int64(searchResults.Total), nil
 }
 
+// filterTagged keeps the nodes of the exact tagged paths and paginates them
+func filterTagged(nodes []model.SearchNode, req model.SearchReq) ([]model.SearchNode, int64, error) {
+	paths := make(map[string]struct{}, len(req.TaggedPaths))
+	for _, p := range req.TaggedPaths {
+		paths[utils.FixAndCleanPath(p)] = struct{}{}
+	}
+	filtered := make([]model.SearchNode, 0, len(nodes))
+	for _, node := range nodes {
+		if _, ok := paths[path.Join(utils.FixAndCleanPath(node.Parent), node.Name)]; ok {
+			filtered = append(filtered, node)
+		}
+	}
+	total := len(filtered)
+	start := (req.Page - 1) * req.PerPage
+	if start < 0 || start > total {
+		start = total
+	}
+	end := total
+	if req.PerPage < total-start {
+		end = start + req.PerPage
+	}
+	return filtered[start:end], int64(total), nil
+}
+
+func filterQueries(req model.SearchReq) []query2.Query {
+	var queries []query2.Query
+	inclusive := true
+	if req.MinSize > 0 || req.MaxSize > 0 {
+		var min, max *float64
+		if req.MinSize > 0 {
+			v := float64(req.MinSize)
+			min = &v
+		}
+		if req.MaxSize > 0 {
+			v := float64(req.MaxSize)
+			max = &v
+		}
+		sizeQuery := bleve.NewNumericRangeInclusiveQuery(min, max, &inclusive, &inclusive)
+		sizeQuery.SetField("size")
+		queries = append(queries, sizeQuery)
+	}
+	if req.ModifiedAfter != nil || req.ModifiedBefore != nil {
+		var start, end time.Time
+		if req.ModifiedAfter != nil {
+			start = *req.ModifiedAfter
+		}
+		if req.ModifiedBefore != nil {
+			end = *req.ModifiedBefore
+		}
+		modifiedQuery := bleve.NewDateRangeInclusiveQuery(start, end, &inclusive, &inclusive)
+		modifiedQuery.SetField("modified")
+		queries = append(queries, modifiedQuery)
+	}
+	if len(req.FileTypes) > 0 {
+		var typeQueries []query2.Query
+		for _, fileType := range req.FileTypes {
+			v := float64(fileType)
+			typeQuery := bleve.NewNumericRangeInclusiveQuery(&v, &v, &inclusive, &inclusive)
+			typeQuery.SetField("file_type")
+			typeQueries = append(typeQueries, typeQuery)
+		}
+		queries = append(queries, bleve.NewDisjunctionQuery(typeQueries...))
+	}
+	if len(req.Exts) > 0 {
+		var extQueries []query2.Query
+		for _, ext := range req.Exts {
+			extQuery := bleve.NewTermQuery(ext)
+			extQuery.SetField("ext")
+			extQueries = append(extQueries, extQuery)
+		}
+		queries = append(queries, bleve.NewDisjunctionQuery(extQueries...))
+	}
+	if len(req.TaggedPaths) > 0 {
+		var taggedQueries []query2.Query
+		for _, p := range req.TaggedPaths {
+			parent, name := path.Split(p)
+			nameQuery := bleve.NewTermQuery(name)
+			nameQuery.SetField("name")
+			// the parent is analyzed, so the phrase is the closest match of it
+			parentQuery := bleve.NewMatchPhraseQuery(utils.FixAndCleanPath(parent))
+			parentQuery.SetField("parent")
+			taggedQueries = append(taggedQueries, bleve.NewConjunctionQuery(nameQuery, parentQuery))
+		}
+		queries = append(queries, bleve.NewDisjunctionQuery(taggedQueries...))
+	}
+	return queries
+}
+
+func sortField(req model.SearchReq) string {
+	field := "name"
+	if req.OrderBy != "" {
+		field = req.OrderBy
+	}
+	if req.OrderDirection == "desc" {
+		return "-" + field
+	}
+	return field
+}
+
 func (b *Bleve) Index(ctx context.Context, node model.SearchNode) error {
 	return b.BIndex.Index(uuid.NewString(), node)
 }
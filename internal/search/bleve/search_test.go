@@ -0,0 +1,40 @@
+package bleve
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestSearchTagged(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "index")
+	index, err := Init(&indexPath)
+	if err != nil {
+		t.Fatalf("failed init index: %+v", err)
+	}
+	b := &Bleve{BIndex: index}
+	defer b.Release(context.Background())
+	nodes := []model.SearchNode{
+		{Parent: "/a/b", Name: "x.txt"},
+		{Parent: "/a/b/c", Name: "x.txt"},
+		{Parent: "/z/a/b", Name: "x.txt"},
+		{Parent: "/a/b", Name: "y.txt"},
+	}
+	if err = b.BatchIndex(context.Background(), nodes); err != nil {
+		t.Fatalf("failed index: %+v", err)
+	}
+	req := model.SearchReq{
+		Keywords:    "x.txt",
+		TaggedPaths: []string{"/a/b/x.txt", "/a/b/y.txt"},
+		PageReq:     model.PageReq{Page: 1, PerPage: 10},
+	}
+	res, total, err := b.Search(context.Background(), req)
+	if err != nil {
+		t.Fatalf("failed search: %+v", err)
+	}
+	if total != 1 || len(res) != 1 || res[0].Parent != "/a/b" || res[0].Name != "x.txt" {
+		t.Errorf("unexpected tagged result: %d %+v", total, res)
+	}
+}
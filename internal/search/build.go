@@ -14,6 +14,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/photo"
 	"github.com/alist-org/alist/v3/internal/search/searcher"
 	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/pkg/mq"
@@ -147,9 +148,13 @@ func BuildIndex(ctx context.Context, indexPaths, ignorePaths []string, maxDepth
 			IsDone:   false,
 		})
 	}
+	// cancel the recursive listing of storages when StopIndex api called
+	walkCtx, cancel := context.WithCancel(context.WithValue(ctx, "user", admin))
+	defer cancel()
 	for _, indexPath := range indexPaths {
 		walkFn := func(indexPath string, info model.Obj) error {
 			if !running.Load() {
+				cancel()
 				return filepath.SkipDir
 			}
 			for _, avoidPath := range ignorePaths {
@@ -174,11 +179,16 @@ func BuildIndex(ctx context.Context, indexPaths, ignorePaths []string, maxDepth
 			return err
 		}
 		// TODO: run walkFS concurrently
-		err = fs.WalkFS(context.WithValue(ctx, "user", admin), maxDepth, indexPath, fi, walkFn)
+		err = fs.WalkFS(walkCtx, maxDepth, indexPath, fi, walkFn)
 		if err != nil {
 			return err
 		}
 	}
+	if setting.GetBool(conf.IndexPhotoExif) {
+		for _, indexPath := range indexPaths {
+			photo.AddScanTask(walkCtx, indexPath, maxDepth, ignorePaths)
+		}
+	}
 	return nil
 }
 
@@ -195,7 +205,7 @@ func Config(ctx context.Context) searcher.Config {
 }
 
 func Update(parent string, objs []model.Obj) {
-	if instance == nil || !instance.Config().AutoUpdate || !setting.GetBool(conf.AutoUpdateIndex) || Running() {
+	if instance == nil || !instance.Config().AutoUpdate || !setting.GetBool(conf.AutoUpdateIndex) || Running() || jobCovers(parent) {
 		return
 	}
 	if isIgnorePath(parent) {
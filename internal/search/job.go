@@ -0,0 +1,322 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	jobCrons   = map[uint]*cron.Cron{}
+	jobCronsMu sync.Mutex
+	// only one job runs at a time
+	jobMu      sync.Mutex
+	jobRunning = atomic.Bool{}
+	jobCancel  = atomic.Pointer[context.CancelFunc]{}
+	// jobPath is the path of the running job
+	jobPath = atomic.Pointer[string]{}
+)
+
+// JobRunning reports whether an index job is running
+func JobRunning() bool {
+	return jobRunning.Load()
+}
+
+// jobCovers reports whether the path is indexed by the running job,
+// the auto update of it is skipped as the job updates it anyway
+func jobCovers(path string) bool {
+	p := jobPath.Load()
+	return p != nil && utils.IsSubPath(*p, path)
+}
+
+// InitIndexJobs schedules all enabled index jobs
+func InitIndexJobs() {
+	jobs, err := db.GetIndexJobs()
+	if err != nil {
+		log.Errorf("failed get index jobs: %+v", err)
+		return
+	}
+	for i := range jobs {
+		if !jobs[i].Progress.IsDone {
+			// the job was interrupted by shutdown
+			jobs[i].Progress.IsDone = true
+			_ = db.UpdateIndexJobProgress(jobs[i].ID, &jobs[i].Progress)
+		}
+		scheduleIndexJob(&jobs[i])
+	}
+}
+
+func GetIndexJobs() ([]model.IndexJob, error) {
+	return db.GetIndexJobs()
+}
+
+func GetIndexJobById(id uint) (*model.IndexJob, error) {
+	return db.GetIndexJobById(id)
+}
+
+func CreateIndexJob(job *model.IndexJob) error {
+	job.Path = utils.FixAndCleanPath(job.Path)
+	job.Progress = model.IndexProgress{IsDone: true}
+	if err := db.CreateIndexJob(job); err != nil {
+		return err
+	}
+	scheduleIndexJob(job)
+	return nil
+}
+
+func UpdateIndexJob(job *model.IndexJob) error {
+	job.Path = utils.FixAndCleanPath(job.Path)
+	if err := db.UpdateIndexJob(job); err != nil {
+		return err
+	}
+	scheduleIndexJob(job)
+	return nil
+}
+
+func DeleteIndexJobById(id uint) error {
+	unscheduleIndexJob(id)
+	return db.DeleteIndexJobById(id)
+}
+
+func scheduleIndexJob(job *model.IndexJob) {
+	unscheduleIndexJob(job.ID)
+	if job.Disabled || job.Interval <= 0 {
+		return
+	}
+	id := job.ID
+	c := cron.NewCron(time.Duration(job.Interval) * time.Minute)
+	c.Do(func() {
+		if err := RunIndexJobById(context.Background(), id); err != nil {
+			log.Errorf("run index job [%d] error: %+v", id, err)
+		}
+	})
+	jobCronsMu.Lock()
+	jobCrons[id] = c
+	jobCronsMu.Unlock()
+}
+
+func unscheduleIndexJob(id uint) {
+	jobCronsMu.Lock()
+	defer jobCronsMu.Unlock()
+	if c, ok := jobCrons[id]; ok {
+		c.Stop()
+		delete(jobCrons, id)
+	}
+}
+
+// StopIndexJob cancels the running index job
+func StopIndexJob() bool {
+	cancel := jobCancel.Load()
+	if cancel == nil {
+		return false
+	}
+	(*cancel)()
+	return true
+}
+
+func RunIndexJobById(ctx context.Context, id uint) error {
+	job, err := db.GetIndexJobById(id)
+	if err != nil {
+		return err
+	}
+	if job.Disabled {
+		return nil
+	}
+	return RunIndexJob(ctx, job)
+}
+
+// RunIndexJob syncs the index under job.Path with the storages incrementally:
+// for every folder it diffs the listing against the indexed nodes of that folder,
+// instead of clearing and rebuilding the whole index
+func RunIndexJob(ctx context.Context, job *model.IndexJob) error {
+	if instance == nil {
+		return errs.SearchNotAvailable
+	}
+	if !instance.Config().AutoUpdate {
+		return fmt.Errorf("incremental index is not supported by %s", instance.Config().Name)
+	}
+	if Running() {
+		return errs.BuildIndexIsRunning
+	}
+	if !jobMu.TryLock() {
+		return errs.BuildIndexIsRunning
+	}
+	defer jobMu.Unlock()
+	jobRunning.Store(true)
+	jobPath.Store(&job.Path)
+	defer func() {
+		jobPath.Store(nil)
+		jobRunning.Store(false)
+	}()
+	ctx, cancel := context.WithCancel(ctx)
+	jobCancel.Store(&cancel)
+	defer func() {
+		jobCancel.Store(nil)
+		cancel()
+	}()
+
+	admin, err := op.GetAdmin()
+	if err != nil {
+		return err
+	}
+	maxDepth := job.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = setting.GetInt(conf.MaxIndexDepth, 20)
+	}
+	r := &indexJobRunner{
+		job:         job,
+		ignorePaths: append(job.GetIgnorePaths(), conf.SlicesMap[conf.IgnorePaths]...),
+		progress:    model.IndexProgress{IsDone: false},
+	}
+	log.Infof("run index job [%d] for: %s", job.ID, job.Path)
+	r.writeProgress()
+	err = r.walk(context.WithValue(ctx, "user", admin), job.Path, maxDepth)
+	now := time.Now()
+	r.progress.IsDone = true
+	r.progress.LastDoneTime = &now
+	if err != nil {
+		r.progress.Error = err.Error()
+	} else if len(r.failedDirs) > 0 {
+		r.progress.Error = failedDirsError(r.failedDirs)
+	}
+	r.writeProgress()
+	log.Infof("index job [%d] done, changed count: %d", job.ID, r.progress.ObjCount)
+	return err
+}
+
+type indexJobRunner struct {
+	job         *model.IndexJob
+	ignorePaths []string
+	progress    model.IndexProgress
+	// folders failed to list, their old index is kept
+	failedDirs []string
+}
+
+// maxFailedDirs is the max number of the failed folders shown in the job status
+const maxFailedDirs = 10
+
+func failedDirsError(dirs []string) string {
+	shown := dirs
+	if len(shown) > maxFailedDirs {
+		shown = shown[:maxFailedDirs]
+	}
+	msg := fmt.Sprintf("failed list %d folders: %s", len(dirs), strings.Join(shown, ", "))
+	if len(dirs) > maxFailedDirs {
+		msg += ", ..."
+	}
+	return msg
+}
+
+func (r *indexJobRunner) writeProgress() {
+	if err := db.UpdateIndexJobProgress(r.job.ID, &r.progress); err != nil {
+		log.Errorf("save index job progress error: %+v", err)
+	}
+	r.job.Progress = r.progress
+}
+
+func (r *indexJobRunner) isIgnore(p string) bool {
+	for _, ignorePath := range r.ignorePaths {
+		if strings.HasPrefix(p, ignorePath) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *indexJobRunner) walk(ctx context.Context, dir string, depth int) error {
+	if depth <= 0 || r.isIgnore(dir) {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	meta, _ := op.GetNearestMeta(dir)
+	objs, err := fs.List(context.WithValue(ctx, "meta", meta), dir, &fs.ListArgs{Refresh: r.job.Refresh, NoLog: true})
+	if err != nil {
+		// keep the old index if the folder can't be listed temporarily
+		log.Warnf("index job [%d] failed list %s: %+v", r.job.ID, dir, err)
+		r.failedDirs = append(r.failedDirs, dir)
+		return nil
+	}
+	nodes, err := instance.Get(ctx, dir)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get index of %s", dir)
+	}
+	old := make(map[string]model.SearchNode, len(nodes))
+	for _, node := range nodes {
+		old[node.Name] = node
+	}
+	var (
+		toAdd []model.SearchNode
+		dirs  []string
+	)
+	for _, obj := range objs {
+		objPath := path.Join(dir, obj.GetName())
+		if r.isIgnore(objPath) {
+			continue
+		}
+		node, ok := old[obj.GetName()]
+		delete(old, obj.GetName())
+		if !ok || !sameNode(node, obj) {
+			if ok {
+				if err = instance.Del(ctx, objPath); err != nil {
+					return errors.WithMessagef(err, "failed del index of %s", objPath)
+				}
+			}
+			toAdd = append(toAdd, toSearchNode(dir, obj))
+		}
+		if obj.IsDir() {
+			dirs = append(dirs, objPath)
+		}
+	}
+	for name := range old {
+		objPath := path.Join(dir, name)
+		if op.HasStorage(objPath) {
+			continue
+		}
+		log.Debugf("delete index: %s", objPath)
+		if err = instance.Del(ctx, objPath); err != nil {
+			return errors.WithMessagef(err, "failed del index of %s", objPath)
+		}
+	}
+	if len(toAdd) > 0 {
+		if err = instance.BatchIndex(ctx, toAdd); err != nil {
+			return errors.WithMessagef(err, "failed index objs in %s", dir)
+		}
+		r.progress.ObjCount += uint64(len(toAdd))
+	}
+	for _, d := range dirs {
+		if err = r.walk(ctx, d, depth-1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sameNode(node model.SearchNode, obj model.Obj) bool {
+	if node.IsDir != obj.IsDir() {
+		return false
+	}
+	if node.IsDir {
+		return true
+	}
+	return node.Size == obj.GetSize() && node.Modified.Unix() == obj.ModTime().Unix()
+}
@@ -0,0 +1,182 @@
+package search
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+func scheduled(id uint) bool {
+	jobCronsMu.Lock()
+	defer jobCronsMu.Unlock()
+	_, ok := jobCrons[id]
+	return ok
+}
+
+func TestScheduleIndexJob(t *testing.T) {
+	job := &model.IndexJob{Path: "/schedule/", Interval: 60}
+	if err := CreateIndexJob(job); err != nil {
+		t.Fatalf("failed create job: %+v", err)
+	}
+	if job.Path != "/schedule" || !job.Progress.IsDone {
+		t.Errorf("unexpected job created: %+v", job)
+	}
+	if !scheduled(job.ID) {
+		t.Errorf("expect the job scheduled")
+	}
+	job.Disabled = true
+	if err := UpdateIndexJob(job); err != nil {
+		t.Fatalf("failed update job: %+v", err)
+	}
+	if scheduled(job.ID) {
+		t.Errorf("expect the disabled job unscheduled")
+	}
+	job.Disabled, job.Interval = false, 0
+	if err := UpdateIndexJob(job); err != nil {
+		t.Fatalf("failed update job: %+v", err)
+	}
+	if scheduled(job.ID) {
+		t.Errorf("expect the manual job unscheduled")
+	}
+	job.Interval = 30
+	if err := UpdateIndexJob(job); err != nil {
+		t.Fatalf("failed update job: %+v", err)
+	}
+	if err := DeleteIndexJobById(job.ID); err != nil {
+		t.Fatalf("failed delete job: %+v", err)
+	}
+	if scheduled(job.ID) {
+		t.Errorf("expect the deleted job unscheduled")
+	}
+}
+
+func TestRunIndexJob(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.txt", "a")
+	write("sub/b.txt", "b")
+	write("sub/deep/c.txt", "c")
+	if err := db.CreateUser(&model.User{Username: "admin", Role: model.ADMIN}); err != nil {
+		t.Fatalf("failed create admin: %+v", err)
+	}
+	_, err := op.CreateStorage(context.Background(), model.Storage{
+		Driver:    "Local",
+		MountPath: "/idx",
+		Addition:  `{"root_folder_path":"` + filepath.ToSlash(root) + `"}`,
+	})
+	if err != nil {
+		t.Fatalf("failed create storage: %+v", err)
+	}
+	if err = Init("database_non_full_text"); err != nil {
+		t.Fatalf("failed init search: %+v", err)
+	}
+	defer func() {
+		_ = instance.Clear(context.Background())
+		instance = nil
+	}()
+	indexed := func() []string {
+		nodes, _, err := Search(context.Background(), model.SearchReq{Parent: "/idx", PageReq: model.PageReq{Page: 1, PerPage: 100}})
+		if err != nil {
+			t.Fatalf("failed search: %+v", err)
+		}
+		var paths []string
+		for _, node := range nodes {
+			paths = append(paths, strings.TrimPrefix(node.Parent+"/"+node.Name, "/idx/"))
+		}
+		return paths
+	}
+	has := func(paths []string, p string) bool {
+		for _, path := range paths {
+			if path == p {
+				return true
+			}
+		}
+		return false
+	}
+
+	job := &model.IndexJob{Path: "/idx", MaxDepth: 2, Refresh: true}
+	if err = CreateIndexJob(job); err != nil {
+		t.Fatalf("failed create job: %+v", err)
+	}
+	if err = RunIndexJob(context.Background(), job); err != nil {
+		t.Fatalf("failed run job: %+v", err)
+	}
+	// the files in sub/deep are beyond the depth
+	paths := indexed()
+	if len(paths) != 4 || !has(paths, "a.txt") || !has(paths, "sub/b.txt") || !has(paths, "sub/deep") {
+		t.Errorf("unexpected index: %v", paths)
+	}
+	if job.Progress.ObjCount != 4 || job.Progress.Error != "" {
+		t.Errorf("unexpected progress: %+v", job.Progress)
+	}
+
+	// only the changes are indexed in the next run
+	write("sub/b.txt", "bb")
+	write("d.txt", "d")
+	if err = os.Remove(filepath.Join(root, "a.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err = RunIndexJob(context.Background(), job); err != nil {
+		t.Fatalf("failed run job: %+v", err)
+	}
+	paths = indexed()
+	if len(paths) != 4 || has(paths, "a.txt") || !has(paths, "d.txt") {
+		t.Errorf("unexpected index: %v", paths)
+	}
+	if job.Progress.ObjCount != 2 {
+		t.Errorf("expect the updated and created files indexed, got %d", job.Progress.ObjCount)
+	}
+
+	// the folder failed to list is recorded in the status
+	missing := &model.IndexJob{Path: "/idx/missing", MaxDepth: 2}
+	if err = CreateIndexJob(missing); err != nil {
+		t.Fatalf("failed create job: %+v", err)
+	}
+	if err = RunIndexJob(context.Background(), missing); err != nil {
+		t.Fatalf("failed run job: %+v", err)
+	}
+	if !strings.Contains(missing.Progress.Error, "/idx/missing") {
+		t.Errorf("expect the failed folder recorded, got %q", missing.Progress.Error)
+	}
+}
+
+func TestJobCovers(t *testing.T) {
+	if jobCovers("/a") {
+		t.Errorf("expect nothing covered without running job")
+	}
+	p := "/a"
+	jobPath.Store(&p)
+	defer jobPath.Store(nil)
+	for path, expect := range map[string]bool{"/a": true, "/a/b": true, "/ab": false, "/b": false, "/": false} {
+		if got := jobCovers(path); got != expect {
+			t.Errorf("expect %s covered %v, got %v", path, expect, got)
+		}
+	}
+}
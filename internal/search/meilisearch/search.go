@@ -9,20 +9,95 @@ import (
 	"github.com/google/uuid"
 	"github.com/meilisearch/meilisearch-go"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 )
 
 type searchDocument struct {
 	ID string `json:"id"`
+	// unix timestamp of modified, meilisearch can only filter and sort numbers
+	ModifiedAt int64 `json:"modified_at"`
 	model.SearchNode
 }
 
+func toSearchNode(srcMap map[string]any) model.SearchNode {
+	node := model.SearchNode{
+		Parent: srcMap["parent"].(string),
+		Name:   srcMap["name"].(string),
+		IsDir:  srcMap["is_dir"].(bool),
+		Size:   int64(srcMap["size"].(float64)),
+	}
+	// fields below may be absent in documents indexed by old version
+	if modified, ok := srcMap["modified"].(string); ok {
+		node.Modified, _ = time.Parse(time.RFC3339, modified)
+	}
+	if fileType, ok := srcMap["file_type"].(float64); ok {
+		node.FileType = int(fileType)
+	}
+	if ext, ok := srcMap["ext"].(string); ok {
+		node.Ext = ext
+	}
+	return node
+}
+
+func quoteFilter(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "\\'") + "'"
+}
+
+func buildFilter(req model.SearchReq) string {
+	var filters []string
+	if req.Scope != 0 {
+		filters = append(filters, fmt.Sprintf("is_dir = %v", req.Scope == 1))
+	}
+	if req.MinSize > 0 {
+		filters = append(filters, fmt.Sprintf("size >= %d", req.MinSize))
+	}
+	if req.MaxSize > 0 {
+		filters = append(filters, fmt.Sprintf("size <= %d", req.MaxSize))
+	}
+	if req.ModifiedAfter != nil {
+		filters = append(filters, fmt.Sprintf("modified_at >= %d", req.ModifiedAfter.Unix()))
+	}
+	if req.ModifiedBefore != nil {
+		filters = append(filters, fmt.Sprintf("modified_at <= %d", req.ModifiedBefore.Unix()))
+	}
+	if len(req.FileTypes) > 0 {
+		types := utils.MustSliceConvert(req.FileTypes, func(src int) string {
+			return strconv.Itoa(src)
+		})
+		filters = append(filters, fmt.Sprintf("file_type IN [%s]", strings.Join(types, ",")))
+	}
+	if len(req.Exts) > 0 {
+		exts := utils.MustSliceConvert(req.Exts, quoteFilter)
+		filters = append(filters, fmt.Sprintf("ext IN [%s]", strings.Join(exts, ",")))
+	}
+	if len(req.TaggedPaths) > 0 {
+		// grouped by the parent, so the tagged objects in a folder are one term
+		var parents []string
+		names := make(map[string][]string)
+		for _, p := range req.TaggedPaths {
+			parent, name := path.Split(p)
+			parent = utils.FixAndCleanPath(parent)
+			if _, ok := names[parent]; !ok {
+				parents = append(parents, parent)
+			}
+			names[parent] = append(names[parent], quoteFilter(name))
+		}
+		tagged := utils.MustSliceConvert(parents, func(parent string) string {
+			return fmt.Sprintf("(parent = %s AND name IN [%s])", quoteFilter(parent), strings.Join(names[parent], ","))
+		})
+		filters = append(filters, "("+strings.Join(tagged, " OR ")+")")
+	}
+	return strings.Join(filters, " AND ")
+}
+
 type Meilisearch struct {
 	Client               *meilisearch.Client
 	IndexUid             string
 	FilterableAttributes []string
 	SearchableAttributes []string
+	SortableAttributes   []string
 }
 
 func (m *Meilisearch) Config() searcher.Config {
@@ -35,21 +110,26 @@ func (m *Meilisearch) Search(ctx context.Context, req model.SearchReq) ([]model.
 		Page:                 int64(req.Page),
 		HitsPerPage:          int64(req.PerPage),
 	}
-	if req.Scope != 0 {
-		mReq.Filter = fmt.Sprintf("is_dir = %v", req.Scope == 1)
+	if filter := buildFilter(req); filter != "" {
+		mReq.Filter = filter
+	}
+	if req.OrderBy != "" {
+		orderBy := req.OrderBy
+		if orderBy == "modified" {
+			orderBy = "modified_at"
+		}
+		orderDirection := "asc"
+		if req.OrderDirection != "" {
+			orderDirection = req.OrderDirection
+		}
+		mReq.Sort = []string{orderBy + ":" + orderDirection}
 	}
 	search, err := m.Client.Index(m.IndexUid).Search(req.Keywords, mReq)
 	if err != nil {
 		return nil, 0, err
 	}
 	nodes, err := utils.SliceConvert(search.Hits, func(src any) (model.SearchNode, error) {
-		srcMap := src.(map[string]any)
-		return model.SearchNode{
-			Parent: srcMap["parent"].(string),
-			Name:   srcMap["name"].(string),
-			IsDir:  srcMap["is_dir"].(bool),
-			Size:   int64(srcMap["size"].(float64)),
-		}, nil
+		return toSearchNode(src.(map[string]any)), nil
 	})
 	if err != nil {
 		return nil, 0, err
@@ -66,6 +146,7 @@ func (m *Meilisearch) BatchIndex(ctx context.Context, nodes []model.SearchNode)
 
 		return &searchDocument{
 			ID:         uuid.NewString(),
+			ModifiedAt: src.Modified.Unix(),
 			SearchNode: src,
 		}, nil
 	})
@@ -100,13 +181,8 @@ func (m *Meilisearch) getDocumentsByParent(ctx context.Context, parent string) (
 	}
 	return utils.SliceConvert(result.Results, func(src map[string]any) (*searchDocument, error) {
 		return &searchDocument{
-			ID: src["id"].(string),
-			SearchNode: model.SearchNode{
-				Parent: src["parent"].(string),
-				Name:   src["name"].(string),
-				IsDir:  src["is_dir"].(bool),
-				Size:   int64(src["size"].(float64)),
-			},
+			ID:         src["id"].(string),
+			SearchNode: toSearchNode(src),
 		}, nil
 	})
 }
@@ -3,17 +3,23 @@ package search
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/search/searcher"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
 
 var instance searcher.Searcher = nil
 
+// maxTaggedPaths is the max number of the tagged objects a search with a tag can filter by
+const maxTaggedPaths = 1000
+
 // Init or reset index
 func Init(mode string) error {
 	if instance != nil {
@@ -48,6 +54,27 @@ func Init(mode string) error {
 }
 
 func Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
+	for i := range req.Exts {
+		req.Exts[i] = strings.ToLower(strings.TrimPrefix(req.Exts[i], "."))
+	}
+	if req.Tag != "" {
+		var userID uint
+		if user, ok := ctx.Value("user").(*model.User); ok {
+			userID = user.ID
+		}
+		paths, err := db.GetTaggedPaths(req.Tag, userID, req.Parent)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(paths) == 0 {
+			return nil, 0, nil
+		}
+		// the paths are passed to the searcher as a filter, which can't be unbounded
+		if len(paths) > maxTaggedPaths {
+			return nil, 0, fmt.Errorf("too many objects with the tag %s, please search in a sub folder", req.Tag)
+		}
+		req.TaggedPaths = paths
+	}
 	return instance.Search(ctx, req)
 }
 
@@ -55,12 +82,22 @@ func Index(ctx context.Context, parent string, obj model.Obj) error {
 	if instance == nil {
 		return errs.SearchNotAvailable
 	}
-	return instance.Index(ctx, model.SearchNode{
-		Parent: parent,
-		Name:   obj.GetName(),
-		IsDir:  obj.IsDir(),
-		Size:   obj.GetSize(),
-	})
+	return instance.Index(ctx, toSearchNode(parent, obj))
+}
+
+func toSearchNode(parent string, obj model.Obj) model.SearchNode {
+	node := model.SearchNode{
+		Parent:   parent,
+		Name:     obj.GetName(),
+		IsDir:    obj.IsDir(),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		FileType: utils.GetObjType(obj.GetName(), obj.IsDir()),
+	}
+	if !obj.IsDir() {
+		node.Ext = utils.Ext(obj.GetName())
+	}
+	return node
 }
 
 type ObjWithParent struct {
@@ -77,12 +114,7 @@ func BatchIndex(ctx context.Context, objs []ObjWithParent) error {
 	}
 	var searchNodes []model.SearchNode
 	for i := range objs {
-		searchNodes = append(searchNodes, model.SearchNode{
-			Parent: objs[i].Parent,
-			Name:   objs[i].GetName(),
-			IsDir:  objs[i].IsDir(),
-			Size:   objs[i].GetSize(),
-		})
+		searchNodes = append(searchNodes, toSearchNode(objs[i].Parent, objs[i].Obj))
 	}
 	return instance.BatchIndex(ctx, searchNodes)
 }
@@ -36,6 +36,15 @@ func Verify(data string, sign string) error {
 	return instance.Verify(data, sign)
 }
 
+// Thumb signs the thumbnail of path only, the link can't be used to download the file itself
+func Thumb(path string) string {
+	return Sign(path + "#thumb")
+}
+
+func VerifyThumb(path string, sign string) error {
+	return Verify(path+"#thumb", sign)
+}
+
 func Instance() {
 	instance = sign.NewHMACSign([]byte(setting.GetStr(conf.Token)))
 }
@@ -0,0 +1,88 @@
+package stream
+
+import (
+	"errors"
+	"io"
+
+	"github.com/alist-org/alist/v3/pkg/http_range"
+)
+
+// readChunkSize is the max length of a ranged read, so a seek doesn't waste the rest of a long read
+const readChunkSize = 1024 * 1024
+
+// RangeReader reads a range of the file, like SeekableStream
+type RangeReader interface {
+	RangeRead(httpRange http_range.Range) (io.Reader, error)
+}
+
+// RangeReadSeeker reads the file by ranged reads, so that the headers and tags
+// at both ends of the file are read without downloading the whole file
+type RangeReadSeeker struct {
+	rr   RangeReader
+	size int64
+	pos  int64
+	// r reads from rPos to the end of the chunk
+	r    io.Reader
+	rPos int64
+}
+
+func NewRangeReadSeeker(rr RangeReader, size int64) *RangeReadSeeker {
+	return &RangeReadSeeker{rr: rr, size: size}
+}
+
+func (s *RangeReadSeeker) Read(p []byte) (int, error) {
+	if s.pos >= s.size {
+		return 0, io.EOF
+	}
+	fresh := false
+	if s.r == nil || s.rPos != s.pos {
+		s.closeReader()
+		length := min(s.size-s.pos, readChunkSize)
+		r, err := s.rr.RangeRead(http_range.Range{Start: s.pos, Length: length})
+		if err != nil {
+			return 0, err
+		}
+		s.r, s.rPos, fresh = r, s.pos, true
+	}
+	n, err := s.r.Read(p)
+	s.pos += int64(n)
+	s.rPos = s.pos
+	if errors.Is(err, io.EOF) {
+		// the chunk is read to the end, the next read starts a new chunk
+		s.closeReader()
+		if n > 0 {
+			return n, nil
+		}
+		if fresh {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return s.Read(p)
+	}
+	return n, err
+}
+
+func (s *RangeReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += s.pos
+	case io.SeekEnd:
+		offset += s.size
+	}
+	if offset < 0 {
+		return 0, errors.New("seek to negative position")
+	}
+	s.pos = offset
+	return offset, nil
+}
+
+func (s *RangeReadSeeker) closeReader() {
+	if c, ok := s.r.(io.Closer); ok {
+		_ = c.Close()
+	}
+	s.r = nil
+}
+
+func (s *RangeReadSeeker) Close() error {
+	s.closeReader()
+	return nil
+}
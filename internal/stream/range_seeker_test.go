@@ -0,0 +1,34 @@
+package stream
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/alist-org/alist/v3/pkg/http_range"
+)
+
+type bytesRangeReader []byte
+
+func (b bytesRangeReader) RangeRead(r http_range.Range) (io.Reader, error) {
+	return bytes.NewReader(b[r.Start : r.Start+r.Length]), nil
+}
+
+func TestRangeReadSeeker(t *testing.T) {
+	data := make([]byte, readChunkSize*2+10)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	r := NewRangeReadSeeker(bytesRangeReader(data), int64(len(data)))
+	got, err := io.ReadAll(r)
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("failed read all across chunks: %v", err)
+	}
+	if _, err = r.Seek(-5, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	tail, _ := io.ReadAll(r)
+	if !bytes.Equal(tail, data[len(data)-5:]) {
+		t.Errorf("unexpected tail: %v", tail)
+	}
+}
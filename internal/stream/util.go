@@ -68,6 +68,34 @@ func GetRangeReadCloserFromLink(size int64, link *model.Link) (model.RangeReadCl
 	return &resultRangeReadCloser, nil
 }
 
+// GetRangeReaderFromLink returns a RangeReaderFunc for any kind of link,
+// the returned reader must be closed by the caller
+func GetRangeReaderFromLink(size int64, link *model.Link) (model.RangeReaderFunc, error) {
+	if link.MFile != nil {
+		return func(ctx context.Context, r http_range.Range) (io.ReadCloser, error) {
+			length := r.Length
+			if length < 0 || r.Start+length > size {
+				length = size - r.Start
+			}
+			return io.NopCloser(io.NewSectionReader(link.MFile, r.Start, length)), nil
+		}, nil
+	}
+	rrc := link.RangeReadCloser
+	if len(link.URL) > 0 {
+		var err error
+		rrc, err = GetRangeReadCloserFromLink(size, link)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if rrc == nil {
+		return nil, errs.NotSupport
+	}
+	return func(ctx context.Context, r http_range.Range) (io.ReadCloser, error) {
+		return rrc.RangeRead(ctx, r)
+	}, nil
+}
+
 func RequestRangedHttp(ctx context.Context, link *model.Link, offset, length int64) (*http.Response, error) {
 	header := net.ProcessHeader(http.Header{}, link.Header)
 	header = http_range.ApplyRangeToHttpHeader(http_range.Range{Start: offset, Length: length}, header)
@@ -0,0 +1,279 @@
+package warmer
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/semaphore"
+)
+
+// maxErrors of a run kept in the status
+const maxErrors = 20
+
+type Status struct {
+	Running     bool       `json:"running"`
+	LastRunTime *time.Time `json:"last_run_time"`
+	LastCost    string     `json:"last_cost"`
+	DirCount    int        `json:"dir_count"`
+	Errors      []string   `json:"errors"`
+}
+
+type jobState struct {
+	mu     sync.Mutex
+	status Status
+	cancel context.CancelFunc
+}
+
+func (s *jobState) getStatus() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := s.status
+	status.Errors = append([]string(nil), s.status.Errors...)
+	return status
+}
+
+var (
+	warmCron *cron.Cron
+	states   generic_sync.MapOf[uint, *jobState]
+)
+
+func getState(id uint) *jobState {
+	s, _ := states.LoadOrStore(id, &jobState{})
+	return s
+}
+
+// InitCron checks the jobs every minute, and runs the ones due
+func InitCron() {
+	if warmCron != nil {
+		warmCron.Stop()
+	}
+	warmCron = cron.NewCron(time.Minute)
+	warmCron.Do(func() {
+		jobs, err := db.GetWarmJobs()
+		if err != nil {
+			log.Errorf("failed get warm jobs: %+v", err)
+			return
+		}
+		for i := range jobs {
+			if due(&jobs[i]) {
+				go Run(context.Background(), &jobs[i])
+			}
+		}
+	})
+}
+
+// interval of the job, 0 means it's not scheduled
+func interval(job *model.WarmJob) time.Duration {
+	if job.Interval > 0 {
+		return time.Duration(job.Interval) * time.Minute
+	}
+	storage, _, err := op.GetStorageAndActualPath(job.Path)
+	if err != nil || storage.Config().NoCache || storage.GetStorage().CacheExpiration <= 0 {
+		return 0
+	}
+	// refresh before the cache expires
+	d := time.Duration(storage.GetStorage().CacheExpiration) * time.Minute * 4 / 5
+	return max(d, time.Minute)
+}
+
+func due(job *model.WarmJob) bool {
+	if job.Disabled {
+		return false
+	}
+	d := interval(job)
+	if d <= 0 {
+		return false
+	}
+	status := getState(job.ID).getStatus()
+	return !status.Running && (status.LastRunTime == nil || time.Since(*status.LastRunTime) >= d)
+}
+
+func GetWarmJobs() ([]model.WarmJob, error) {
+	return db.GetWarmJobs()
+}
+
+func GetWarmJobById(id uint) (*model.WarmJob, error) {
+	return db.GetWarmJobById(id)
+}
+
+// GetStatus returns the status of the last or current run of the job
+func GetStatus(id uint) Status {
+	return getState(id).getStatus()
+}
+
+func CreateWarmJob(job *model.WarmJob) error {
+	job.Path = utils.FixAndCleanPath(job.Path)
+	return db.CreateWarmJob(job)
+}
+
+func UpdateWarmJob(job *model.WarmJob) error {
+	job.Path = utils.FixAndCleanPath(job.Path)
+	return db.UpdateWarmJob(job)
+}
+
+func DeleteWarmJobById(id uint) error {
+	if s, ok := states.Load(id); ok {
+		s.mu.Lock()
+		if s.cancel != nil {
+			s.cancel()
+		}
+		s.mu.Unlock()
+		states.Delete(id)
+	}
+	return db.DeleteWarmJobById(id)
+}
+
+// Run refreshes the listings under job.Path, it returns immediately if the job is running
+func Run(ctx context.Context, job *model.WarmJob) {
+	s := getState(job.ID)
+	s.mu.Lock()
+	if s.status.Running {
+		s.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	start := time.Now()
+	s.cancel = cancel
+	s.status = Status{Running: true, LastRunTime: &start}
+	s.mu.Unlock()
+
+	r := &runner{state: s}
+	log.Debugf("run warm job [%d] for: %s", job.ID, job.Path)
+	r.walk(ctx, job.Path, max(job.Depth, 1))
+
+	s.mu.Lock()
+	s.status.Running = false
+	s.status.LastCost = time.Since(start).String()
+	s.cancel = nil
+	s.mu.Unlock()
+	log.Debugf("warm job [%d] done, %d folders refreshed", job.ID, s.getStatus().DirCount)
+}
+
+type runner struct {
+	state *jobState
+}
+
+func (r *runner) addError(err error) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	if len(r.state.status.Errors) < maxErrors {
+		r.state.status.Errors = append(r.state.status.Errors, err.Error())
+	}
+}
+
+func (r *runner) addDir() {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	r.state.status.DirCount++
+}
+
+// walk refreshes the folders level by level, the folders of a level are listed concurrently
+func (r *runner) walk(ctx context.Context, dir string, depth int) {
+	level := []string{dir}
+	for ; depth > 0 && len(level) > 0 && ctx.Err() == nil; depth-- {
+		var (
+			next []string
+			mu   sync.Mutex
+			wg   sync.WaitGroup
+		)
+		for _, d := range level {
+			wg.Add(1)
+			go func(d string) {
+				defer wg.Done()
+				dirs := r.warm(ctx, d)
+				mu.Lock()
+				next = append(next, dirs...)
+				mu.Unlock()
+			}(d)
+		}
+		wg.Wait()
+		level = next
+	}
+}
+
+// warm refreshes the listing of dir in all storages of the balance group, and returns the sub folders
+func (r *runner) warm(ctx context.Context, dir string) []string {
+	seen := make(map[string]struct{})
+	var dirs []string
+	addDir := func(name string) {
+		if _, ok := seen[name]; !ok {
+			seen[name] = struct{}{}
+			dirs = append(dirs, stdpath.Join(dir, name))
+		}
+	}
+	for _, obj := range op.GetStorageVirtualFilesByPath(dir) {
+		addDir(obj.GetName())
+	}
+	storages, actualPath, err := op.GetStoragesAndActualPath(dir)
+	if err != nil {
+		if len(dirs) == 0 {
+			r.addError(fmt.Errorf("%s: %w", dir, err))
+		}
+		return dirs
+	}
+	for _, storage := range storages {
+		if storage.Config().NoCache || storage.GetStorage().CacheExpiration <= 0 {
+			continue
+		}
+		objs, err := list(ctx, storage, dir, actualPath)
+		if err != nil {
+			if ctx.Err() == nil {
+				r.addError(fmt.Errorf("%s: %w", stdpath.Join(storage.GetStorage().MountPath, actualPath), err))
+			}
+			continue
+		}
+		r.addDir()
+		for _, obj := range objs {
+			if obj.IsDir() {
+				addDir(obj.GetName())
+			}
+		}
+	}
+	return dirs
+}
+
+type limiter struct {
+	sem   *semaphore.Weighted
+	limit int64
+}
+
+var (
+	limiters   = map[string]*limiter{}
+	limitersMu sync.Mutex
+)
+
+// getLimiter returns the limiter of the storage, it's recreated if the setting is changed
+func getLimiter(mountPath string) *semaphore.Weighted {
+	limit := int64(max(setting.GetInt(conf.WarmConcurrency, 2), 1))
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	l, ok := limiters[mountPath]
+	if !ok || l.limit != limit {
+		l = &limiter{sem: semaphore.NewWeighted(limit), limit: limit}
+		limiters[mountPath] = l
+	}
+	return l.sem
+}
+
+func list(ctx context.Context, storage driver.Driver, reqPath, actualPath string) ([]model.Obj, error) {
+	sem := getLimiter(storage.GetStorage().MountPath)
+	if err := sem.Acquire(ctx, 1); err != nil {
+		return nil, err
+	}
+	defer sem.Release(1)
+	return op.List(ctx, storage, actualPath, model.ListArgs{ReqPath: reqPath, Refresh: true})
+}
@@ -0,0 +1,62 @@
+package warmer_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	_ "github.com/alist-org/alist/v3/drivers/virtual"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/warmer"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+func TestRun(t *testing.T) {
+	_, err := op.CreateStorage(context.Background(), model.Storage{
+		Driver:          "Virtual",
+		MountPath:       "/warm",
+		CacheExpiration: 30,
+		Addition:        `{"num_file":1,"num_folder":2,"max_file_size":10,"min_file_size":1}`,
+	})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	job := &model.WarmJob{Path: "/warm", Depth: 2}
+	if err = warmer.CreateWarmJob(job); err != nil {
+		t.Fatalf("failed to create job: %+v", err)
+	}
+	warmer.Run(context.Background(), job)
+	status := warmer.GetStatus(job.ID)
+	if status.Running || status.LastRunTime == nil || len(status.Errors) > 0 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	// /warm and its 2 sub folders are refreshed, the deeper ones are beyond the depth
+	if status.DirCount != 3 {
+		t.Errorf("expect 3 folders refreshed, got %d", status.DirCount)
+	}
+
+	job = &model.WarmJob{Path: "/missing", Depth: 1}
+	if err = warmer.CreateWarmJob(job); err != nil {
+		t.Fatalf("failed to create job: %+v", err)
+	}
+	warmer.Run(context.Background(), job)
+	if status = warmer.GetStatus(job.ID); len(status.Errors) != 1 {
+		t.Errorf("expect the error recorded, got %+v", status)
+	}
+	if time.Since(*status.LastRunTime) > time.Minute {
+		t.Errorf("unexpected last run time: %s", status.LastRunTime)
+	}
+}
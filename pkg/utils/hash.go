@@ -75,6 +75,15 @@ func RegisterHashWithParam(name, alias string, width int, newFunc func(...any) h
 	return newType
 }
 
+// GetHashByName returns the registered HashType by its name or alias
+func GetHashByName(name string) (*HashType, bool) {
+	if ht, ok := name2hash[name]; ok {
+		return ht, true
+	}
+	ht, ok := alias2hash[name]
+	return ht, ok
+}
+
 var (
 	// MD5 indicates MD5 support
 	MD5 = RegisterHash("md5", "MD5", 32, md5.New)
@@ -219,6 +228,15 @@ func FromString(str string) HashInfo {
 
 	return hi
 }
+func (hi HashInfo) GobEncode() ([]byte, error) {
+	return []byte(hi.String()), nil
+}
+
+func (hi *HashInfo) GobDecode(data []byte) error {
+	*hi = FromString(string(data))
+	return nil
+}
+
 func (hi HashInfo) GetHash(ht *HashType) string {
 	return hi.h[ht]
 }
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"bytes"
+	"encoding/gob"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -64,3 +65,22 @@ func TestMultiHasher(t *testing.T) {
 
 	}
 }
+
+func TestHashInfoGob(t *testing.T) {
+	hi := NewHashInfo(MD5, "bf13fc19e5151ac57d4252e0e0f87abe")
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(hi))
+	var newHi HashInfo
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&newHi))
+	assert.Equal(t, hi.h, newHi.h)
+}
+
+func TestGetHashByName(t *testing.T) {
+	for _, name := range []string{"sha1", "SHA-1"} {
+		ht, ok := GetHashByName(name)
+		require.True(t, ok, "hash type %s should be found", name)
+		assert.Equal(t, SHA1, ht)
+	}
+	_, ok := GetHashByName("unknown")
+	assert.False(t, ok)
+}
@@ -58,7 +58,8 @@ func ParseToken(tokenString string) (*UserClaims, error) {
 			}
 		}
 	}
-	if claims, ok := token.Claims.(*UserClaims); ok && token.Valid {
+	// the tokens with an audience are for others, like wopi
+	if claims, ok := token.Claims.(*UserClaims); ok && token.Valid && len(claims.Audience) == 0 {
 		return claims, nil
 	}
 	return nil, errors.New("couldn't handle this token")
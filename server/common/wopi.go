@@ -0,0 +1,70 @@
+package common
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+)
+
+// WopiTokenExpiresIn is long enough for an editing session, the editor can't refresh the token
+const WopiTokenExpiresIn = 10 * time.Hour
+
+// WopiAudience tells the wopi tokens from the user tokens, as they are signed by the same key
+const WopiAudience = "wopi"
+
+// WopiClaims grants the access of a file to the wopi client, which acts as the user.
+// The permissions are checked again on every request, so the token is no more than the user.
+type WopiClaims struct {
+	Username string `json:"username"`
+	PwdTS    int64  `json:"pwd_ts"`
+	Path     string `json:"path"`
+	// MetaPwd is the hash of the meta password given by the user, it's invalid once the password is changed
+	MetaPwd string `json:"meta_pwd,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// WopiPasswordHash hashes the meta password by the secret key, so the token doesn't reveal it
+func WopiPasswordHash(password string) string {
+	if password == "" {
+		return ""
+	}
+	h := hmac.New(sha256.New, SecretKey)
+	h.Write([]byte(password))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func GenerateWopiToken(user *model.User, path string, password string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(WopiTokenExpiresIn)
+	claim := WopiClaims{
+		Username: user.Username,
+		PwdTS:    user.PwdTS,
+		Path:     path,
+		MetaPwd:  WopiPasswordHash(password),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{WopiAudience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		}}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claim).SignedString(SecretKey)
+	return token, expiresAt, err
+}
+
+func ParseWopiToken(tokenString string) (*WopiClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &WopiClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return SecretKey, nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid wopi token")
+	}
+	if claims, ok := token.Claims.(*WopiClaims); ok && token.Valid &&
+		claims.VerifyAudience(WopiAudience, true) && claims.Path != "" {
+		return claims, nil
+	}
+	return nil, errors.New("couldn't handle this token")
+}
@@ -0,0 +1,43 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestWopiToken(t *testing.T) {
+	SecretKey = []byte("secret")
+	user := &model.User{Username: "alice", PwdTS: 1}
+	token, _, err := GenerateWopiToken(user, "/docs/a.docx", "123")
+	if err != nil {
+		t.Fatalf("failed generate token: %+v", err)
+	}
+	claims, err := ParseWopiToken(token)
+	if err != nil {
+		t.Fatalf("failed parse token: %+v", err)
+	}
+	if claims.Username != "alice" || claims.PwdTS != 1 || claims.Path != "/docs/a.docx" ||
+		claims.MetaPwd == "123" || claims.MetaPwd != WopiPasswordHash("123") {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+	// the login token of the user can't be used as a wopi token
+	login, err := jwt.NewWithClaims(jwt.SigningMethodHS256, UserClaims{Username: "alice", PwdTS: 1}).SignedString(SecretKey)
+	if err != nil {
+		t.Fatalf("failed generate token: %+v", err)
+	}
+	if _, err := ParseWopiToken(login); err == nil {
+		t.Errorf("expect the login token rejected")
+	}
+	// and the wopi token can't be used to login, even if it's not invalidated
+	validTokenCache.Set(token, true)
+	defer validTokenCache.Del(token)
+	if _, err := ParseToken(token); err == nil {
+		t.Errorf("expect the wopi token rejected as a login token")
+	}
+	SecretKey = []byte("other")
+	if _, err := ParseWopiToken(token); err == nil {
+		t.Errorf("expect the token signed by another key rejected")
+	}
+}
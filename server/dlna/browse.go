@@ -0,0 +1,366 @@
+package dlna
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/pkg/errors"
+)
+
+const (
+	rootID       = "0"
+	rootParentID = "-1"
+	// the content is listed on demand, so the update id never changes
+	systemUpdateID = "1"
+)
+
+type browseArgs struct {
+	ObjectID       string `xml:"ObjectID"`
+	BrowseFlag     string `xml:"BrowseFlag"`
+	Filter         string `xml:"Filter"`
+	StartingIndex  int    `xml:"StartingIndex"`
+	RequestedCount int    `xml:"RequestedCount"`
+	SortCriteria   string `xml:"SortCriteria"`
+}
+
+func (s *Server) serveContentDirectory(w http.ResponseWriter, r *http.Request) {
+	switch action := soapAction(r); action {
+	case "GetSearchCapabilities":
+		writeSoapResponse(w, contentDirType, action, []soapArg{{"SearchCaps", ""}})
+	case "GetSortCapabilities":
+		writeSoapResponse(w, contentDirType, action, []soapArg{{"SortCaps", ""}})
+	case "GetSystemUpdateID":
+		writeSoapResponse(w, contentDirType, action, []soapArg{{"Id", systemUpdateID}})
+	case "Browse":
+		var args browseArgs
+		if err := readSoapArgs(r, &args); err != nil {
+			writeSoapError(w, err)
+			return
+		}
+		objs, total, err := s.browse(r, args)
+		if err != nil {
+			writeSoapError(w, err)
+			return
+		}
+		result, err := marshalDIDL(objs)
+		if err != nil {
+			writeSoapError(w, err)
+			return
+		}
+		writeSoapResponse(w, contentDirType, action, []soapArg{
+			{"Result", result},
+			{"NumberReturned", fmt.Sprint(len(objs))},
+			{"TotalMatches", fmt.Sprint(total)},
+			{"UpdateID", systemUpdateID},
+		})
+	default:
+		writeSoapError(w, &upnpError{Code: errInvalidAction, Desc: "Invalid Action"})
+	}
+}
+
+// browseUser returns the user configured to browse as, or the guest
+func browseUser() (*model.User, error) {
+	var (
+		user *model.User
+		err  error
+	)
+	if name := setting.GetStr(conf.DLNAUser); name != "" {
+		user, err = op.GetUserByName(name)
+	} else {
+		user, err = op.GetGuest()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled {
+		return nil, errors.WithStack(errs.PermissionDenied)
+	}
+	return user, nil
+}
+
+// rootPaths returns the paths exposed, which are relative to the base path of the user
+func rootPaths(user *model.User) []string {
+	var paths []string
+	for _, p := range strings.Split(setting.GetStr(conf.DLNAPaths), "\n") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		if joined, err := user.JoinPath(p); err == nil && !utils.SliceContains(paths, joined) {
+			paths = append(paths, joined)
+		}
+	}
+	if len(paths) == 0 {
+		paths = append(paths, user.BasePath)
+	}
+	return paths
+}
+
+// browse returns the object or the children of the object, which id is the path in alist
+func (s *Server) browse(r *http.Request, args browseArgs) ([]didlObject, int, error) {
+	user, err := browseUser()
+	if err != nil {
+		return nil, 0, err
+	}
+	roots := rootPaths(user)
+	ctx := context.WithValue(r.Context(), "user", user)
+	var objs []didlObject
+	if args.ObjectID == rootID {
+		if args.BrowseFlag == "BrowseMetadata" {
+			return []didlObject{s.rootObject(len(roots))}, 1, nil
+		}
+		for _, root := range roots {
+			if !canAccess(user, root) {
+				continue
+			}
+			if _, err := fs.Get(ctx, root, &fs.GetArgs{}); err != nil {
+				continue
+			}
+			title := stdpath.Base(root)
+			if root == "/" {
+				title = s.friendlyName
+			}
+			objs = append(objs, containerObject(root, rootID, title))
+		}
+		return paginate(objs, args)
+	}
+	path := args.ObjectID
+	if path != utils.FixAndCleanPath(path) || !underRoots(roots, path) || !canAccess(user, path) {
+		return nil, 0, &upnpError{Code: errNoSuchObject, Desc: "No such object"}
+	}
+	if args.BrowseFlag == "BrowseMetadata" {
+		obj, err := fs.Get(ctx, path, &fs.GetArgs{NoLog: true})
+		if err != nil {
+			return nil, 0, &upnpError{Code: errNoSuchObject, Desc: "No such object"}
+		}
+		o, ok := toObject(r, path, parentID(roots, path), obj)
+		if !ok {
+			return nil, 0, &upnpError{Code: errNoSuchObject, Desc: "No such object"}
+		}
+		return []didlObject{o}, 1, nil
+	}
+	meta, _ := op.GetNearestMeta(path)
+	list, err := fs.List(context.WithValue(ctx, "meta", meta), path, &fs.ListArgs{})
+	if err != nil {
+		return nil, 0, &upnpError{Code: errNoSuchObject, Desc: "No such object"}
+	}
+	for _, obj := range list {
+		childPath := stdpath.Join(path, obj.GetName())
+		if obj.IsDir() && !canAccess(user, childPath) {
+			continue
+		}
+		if o, ok := toObject(r, childPath, path, obj); ok {
+			objs = append(objs, o)
+		}
+	}
+	return paginate(objs, args)
+}
+
+// canAccess checks the hidden files and the meta passwords, the devices can't input a password
+func canAccess(user *model.User, path string) bool {
+	meta, err := op.GetNearestMeta(path)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return false
+	}
+	return common.CanAccess(user, meta, path, "")
+}
+
+func underRoots(roots []string, path string) bool {
+	for _, root := range roots {
+		if utils.IsSubPath(root, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func parentID(roots []string, path string) string {
+	if utils.SliceContains(roots, path) {
+		return rootID
+	}
+	return stdpath.Dir(path)
+}
+
+func paginate(objs []didlObject, args browseArgs) ([]didlObject, int, error) {
+	total := len(objs)
+	start := args.StartingIndex
+	if start < 0 || start > total {
+		start = total
+	}
+	end := total
+	if args.RequestedCount > 0 && args.RequestedCount < total-start {
+		end = start + args.RequestedCount
+	}
+	return objs[start:end], total, nil
+}
+
+func (s *Server) rootObject(childCount int) didlObject {
+	o := containerObject(rootID, rootParentID, s.friendlyName)
+	o.ChildCount = &childCount
+	return o
+}
+
+func containerObject(id, parentID, title string) didlObject {
+	return didlObject{
+		XMLName:    xml.Name{Local: "container"},
+		ID:         id,
+		ParentID:   parentID,
+		Restricted: 1,
+		Title:      title,
+		Class:      "object.container.storageFolder",
+	}
+}
+
+// toObject converts the dir or the media file to the object, the other files are not exposed
+func toObject(r *http.Request, path, parentID string, obj model.Obj) (didlObject, bool) {
+	if obj.IsDir() {
+		return containerObject(path, parentID, obj.GetName()), true
+	}
+	fileType := utils.GetFileType(obj.GetName())
+	class, ok := upnpClasses[fileType]
+	if !ok {
+		return didlObject{}, false
+	}
+	url := mediaURL(r, path, obj.GetName())
+	if url == "" {
+		return didlObject{}, false
+	}
+	o := didlObject{
+		XMLName:    xml.Name{Local: "item"},
+		ID:         path,
+		ParentID:   parentID,
+		Restricted: 1,
+		Title:      obj.GetName(),
+		Class:      class,
+		Date:       obj.ModTime().Format("2006-01-02T15:04:05"),
+		Res: &didlRes{
+			ProtocolInfo: protocolInfo(obj.GetName(), fileType),
+			Size:         obj.GetSize(),
+			URL:          url,
+		},
+	}
+	if thumb, _ := model.GetThumb(obj); thumb != "" {
+		o.AlbumArtURI = thumb
+	} else if fileType == conf.IMAGE {
+		o.AlbumArtURI = url
+	}
+	return o, true
+}
+
+var upnpClasses = map[int]string{
+	conf.VIDEO: "object.item.videoItem",
+	conf.AUDIO: "object.item.audioItem.musicTrack",
+	conf.IMAGE: "object.item.imageItem.photo",
+}
+
+// mediaURL returns the signed link of the file, which is proxied if the storage should be
+func mediaURL(r *http.Request, path, name string) string {
+	base := mediaBaseURL(r)
+	if base == "" {
+		return ""
+	}
+	prefix := "/d"
+	if storage, err := fs.GetStorage(path, &fs.GetStoragesArgs{}); err == nil && common.ShouldProxy(storage, name) {
+		prefix = "/p"
+	}
+	return fmt.Sprintf("%s%s%s?sign=%s", base, prefix, utils.EncodePath(path, true), sign.Sign(path))
+}
+
+// the mime types the devices expect, which may be unknown or different in the mime package
+var dlnaMimeTypes = map[string]string{
+	"mkv":  "video/x-matroska",
+	"avi":  "video/x-msvideo",
+	"ts":   "video/mp2t",
+	"m2ts": "video/mp2t",
+	"mts":  "video/mp2t",
+	"flv":  "video/x-flv",
+	"wmv":  "video/x-ms-wmv",
+	"mp4":  "video/mp4",
+	"m4v":  "video/mp4",
+	"mov":  "video/quicktime",
+	"webm": "video/webm",
+	"mpg":  "video/mpeg",
+	"mpeg": "video/mpeg",
+	"mp3":  "audio/mpeg",
+	"flac": "audio/flac",
+	"m4a":  "audio/mp4",
+	"aac":  "audio/aac",
+	"ogg":  "audio/ogg",
+	"wav":  "audio/wav",
+	"wma":  "audio/x-ms-wma",
+	"ape":  "audio/x-ape",
+	"jpg":  "image/jpeg",
+	"jpeg": "image/jpeg",
+	"png":  "image/png",
+	"gif":  "image/gif",
+	"bmp":  "image/bmp",
+	"webp": "image/webp",
+}
+
+// protocolInfo returns the protocol info of the file, the range requests are supported by the links
+func protocolInfo(name string, fileType int) string {
+	mimeType, ok := dlnaMimeTypes[strings.ToLower(utils.Ext(name))]
+	if !ok {
+		mimeType = utils.GetMimeType(name)
+	}
+	// streaming transfer mode for av, interactive transfer mode for images
+	flags := "01700000000000000000000000000000"
+	if fileType == conf.IMAGE {
+		flags = "00f00000000000000000000000000000"
+	}
+	return fmt.Sprintf("http-get:*:%s:DLNA.ORG_OP=01;DLNA.ORG_CI=0;DLNA.ORG_FLAGS=%s", mimeType, flags)
+}
+
+type didlRes struct {
+	ProtocolInfo string `xml:"protocolInfo,attr"`
+	Size         int64  `xml:"size,attr,omitempty"`
+	URL          string `xml:",chardata"`
+}
+
+type didlObject struct {
+	XMLName     xml.Name
+	ID          string   `xml:"id,attr"`
+	ParentID    string   `xml:"parentID,attr"`
+	Restricted  int      `xml:"restricted,attr"`
+	ChildCount  *int     `xml:"childCount,attr,omitempty"`
+	Title       string   `xml:"dc:title"`
+	Class       string   `xml:"upnp:class"`
+	Date        string   `xml:"dc:date,omitempty"`
+	AlbumArtURI string   `xml:"upnp:albumArtURI,omitempty"`
+	Res         *didlRes `xml:"res,omitempty"`
+}
+
+type didlLite struct {
+	XMLName   xml.Name     `xml:"DIDL-Lite"`
+	Xmlns     string       `xml:"xmlns,attr"`
+	XmlnsDC   string       `xml:"xmlns:dc,attr"`
+	XmlnsUPnP string       `xml:"xmlns:upnp,attr"`
+	XmlnsDLNA string       `xml:"xmlns:dlna,attr"`
+	Objects   []didlObject `xml:""`
+}
+
+func marshalDIDL(objs []didlObject) (string, error) {
+	b, err := xml.Marshal(didlLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+		XmlnsDLNA: "urn:schemas-dlna-org:metadata-1-0/",
+		Objects:   objs,
+	})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,72 @@
+package dlna
+
+import (
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+)
+
+func TestReadBrowseArgs(t *testing.T) {
+	body := `<?xml version="1.0"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/">
+<s:Body><u:Browse xmlns:u="urn:schemas-upnp-org:service:ContentDirectory:1">
+<ObjectID>/movies/a &amp; b</ObjectID><BrowseFlag>BrowseDirectChildren</BrowseFlag><Filter>*</Filter>
+<StartingIndex>10</StartingIndex><RequestedCount>5</RequestedCount><SortCriteria></SortCriteria>
+</u:Browse></s:Body></s:Envelope>`
+	r := httptest.NewRequest("POST", contentDirCtlPath, strings.NewReader(body))
+	r.Header.Set("SOAPACTION", `"urn:schemas-upnp-org:service:ContentDirectory:1#Browse"`)
+	if action := soapAction(r); action != "Browse" {
+		t.Errorf("unexpected action: %s", action)
+	}
+	var args browseArgs
+	if err := readSoapArgs(r, &args); err != nil {
+		t.Fatalf("failed read args: %+v", err)
+	}
+	if args.ObjectID != "/movies/a & b" || args.BrowseFlag != "BrowseDirectChildren" || args.StartingIndex != 10 || args.RequestedCount != 5 {
+		t.Errorf("unexpected args: %+v", args)
+	}
+}
+
+func TestMarshalDIDL(t *testing.T) {
+	item := didlObject{
+		ID:       "/movies/a.mkv",
+		ParentID: "/movies",
+		Title:    "a.mkv",
+		Class:    upnpClasses[conf.VIDEO],
+		Res: &didlRes{
+			ProtocolInfo: protocolInfo("a.mkv", conf.VIDEO),
+			Size:         10,
+			URL:          "http://192.168.1.2:5244/d/movies/a.mkv?sign=x&y",
+		},
+	}
+	item.XMLName.Local = "item"
+	result, err := marshalDIDL([]didlObject{containerObject("/movies", rootID, "movies"), item})
+	if err != nil {
+		t.Fatalf("failed marshal: %+v", err)
+	}
+	for _, want := range []string{
+		`<DIDL-Lite xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/"`,
+		`<container id="/movies" parentID="0" restricted="1"><dc:title>movies</dc:title><upnp:class>object.container.storageFolder</upnp:class></container>`,
+		`<item id="/movies/a.mkv" parentID="/movies" restricted="0"><dc:title>a.mkv</dc:title><upnp:class>object.item.videoItem</upnp:class>`,
+		`<res protocolInfo="http-get:*:video/x-matroska:DLNA.ORG_OP=01;DLNA.ORG_CI=0;DLNA.ORG_FLAGS=01700000000000000000000000000000" size="10">http://192.168.1.2:5244/d/movies/a.mkv?sign=x&amp;y</res>`,
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expect %s in %s", want, result)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	objs := make([]didlObject, 5)
+	for _, c := range []struct {
+		start, count, returned int
+	}{{0, 0, 5}, {1, 2, 2}, {4, 10, 1}, {6, 1, 0}, {2, math.MaxInt, 3}} {
+		res, total, _ := paginate(objs, browseArgs{StartingIndex: c.start, RequestedCount: c.count})
+		if len(res) != c.returned || total != 5 {
+			t.Errorf("start %d count %d: expect %d returned, got %d of %d", c.start, c.count, c.returned, len(res), total)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Package dlna implements a DLNA/UPnP media server exposing alist paths to the devices in the LAN
+package dlna
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/alist-org/alist/v3/cmd/flags"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	rootDescPath        = "/rootDesc.xml"
+	contentDirSCPDPath  = "/ContentDirectory.xml"
+	connManagerSCPDPath = "/ConnectionManager.xml"
+	contentDirCtlPath   = "/ctl/ContentDirectory"
+	connManagerCtlPath  = "/ctl/ConnectionManager"
+	contentDirEvtPath   = "/evt/ContentDirectory"
+	connManagerEvtPath  = "/evt/ConnectionManager"
+
+	contentDirType  = "urn:schemas-upnp-org:service:ContentDirectory:1"
+	connManagerType = "urn:schemas-upnp-org:service:ConnectionManager:1"
+	mediaServerType = "urn:schemas-upnp-org:device:MediaServer:1"
+)
+
+var serverHeader = fmt.Sprintf("%s/%s UPnP/1.0 DLNADOC/1.50 AList/%s", runtime.GOOS, runtime.GOARCH, conf.Version)
+
+type Server struct {
+	uuid         string
+	friendlyName string
+	port         int
+	httpSrv      *http.Server
+	ssdp         *ssdpServer
+}
+
+func NewServer() *Server {
+	s := &Server{
+		uuid:         deviceUUID(),
+		friendlyName: conf.Conf.DLNA.FriendlyName,
+		port:         conf.Conf.DLNA.Port,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(rootDescPath, s.serveRootDesc)
+	mux.HandleFunc(contentDirSCPDPath, serveXML(contentDirSCPD))
+	mux.HandleFunc(connManagerSCPDPath, serveXML(connManagerSCPD))
+	mux.HandleFunc(contentDirCtlPath, s.serveContentDirectory)
+	mux.HandleFunc(connManagerCtlPath, serveConnectionManager)
+	mux.HandleFunc(contentDirEvtPath, serveEvent)
+	mux.HandleFunc(connManagerEvtPath, serveEvent)
+	s.httpSrv = &http.Server{
+		Addr: fmt.Sprintf("%s:%d", conf.Conf.Scheme.Address, s.port),
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Server", serverHeader)
+			mux.ServeHTTP(w, r)
+		}),
+	}
+	s.ssdp = &ssdpServer{
+		uuid:     s.uuid,
+		location: s.location,
+	}
+	return s
+}
+
+// deviceUUID keeps the same uuid for the same data dir, so that the devices don't find a new server after restart
+func deviceUUID() string {
+	hostname, _ := os.Hostname()
+	sum := md5.Sum([]byte(hostname + "|" + flags.DataDir))
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[:4], sum[4:6], sum[6:8], sum[8:10], sum[10:])
+}
+
+func (s *Server) location(ip net.IP) string {
+	return fmt.Sprintf("http://%s%s", net.JoinHostPort(ip.String(), fmt.Sprint(s.port)), rootDescPath)
+}
+
+// Start serves http and announces the server in the LAN, it blocks until the http server is closed
+func (s *Server) Start() error {
+	if err := s.ssdp.start(); err != nil {
+		log.Warnf("failed start ssdp of dlna server: %+v", err)
+	}
+	return s.httpSrv.ListenAndServe()
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.ssdp.stop()
+	return s.httpSrv.Shutdown(ctx)
+}
+
+func serveXML(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", `text/xml; charset="utf-8"`)
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+func (s *Server) serveRootDesc(w http.ResponseWriter, r *http.Request) {
+	presentationURL := ""
+	if base := mediaBaseURL(r); base != "" {
+		presentationURL = fmt.Sprintf("<presentationURL>%s/</presentationURL>", xmlEscape(base))
+	}
+	serveXML(fmt.Sprintf(rootDesc, xmlEscape(s.friendlyName), conf.Version, s.uuid, presentationURL))(w, r)
+}
+
+// serveEvent accepts the subscriptions, but no event is sent as the content is browsed on demand
+func serveEvent(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "SUBSCRIBE":
+		sid := r.Header.Get("SID")
+		if sid == "" {
+			sid = "uuid:" + utils.GetMD5EncodeStr(r.RemoteAddr+r.Header.Get("CALLBACK"))
+		}
+		w.Header().Set("SID", sid)
+		w.Header().Set("TIMEOUT", "Second-1800")
+	case "UNSUBSCRIBE":
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// mediaBaseURL returns the base url of the alist server for the device requested,
+// the host of the request is used if the site url isn't set, as it's reachable by the device
+func mediaBaseURL(r *http.Request) string {
+	if strings.HasPrefix(conf.Conf.SiteURL, "http") {
+		return strings.TrimSuffix(conf.Conf.SiteURL, "/")
+	}
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+	scheme, port := "http", conf.Conf.Scheme.HttpPort
+	if port == -1 {
+		scheme, port = "https", conf.Conf.Scheme.HttpsPort
+	}
+	if port == -1 {
+		return ""
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(host, fmt.Sprint(port)), strings.TrimSuffix(conf.URL.Path, "/"))
+}
+
+const rootDesc = `<?xml version="1.0" encoding="utf-8"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0" xmlns:dlna="urn:schemas-dlna-org:device-1-0">
+  <specVersion><major>1</major><minor>0</minor></specVersion>
+  <device>
+    <deviceType>` + mediaServerType + `</deviceType>
+    <friendlyName>%s</friendlyName>
+    <manufacturer>AList</manufacturer>
+    <manufacturerURL>https://github.com/alist-org/alist</manufacturerURL>
+    <modelName>AList</modelName>
+    <modelNumber>%s</modelNumber>
+    <UDN>uuid:%s</UDN>
+    <dlna:X_DLNADOC>DMS-1.50</dlna:X_DLNADOC>
+    <serviceList>
+      <service>
+        <serviceType>` + contentDirType + `</serviceType>
+        <serviceId>urn:upnp-org:serviceId:ContentDirectory</serviceId>
+        <SCPDURL>` + contentDirSCPDPath + `</SCPDURL>
+        <controlURL>` + contentDirCtlPath + `</controlURL>
+        <eventSubURL>` + contentDirEvtPath + `</eventSubURL>
+      </service>
+      <service>
+        <serviceType>` + connManagerType + `</serviceType>
+        <serviceId>urn:upnp-org:serviceId:ConnectionManager</serviceId>
+        <SCPDURL>` + connManagerSCPDPath + `</SCPDURL>
+        <controlURL>` + connManagerCtlPath + `</controlURL>
+        <eventSubURL>` + connManagerEvtPath + `</eventSubURL>
+      </service>
+    </serviceList>
+    %s
+  </device>
+</root>`
@@ -0,0 +1,124 @@
+package dlna
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// the upnp errors of the actions
+const (
+	errInvalidAction = 401
+	errInvalidArgs   = 402
+	errActionFailed  = 501
+	errNoSuchObject  = 701
+)
+
+type upnpError struct {
+	Code int
+	Desc string
+}
+
+func (e *upnpError) Error() string {
+	return fmt.Sprintf("upnp error %d: %s", e.Code, e.Desc)
+}
+
+// soapArg is an argument of the action response, which has to be in the order of the scpd
+type soapArg struct {
+	Name  string
+	Value string
+}
+
+type soapEnvelope struct {
+	Body struct {
+		Action struct {
+			XMLName xml.Name
+			Inner   []byte `xml:",innerxml"`
+		} `xml:",any"`
+	} `xml:"Body"`
+}
+
+// soapAction returns the name of the action requested, like Browse
+func soapAction(r *http.Request) string {
+	action := strings.Trim(r.Header.Get("SOAPACTION"), `"`)
+	if i := strings.LastIndex(action, "#"); i >= 0 {
+		return action[i+1:]
+	}
+	return ""
+}
+
+// readSoapArgs decodes the arguments of the action in the body into v
+func readSoapArgs(r *http.Request, v interface{}) error {
+	var env soapEnvelope
+	if err := xml.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&env); err != nil {
+		return &upnpError{Code: errInvalidArgs, Desc: err.Error()}
+	}
+	inner := append(append([]byte("<args>"), env.Body.Action.Inner...), "</args>"...)
+	if err := xml.Unmarshal(inner, v); err != nil {
+		return &upnpError{Code: errInvalidArgs, Desc: err.Error()}
+	}
+	return nil
+}
+
+func writeSoapResponse(w http.ResponseWriter, serviceType, action string, args []soapArg) {
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="utf-8"?>` +
+		`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body>`)
+	fmt.Fprintf(&b, `<u:%sResponse xmlns:u="%s">`, action, serviceType)
+	for _, arg := range args {
+		fmt.Fprintf(&b, "<%s>%s</%s>", arg.Name, xmlEscape(arg.Value), arg.Name)
+	}
+	fmt.Fprintf(&b, `</u:%sResponse></s:Body></s:Envelope>`, action)
+	w.Header().Set("Content-Type", `text/xml; charset="utf-8"`)
+	w.Header().Set("EXT", "")
+	_, _ = w.Write(b.Bytes())
+}
+
+func writeSoapError(w http.ResponseWriter, err error) {
+	e, ok := err.(*upnpError)
+	if !ok {
+		log.Errorf("dlna action failed: %+v", err)
+		e = &upnpError{Code: errActionFailed, Desc: "Action Failed"}
+	}
+	w.Header().Set("Content-Type", `text/xml; charset="utf-8"`)
+	w.WriteHeader(http.StatusInternalServerError)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?>`+
+		`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body>`+
+		`<s:Fault><faultcode>s:Client</faultcode><faultstring>UPnPError</faultstring><detail>`+
+		`<UPnPError xmlns="urn:schemas-upnp-org:control-1-0"><errorCode>%d</errorCode><errorDescription>%s</errorDescription></UPnPError>`+
+		`</detail></s:Fault></s:Body></s:Envelope>`, e.Code, xmlEscape(e.Desc))
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+const sourceProtocolInfo = "http-get:*:video/*:*,http-get:*:audio/*:*,http-get:*:image/*:*"
+
+func serveConnectionManager(w http.ResponseWriter, r *http.Request) {
+	switch action := soapAction(r); action {
+	case "GetProtocolInfo":
+		writeSoapResponse(w, connManagerType, action, []soapArg{{"Source", sourceProtocolInfo}, {"Sink", ""}})
+	case "GetCurrentConnectionIDs":
+		writeSoapResponse(w, connManagerType, action, []soapArg{{"ConnectionIDs", "0"}})
+	case "GetCurrentConnectionInfo":
+		writeSoapResponse(w, connManagerType, action, []soapArg{
+			{"RcsID", "-1"},
+			{"AVTransportID", "-1"},
+			{"ProtocolInfo", ""},
+			{"PeerConnectionManager", ""},
+			{"PeerConnectionID", "-1"},
+			{"Direction", "Output"},
+			{"Status", "OK"},
+		})
+	default:
+		writeSoapError(w, &upnpError{Code: errInvalidAction, Desc: "Invalid Action"})
+	}
+}
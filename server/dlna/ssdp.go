@@ -0,0 +1,204 @@
+package dlna
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	ssdpMaxAge         = 1800
+	ssdpNotifyInterval = 30 * time.Second
+)
+
+var ssdpAddr = &net.UDPAddr{IP: net.IPv4(239, 255, 255, 250), Port: 1900}
+
+// ssdpServer answers the searches of the devices and announces the server periodically on each interface
+type ssdpServer struct {
+	uuid     string
+	location func(ip net.IP) string
+
+	mu    sync.Mutex
+	conns []*ssdpConn
+	quit  chan struct{}
+	wg    sync.WaitGroup
+}
+
+type ssdpConn struct {
+	*net.UDPConn
+	ip net.IP
+}
+
+func (s *ssdpServer) targets() []string {
+	return []string{"upnp:rootdevice", "uuid:" + s.uuid, mediaServerType, contentDirType, connManagerType}
+}
+
+func (s *ssdpServer) usn(target string) string {
+	if strings.HasPrefix(target, "uuid:") {
+		return target
+	}
+	return "uuid:" + s.uuid + "::" + target
+}
+
+func (s *ssdpServer) start() error {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quit = make(chan struct{})
+	for i := range ifaces {
+		iface := ifaces[i]
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagMulticast == 0 {
+			continue
+		}
+		ip := interfaceIPv4(&iface)
+		if ip == nil {
+			continue
+		}
+		conn, err := net.ListenMulticastUDP("udp4", &iface, ssdpAddr)
+		if err != nil {
+			log.Warnf("failed listen ssdp on %s: %+v", iface.Name, err)
+			continue
+		}
+		c := &ssdpConn{UDPConn: conn, ip: ip}
+		s.conns = append(s.conns, c)
+		s.wg.Add(1)
+		go s.serve(c)
+	}
+	if len(s.conns) == 0 {
+		return errors.New("no interface to listen ssdp")
+	}
+	s.wg.Add(1)
+	go s.notifyLoop()
+	return nil
+}
+
+func (s *ssdpServer) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.quit == nil {
+		return
+	}
+	close(s.quit)
+	s.notify("ssdp:byebye")
+	for _, c := range s.conns {
+		_ = c.Close()
+	}
+	s.wg.Wait()
+	s.conns, s.quit = nil, nil
+}
+
+func interfaceIPv4(iface *net.Interface) net.IP {
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil && !ipNet.IP.IsLoopback() {
+			return ipNet.IP.To4()
+		}
+	}
+	return nil
+}
+
+func (s *ssdpServer) serve(c *ssdpConn) {
+	defer s.wg.Done()
+	buf := make([]byte, 2048)
+	for {
+		n, addr, err := c.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-s.quit:
+			default:
+				log.Warnf("failed read ssdp: %+v", err)
+			}
+			return
+		}
+		req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(buf[:n])))
+		if err != nil || req.Method != "M-SEARCH" || req.Header.Get("MAN") != `"ssdp:discover"` {
+			continue
+		}
+		go s.reply(c, addr, req)
+	}
+}
+
+// reply responds the search after a random delay no more than MX seconds
+func (s *ssdpServer) reply(c *ssdpConn, addr *net.UDPAddr, req *http.Request) {
+	st := req.Header.Get("ST")
+	var targets []string
+	for _, target := range s.targets() {
+		if st == "ssdp:all" || st == target {
+			targets = append(targets, target)
+		}
+	}
+	if len(targets) == 0 {
+		return
+	}
+	mx := 1
+	_, _ = fmt.Sscanf(req.Header.Get("MX"), "%d", &mx)
+	if mx > 5 {
+		mx = 5
+	}
+	if mx > 0 {
+		time.Sleep(time.Duration(rand.Int63n(int64(mx) * int64(time.Second))))
+	}
+	for _, target := range targets {
+		msg := fmt.Sprintf("HTTP/1.1 200 OK\r\n"+
+			"CACHE-CONTROL: max-age=%d\r\n"+
+			"DATE: %s\r\n"+
+			"EXT:\r\n"+
+			"LOCATION: %s\r\n"+
+			"SERVER: %s\r\n"+
+			"ST: %s\r\n"+
+			"USN: %s\r\n\r\n",
+			ssdpMaxAge, time.Now().UTC().Format(http.TimeFormat), s.location(c.ip), serverHeader, target, s.usn(target))
+		if _, err := c.WriteToUDP([]byte(msg), addr); err != nil {
+			log.Debugf("failed reply ssdp search of %s: %+v", addr, err)
+		}
+	}
+}
+
+func (s *ssdpServer) notifyLoop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(ssdpNotifyInterval)
+	defer ticker.Stop()
+	s.notify("ssdp:alive")
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-ticker.C:
+			s.notify("ssdp:alive")
+		}
+	}
+}
+
+func (s *ssdpServer) notify(nts string) {
+	for _, c := range s.conns {
+		for _, target := range s.targets() {
+			msg := fmt.Sprintf("NOTIFY * HTTP/1.1\r\n"+
+				"HOST: %s\r\n"+
+				"CACHE-CONTROL: max-age=%d\r\n"+
+				"LOCATION: %s\r\n"+
+				"NT: %s\r\n"+
+				"NTS: %s\r\n"+
+				"SERVER: %s\r\n"+
+				"USN: %s\r\n\r\n",
+				ssdpAddr, ssdpMaxAge, s.location(c.ip), target, nts, serverHeader, s.usn(target))
+			if _, err := c.WriteToUDP([]byte(msg), ssdpAddr); err != nil {
+				log.Debugf("failed send ssdp notify on %s: %+v", c.ip, err)
+			}
+		}
+	}
+}
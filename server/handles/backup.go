@@ -0,0 +1,53 @@
+package handles
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/alist-org/alist/v3/internal/backup"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func ExportBackup(c *gin.Context) {
+	a, err := backup.Export(c.Query("secrets") == "true")
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="alist-backup-%s.json"`,
+		a.CreatedAt.Format("20060102-150405")))
+	c.JSON(http.StatusOK, a)
+}
+
+func ImportBackup(c *gin.Context) {
+	var a backup.Archive
+	if err := c.ShouldBindJSON(&a); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	mode := c.DefaultQuery("mode", backup.ModeMerge)
+	if err := backup.Import(c, &a, backup.ImportArgs{Mode: mode, Reload: true}); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+type SaveBackupReq struct {
+	Path    string `json:"path" binding:"required"`
+	Secrets bool   `json:"secrets"`
+}
+
+func SaveBackup(c *gin.Context) {
+	var req SaveBackupReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := backup.SaveTo(c, req.Path, req.Secrets); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
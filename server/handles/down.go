@@ -20,6 +20,9 @@ import (
 
 func Down(c *gin.Context) {
 	rawPath := c.MustGet("path").(string)
+	if serveMusicCover(c, rawPath) {
+		return
+	}
 	filename := stdpath.Base(rawPath)
 	storage, err := fs.GetStorage(rawPath, &fs.GetStoragesArgs{})
 	if err != nil {
@@ -67,6 +70,9 @@ func Down(c *gin.Context) {
 
 func Proxy(c *gin.Context) {
 	rawPath := c.MustGet("path").(string)
+	if serveMusicCover(c, rawPath) {
+		return
+	}
 	filename := stdpath.Base(rawPath)
 	storage, err := fs.GetStorage(rawPath, &fs.GetStoragesArgs{})
 	if err != nil {
@@ -0,0 +1,126 @@
+package handles
+
+import (
+	stdpath "path"
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/journal"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const maxChangesLimit = 1000
+
+type FsChangesReq struct {
+	Path     string `json:"path" form:"path"`
+	Cursor   string `json:"cursor" form:"cursor"`
+	Limit    int    `json:"limit" form:"limit"`
+	Password string `json:"password" form:"password"`
+}
+
+type FsChangesResp struct {
+	Changes []model.Change `json:"changes"`
+	// Cursor is passed to get the changes after them
+	Cursor  string `json:"cursor"`
+	HasMore bool   `json:"has_more"`
+}
+
+// FsChanges returns the changes in the path after the cursor for the sync clients,
+// the cursor of now is returned without changes if it's empty.
+// 410 is returned if the changes after the cursor are purged or the journal was disabled since,
+// the client has to list all again.
+func FsChanges(c *gin.Context) {
+	var req FsChangesReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if !journal.Enabled() {
+		common.ErrorStrResp(c, "change journal is disabled", 403)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	first, last, err := db.GetChangeIDRange()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if req.Cursor == "" {
+		common.SuccessResp(c, FsChangesResp{Changes: []model.Change{}, Cursor: strconv.FormatUint(uint64(last), 10)})
+		return
+	}
+	cursor, err := strconv.ParseUint(req.Cursor, 10, 64)
+	if err != nil {
+		common.ErrorStrResp(c, "invalid cursor", 400)
+		return
+	}
+	epoch, err := journal.Epoch()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	// the changes are purged, or not recorded while the journal was disabled
+	if (first > 0 && cursor+1 < uint64(first)) || cursor < uint64(epoch) {
+		common.ErrorStrResp(c, "cursor is expired", 410)
+		return
+	}
+	if req.Limit <= 0 || req.Limit > maxChangesLimit {
+		req.Limit = maxChangesLimit
+	}
+	changes, err := db.GetChanges(reqPath, uint(cursor), req.Limit+1)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	resp := FsChangesResp{Changes: []model.Change{}, Cursor: req.Cursor}
+	if len(changes) > req.Limit {
+		changes, resp.HasMore = changes[:req.Limit], true
+	}
+	for _, change := range changes {
+		// the cursor goes over the changes hidden from the user too
+		resp.Cursor = strconv.FormatUint(uint64(change.ID), 10)
+		if visible, ok := visibleChange(user, change, reqPath, req.Password); ok {
+			resp.Changes = append(resp.Changes, visible)
+		}
+	}
+	common.SuccessResp(c, resp)
+}
+
+// visibleChange returns the change as the user sees it, a move is a delete or create
+// if the user can only access one side of it. The changes in the folders hidden from the user are hidden too.
+func visibleChange(user *model.User, change model.Change, reqPath, password string) (model.Change, bool) {
+	canAccess := func(path string) bool {
+		if path == "" || !utils.IsSubPath(reqPath, path) || !utils.IsSubPath(user.BasePath, path) {
+			return false
+		}
+		meta, err := op.GetNearestMeta(stdpath.Dir(path))
+		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			return false
+		}
+		return common.CanAccess(user, meta, path, password) && !fs.IsHidden(user, reqPath, path)
+	}
+	src, dst := canAccess(change.Path), canAccess(change.DstPath)
+	switch {
+	case change.Type != model.ChangeMove:
+		return change, src
+	case src && dst:
+		return change, true
+	case src:
+		return model.Change{ID: change.ID, Type: model.ChangeDelete, Path: change.Path, CreatedAt: change.CreatedAt}, true
+	case dst:
+		return model.Change{ID: change.ID, Type: model.ChangeCreate, Path: change.DstPath, CreatedAt: change.CreatedAt}, true
+	}
+	return change, false
+}
@@ -1,11 +1,13 @@
 package handles
 
 import (
+	"context"
 	"fmt"
 	"github.com/alist-org/alist/v3/internal/task"
 	"io"
 	stdpath "path"
 
+	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -59,6 +61,8 @@ type MoveCopyReq struct {
 	SrcDir string   `json:"src_dir"`
 	DstDir string   `json:"dst_dir"`
 	Names  []string `json:"names"`
+	// Verify makes the copy tasks compare the hashes after transfer
+	Verify bool `json:"verify"`
 }
 
 func FsMove(c *gin.Context) {
@@ -121,9 +125,13 @@ func FsCopy(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	var ctx context.Context = c
+	if req.Verify {
+		ctx = context.WithValue(c, conf.VerifyKey, struct{}{})
+	}
 	var addedTasks []task.TaskInfoWithCreator
 	for i, name := range req.Names {
-		t, err := fs.Copy(c, stdpath.Join(srcDir, name), dstDir, len(req.Names) > i+1)
+		t, err := fs.Copy(ctx, stdpath.Join(srcDir, name), dstDir, len(req.Names) > i+1)
 		if t != nil {
 			addedTasks = append(addedTasks, t)
 		}
@@ -24,6 +24,9 @@ type ListReq struct {
 	Path     string `json:"path" form:"path"`
 	Password string `json:"password" form:"password"`
 	Refresh  bool   `json:"refresh"`
+	// Cursor list by page token instead of page number, the total is unknown then
+	Cursor bool   `json:"cursor" form:"cursor"`
+	Token  string `json:"token" form:"token"`
 }
 
 type DirReq struct {
@@ -52,6 +55,8 @@ type FsListResp struct {
 	Header   string    `json:"header"`
 	Write    bool      `json:"write"`
 	Provider string    `json:"provider"`
+	// Next is the token of the next page in cursor mode
+	Next string `json:"next,omitempty"`
 }
 
 func FsList(c *gin.Context) {
@@ -83,12 +88,24 @@ func FsList(c *gin.Context) {
 		common.ErrorStrResp(c, "Refresh without permission", 403)
 		return
 	}
-	objs, err := fs.List(c, reqPath, &fs.ListArgs{Refresh: req.Refresh})
+	var objs []model.Obj
+	var total int
+	var next string
+	if req.Cursor || req.Token != "" {
+		objs, next, err = fs.ListPage(c, reqPath, &fs.ListPageArgs{
+			Token:   req.Token,
+			Limit:   req.PerPage,
+			Refresh: req.Refresh && req.Token == "",
+		})
+		total = -1
+	} else {
+		objs, err = fs.List(c, reqPath, &fs.ListArgs{Refresh: req.Refresh})
+		total, objs = pagination(objs, &req.PageReq)
+	}
 	if err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
-	total, objs := pagination(objs, &req.PageReq)
 	provider := "unknown"
 	storage, err := fs.GetStorage(reqPath, &fs.GetStoragesArgs{})
 	if err == nil {
@@ -101,6 +118,7 @@ func FsList(c *gin.Context) {
 		Header:   getHeader(meta, reqPath),
 		Write:    user.CanWrite() || common.CanWrite(meta, reqPath),
 		Provider: provider,
+		Next:     next,
 	})
 }
 
@@ -395,3 +413,73 @@ func FsOther(c *gin.Context) {
 	}
 	common.SuccessResp(c, res)
 }
+
+type FsHashReq struct {
+	Path     string `json:"path" form:"path"`
+	Password string `json:"password" form:"password"`
+	// Type is the name of the hash type, md5 by default
+	Type string `json:"type" form:"type"`
+}
+
+type FsHashResp struct {
+	Type string    `json:"type"`
+	Hash string    `json:"hash"`
+	Task *TaskInfo `json:"task,omitempty"`
+}
+
+// FsHash returns the hash of the file if it's known, otherwise a task is added to compute it,
+// and the hash is returned by requesting again after the task succeeded
+func FsHash(c *gin.Context) {
+	var req FsHashReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.Type == "" {
+		req.Type = utils.MD5.Name
+	}
+	ht, ok := utils.GetHashByName(req.Type)
+	if !ok {
+		common.ErrorStrResp(c, "unsupported hash type: "+req.Type, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	c.Set("meta", meta)
+	if !common.CanAccess(user, meta, reqPath, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	hash, err := fs.GetHash(c, reqPath, ht)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	resp := FsHashResp{Type: ht.Name, Hash: hash}
+	if hash == "" {
+		// hashing streams the whole file, which is not allowed for guest
+		if user.IsGuest() {
+			common.ErrorStrResp(c, "You are a guest", 403)
+			return
+		}
+		t, err := fs.HashAsTask(c, reqPath, ht)
+		if err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+		info := getTaskInfo(t)
+		resp.Task = &info
+	}
+	common.SuccessResp(c, resp)
+}
@@ -1,6 +1,7 @@
 package handles
 
 import (
+	"context"
 	"github.com/alist-org/alist/v3/internal/task"
 	"io"
 	"net/url"
@@ -8,11 +9,14 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 )
 
 func getLastModified(c *gin.Context) time.Time {
@@ -26,6 +30,26 @@ func getLastModified(c *gin.Context) time.Time {
 	return lastModified
 }
 
+// putTaskCtx makes the upload task verify the hash after upload if the Verify header is true
+func putTaskCtx(c *gin.Context) context.Context {
+	if c.GetHeader("Verify") == "true" {
+		return context.WithValue(c, conf.VerifyKey, struct{}{})
+	}
+	return c
+}
+
+// fileConflict responds 403 if the Overwrite header is false and path exists
+func fileConflict(c *gin.Context, path string) bool {
+	if c.GetHeader("Overwrite") != "false" {
+		return false
+	}
+	if res, _ := fs.Get(c, path, &fs.GetArgs{NoLog: true}); res == nil {
+		return false
+	}
+	common.ErrorStrResp(c, "file exists", 403)
+	return true
+}
+
 func FsStream(c *gin.Context) {
 	path := c.GetHeader("File-Path")
 	path, err := url.PathUnescape(path)
@@ -40,6 +64,9 @@ func FsStream(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if fileConflict(c, path) {
+		return
+	}
 	dir, name := stdpath.Split(path)
 	sizeStr := c.GetHeader("Content-Length")
 	size, err := strconv.ParseInt(sizeStr, 10, 64)
@@ -59,7 +86,7 @@ func FsStream(c *gin.Context) {
 	}
 	var t task.TaskInfoWithCreator
 	if asTask {
-		t, err = fs.PutAsTask(c, dir, s)
+		t, err = fs.PutAsTask(putTaskCtx(c), dir, s)
 	} else {
 		err = fs.PutDirectly(c, dir, s, true)
 	}
@@ -91,6 +118,9 @@ func FsForm(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if fileConflict(c, path) {
+		return
+	}
 	storage, err := fs.GetStorage(path, &fs.GetStoragesArgs{})
 	if err != nil {
 		common.ErrorResp(c, err, 400)
@@ -127,7 +157,7 @@ func FsForm(c *gin.Context) {
 		s.Reader = struct {
 			io.Reader
 		}{f}
-		t, err = fs.PutAsTask(c, dir, &s)
+		t, err = fs.PutAsTask(putTaskCtx(c), dir, &s)
 	} else {
 		ss, err := stream.NewSeekableStream(s, nil)
 		if err != nil {
@@ -148,3 +178,89 @@ func FsForm(c *gin.Context) {
 		"task": getTaskInfo(t),
 	})
 }
+
+type UploadSessionReq struct {
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+}
+
+type UploadSessionResp struct {
+	// Direct is false if the storage doesn't support direct upload,
+	// then the file should be uploaded by /fs/put or /fs/form
+	Direct  bool                 `json:"direct"`
+	Token   string               `json:"token,omitempty"`
+	Session *model.UploadSession `json:"session,omitempty"`
+}
+
+func FsUploadSession(c *gin.Context) {
+	var req UploadSessionReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	path, err := url.PathUnescape(c.GetHeader("File-Path"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	path, err = user.JoinPath(path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	if fileConflict(c, path) {
+		return
+	}
+	token, session, err := fs.CreateUploadSession(c, path, model.UploadSessionArgs{
+		Size:     req.Size,
+		Mimetype: req.ContentType,
+	})
+	if errs.IsNotImplement(err) {
+		common.SuccessResp(c, UploadSessionResp{Direct: false})
+		return
+	}
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, UploadSessionResp{
+		Direct:  true,
+		Token:   token,
+		Session: session,
+	})
+}
+
+type UploadCompleteReq struct {
+	Token string             `json:"token" binding:"required"`
+	Parts []model.UploadPart `json:"parts"`
+}
+
+func FsUploadComplete(c *gin.Context) {
+	var req UploadCompleteReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	path, err := url.PathUnescape(c.GetHeader("File-Path"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	path, err = user.JoinPath(path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	err = fs.CompleteUploadSession(c, path, req.Token, req.Parts)
+	if errors.Is(errors.Cause(err), errs.UploadSessionNotFound) {
+		common.ErrorResp(c, err, 404)
+		return
+	}
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -2,6 +2,7 @@ package handles
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -19,7 +20,7 @@ type UpdateIndexReq struct {
 }
 
 func BuildIndex(c *gin.Context) {
-	if search.Running() {
+	if search.Running() || search.JobRunning() {
 		common.ErrorStrResp(c, "index is running", 400)
 		return
 	}
@@ -45,7 +46,7 @@ func UpdateIndex(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if search.Running() {
+	if search.Running() || search.JobRunning() {
 		common.ErrorStrResp(c, "index is running", 400)
 		return
 	}
@@ -85,7 +86,7 @@ func StopIndex(c *gin.Context) {
 }
 
 func ClearIndex(c *gin.Context) {
-	if search.Running() {
+	if search.Running() || search.JobRunning() {
 		common.ErrorStrResp(c, "index is running", 400)
 		return
 	}
@@ -107,3 +108,103 @@ func GetProgress(c *gin.Context) {
 	}
 	common.SuccessResp(c, progress)
 }
+
+func ListIndexJobs(c *gin.Context) {
+	jobs, err := search.GetIndexJobs()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, jobs)
+}
+
+func GetIndexJob(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	job, err := search.GetIndexJobById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, job)
+}
+
+func CreateIndexJob(c *gin.Context) {
+	var req model.IndexJob
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := search.CreateIndexJob(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func UpdateIndexJob(c *gin.Context) {
+	var req model.IndexJob
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := search.UpdateIndexJob(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func DeleteIndexJob(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := search.DeleteIndexJobById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func RunIndexJob(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if search.Running() || search.JobRunning() {
+		common.ErrorStrResp(c, "index is running", 400)
+		return
+	}
+	if !search.Config(c).AutoUpdate {
+		common.ErrorStrResp(c, "update is not supported for current index", 400)
+		return
+	}
+	job, err := search.GetIndexJobById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	go func() {
+		if err := search.RunIndexJob(context.Background(), job); err != nil {
+			log.Errorf("run index job [%d] error: %+v", job.ID, err)
+		}
+	}()
+	common.SuccessResp(c)
+}
+
+func StopIndexJob(c *gin.Context) {
+	if !search.StopIndexJob() {
+		common.ErrorStrResp(c, "index job is not running", 400)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -0,0 +1,270 @@
+package handles
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/music"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type MusicReq struct {
+	model.PageReq
+	Artist   string `json:"artist" form:"artist"`
+	Album    string `json:"album" form:"album"`
+	Password string `json:"password" form:"password"`
+}
+
+type MusicAlbumResp struct {
+	model.MusicAlbum
+	Thumb string `json:"thumb"`
+}
+
+type MusicTrackResp struct {
+	model.MusicTrack
+	Thumb string `json:"thumb"`
+}
+
+// signedURL returns the signed /d link of the path, with type=thumb the thumbnail of the storage is served
+func signedURL(c *gin.Context, path string, thumb bool) string {
+	if path == "" {
+		return ""
+	}
+	query := ""
+	if thumb {
+		query = "type=thumb&"
+	}
+	return fmt.Sprintf("%s/d%s?%ssign=%s", common.GetApiUrl(c.Request), utils.EncodePath(path, true), query, sign.Sign(path))
+}
+
+// coverURL returns the thumbnail link of the track serving its embedded cover,
+// it's signed for the thumbnail only, so the track can't be downloaded by it
+func coverURL(c *gin.Context, path string) string {
+	return fmt.Sprintf("%s/d%s?type=thumb&sign=%s", common.GetApiUrl(c.Request), utils.EncodePath(path, true), sign.Thumb(path))
+}
+
+// musicAccess checks whether the user can access the tracks, the nearest meta is cached
+// by the parent folder, as the tracks of an album are usually in the same one
+type musicAccess struct {
+	user     *model.User
+	password string
+	metas    map[string]*model.Meta
+}
+
+func newMusicAccess(user *model.User, password string) *musicAccess {
+	return &musicAccess{user: user, password: password, metas: make(map[string]*model.Meta)}
+}
+
+func (a *musicAccess) can(path string) bool {
+	dir := stdpath.Dir(path)
+	meta, ok := a.metas[dir]
+	if !ok {
+		var err error
+		meta, err = op.GetNearestMeta(dir)
+		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			return false
+		}
+		a.metas[dir] = meta
+	}
+	return common.CanAccess(a.user, meta, path, a.password)
+}
+
+// any checks whether the user can access any track of the artist, or the album if it's not empty
+func (a *musicAccess) any(artist, album string) (bool, error) {
+	paths, err := db.GetMusicTrackPaths(a.user.BasePath, artist, album)
+	if err != nil {
+		return false, err
+	}
+	for _, path := range paths {
+		if a.can(path) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// MusicArtists gets the artists of the tracks the user can access, the total is not filtered like search
+func MusicArtists(c *gin.Context) {
+	var req MusicReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	user := c.MustGet("user").(*model.User)
+	artists, total, err := db.GetMusicArtists(user.BasePath, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	access := newMusicAccess(user, req.Password)
+	filtered := make([]model.MusicArtist, 0, len(artists))
+	for _, artist := range artists {
+		ok, err := access.any(artist.Name, "")
+		if err != nil {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+		if ok {
+			filtered = append(filtered, artist)
+		}
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: filtered,
+		Total:   total,
+	})
+}
+
+// MusicAlbums gets the albums of the tracks the user can access, the total is not filtered like search
+func MusicAlbums(c *gin.Context) {
+	var req MusicReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	user := c.MustGet("user").(*model.User)
+	albums, total, err := db.GetMusicAlbums(user.BasePath, req.Artist, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	access := newMusicAccess(user, req.Password)
+	filtered := make([]MusicAlbumResp, 0, len(albums))
+	for _, album := range albums {
+		ok, err := access.any(album.Artist, album.Name)
+		if err != nil {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+		if !ok {
+			continue
+		}
+		thumb := ""
+		if album.CoverPath != "" && access.can(album.CoverPath) {
+			thumb = coverURL(c, album.CoverPath)
+		}
+		filtered = append(filtered, MusicAlbumResp{MusicAlbum: album, Thumb: thumb})
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: filtered,
+		Total:   total,
+	})
+}
+
+// getMusicTracks gets the tracks the user can access, the total is not filtered like search
+func getMusicTracks(c *gin.Context, req MusicReq) ([]model.MusicTrack, int64, error) {
+	user := c.MustGet("user").(*model.User)
+	tracks, total, err := db.GetMusicTracks(user.BasePath, req.Artist, req.Album, req.Page, req.PerPage)
+	if err != nil {
+		return nil, 0, err
+	}
+	access := newMusicAccess(user, req.Password)
+	var filtered []model.MusicTrack
+	for _, track := range tracks {
+		if access.can(track.Path) {
+			filtered = append(filtered, track)
+		}
+	}
+	return filtered, total, nil
+}
+
+func MusicTracks(c *gin.Context) {
+	var req MusicReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	tracks, total, err := getMusicTracks(c, req)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: utils.MustSliceConvert(tracks, func(track model.MusicTrack) MusicTrackResp {
+			thumb := ""
+			if track.Cover != "" {
+				thumb = coverURL(c, track.Path)
+			}
+			return MusicTrackResp{MusicTrack: track, Thumb: thumb}
+		}),
+		Total: total,
+	})
+}
+
+// MusicPlaylist returns the tracks as a M3U playlist, the links are signed so that players can play them
+func MusicPlaylist(c *gin.Context) {
+	var req MusicReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	tracks, _, err := getMusicTracks(c, req)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	for _, track := range tracks {
+		duration := int(track.Duration)
+		if duration <= 0 {
+			duration = -1
+		}
+		title := track.Title
+		if track.Artist != "" {
+			title = track.Artist + " - " + title
+		}
+		fmt.Fprintf(&b, "#EXTINF:%d,%s\n", duration, title)
+		fmt.Fprintln(&b, signedURL(c, track.Path, false))
+	}
+	c.Header("Content-Disposition", `attachment; filename="playlist.m3u"`)
+	c.Data(200, "audio/x-mpegurl; charset=utf-8", []byte(b.String()))
+}
+
+// serveMusicCover serves the embedded cover as the thumbnail of the track in the music library,
+// a link signed for the thumbnail only gives nothing else
+func serveMusicCover(c *gin.Context, path string) bool {
+	if c.Query("type") != "thumb" {
+		return false
+	}
+	if cover := music.GetCover(path); cover != "" {
+		c.File(cover)
+		return true
+	}
+	if c.GetBool("thumb_only") {
+		common.ErrorStrResp(c, "thumbnail not found", 404)
+		return true
+	}
+	return false
+}
+
+func GetMusicStatus(c *gin.Context) {
+	common.SuccessResp(c, music.GetStatus())
+}
+
+func ScanMusic(c *gin.Context) {
+	if music.GetStatus().Running {
+		common.ErrorStrResp(c, "music library is scanning", 400)
+		return
+	}
+	go music.Scan(context.Background())
+	common.SuccessResp(c)
+}
+
+func StopScanMusic(c *gin.Context) {
+	music.Stop()
+	common.SuccessResp(c)
+}
@@ -0,0 +1,157 @@
+package handles
+
+import (
+	stdpath "path"
+	"sort"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/photo"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type PhotoReq struct {
+	model.PhotoReq
+	Password string `json:"password"`
+}
+
+type PhotoResp struct {
+	model.PhotoMeta
+	URL   string `json:"url"`
+	Thumb string `json:"thumb"`
+}
+
+// PhotoList lists the scanned photos by the capture date, camera or location across storages
+func PhotoList(c *gin.Context) {
+	var (
+		req PhotoReq
+		err error
+	)
+	if err = c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	req.Parent, err = user.JoinPath(req.Parent)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	photos, total, err := db.GetPhotoMetas(req.PhotoReq)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	var resp []PhotoResp
+	for _, p := range accessiblePhotos(user, req.Password, photos) {
+		path := stdpath.Join(p.Parent, p.Name)
+		resp = append(resp, PhotoResp{
+			PhotoMeta: p,
+			URL:       signedURL(c, path, false),
+			Thumb:     signedURL(c, path, true),
+		})
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: resp,
+		Total:   total,
+	})
+}
+
+// accessiblePhotos filters the photos the user can access, the nearest meta is cached by the parent
+func accessiblePhotos(user *model.User, password string, photos []model.PhotoMeta) []model.PhotoMeta {
+	metas := make(map[string]*model.Meta)
+	var res []model.PhotoMeta
+	for _, p := range photos {
+		if !utils.IsSubPath(user.BasePath, p.Parent) {
+			continue
+		}
+		meta, ok := metas[p.Parent]
+		if !ok {
+			var err error
+			meta, err = op.GetNearestMeta(p.Parent)
+			if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+				continue
+			}
+			metas[p.Parent] = meta
+		}
+		if common.CanAccess(user, meta, stdpath.Join(p.Parent, p.Name), password) {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+type PhotoCamerasReq struct {
+	Password string `json:"password" form:"password"`
+}
+
+// PhotoCameras lists the cameras of the photos the user can access, which can be used to filter the photos
+func PhotoCameras(c *gin.Context) {
+	var req PhotoCamerasReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	photos, err := db.GetCameraPhotos(user.BasePath)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	counts := make(map[model.PhotoCamera]int)
+	for _, p := range accessiblePhotos(user, req.Password, photos) {
+		counts[model.PhotoCamera{Make: p.Make, Model: p.Model}]++
+	}
+	cameras := make([]model.PhotoCamera, 0, len(counts))
+	for camera, n := range counts {
+		camera.Photos = n
+		cameras = append(cameras, camera)
+	}
+	sort.Slice(cameras, func(i, j int) bool {
+		if cameras[i].Make != cameras[j].Make {
+			return cameras[i].Make < cameras[j].Make
+		}
+		return cameras[i].Model < cameras[j].Model
+	})
+	common.SuccessResp(c, cameras)
+}
+
+type ScanPhotosReq struct {
+	Paths    []string `json:"paths"`
+	MaxDepth int      `json:"max_depth"`
+}
+
+// ScanPhotos adds the tasks to scan the exif of the images in the paths
+func ScanPhotos(c *gin.Context) {
+	var req ScanPhotosReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if len(req.Paths) == 0 {
+		common.ErrorStrResp(c, "paths can't be empty", 400)
+		return
+	}
+	if req.MaxDepth == 0 {
+		req.MaxDepth = setting.GetInt(conf.MaxIndexDepth, 20)
+	}
+	var addedTasks []task.TaskInfoWithCreator
+	for _, path := range req.Paths {
+		addedTasks = append(addedTasks, photo.AddScanTask(c, path, req.MaxDepth, conf.SlicesMap[conf.IgnorePaths]))
+	}
+	common.SuccessResp(c, gin.H{
+		"tasks": getTaskInfos(addedTasks),
+	})
+}
@@ -26,6 +26,9 @@ func ListStorages(c *gin.Context) {
 		common.ErrorResp(c, err, 500)
 		return
 	}
+	for i := range storages {
+		op.MaskStorage(&storages[i])
+	}
 	common.SuccessResp(c, common.PageResp{
 		Content: storages,
 		Total:   total,
@@ -116,6 +119,15 @@ func GetStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
+	if c.Query("reveal") == "true" {
+		storage.Addition, err = op.DecryptAddition(storage.Driver, storage.Addition)
+		if err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	} else {
+		op.MaskStorage(storage)
+	}
 	common.SuccessResp(c, storage)
 }
 
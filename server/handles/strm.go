@@ -0,0 +1,53 @@
+package handles
+
+import (
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type StrmExportReq struct {
+	SrcPath  string `json:"src_path"`
+	DstPath  string `json:"dst_path"`
+	Local    bool   `json:"local"`
+	SiteURL  string `json:"site_url"`
+	MaxDepth int    `json:"max_depth"`
+}
+
+// StrmExport adds a task to export the strm files of the media in the src path for media servers
+func StrmExport(c *gin.Context) {
+	var req StrmExportReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.SrcPath == "" || req.DstPath == "" {
+		common.ErrorStrResp(c, "src_path and dst_path can't be empty", 400)
+		return
+	}
+	// the media servers play by the links in strm files, so the url should be reachable by them
+	if req.SiteURL == "" {
+		req.SiteURL = common.GetApiUrl(c.Request)
+	}
+	if req.MaxDepth == 0 {
+		req.MaxDepth = setting.GetInt(conf.MaxIndexDepth, 20)
+	}
+	t, err := fs.StrmExportAsTask(c, &fs.StrmExportTask{
+		SrcPath:  req.SrcPath,
+		DstPath:  req.DstPath,
+		Local:    req.Local,
+		SiteURL:  strings.TrimSuffix(req.SiteURL, "/"),
+		MaxDepth: req.MaxDepth,
+	})
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"task": getTaskInfo(t),
+	})
+}
@@ -0,0 +1,282 @@
+package handles
+
+import (
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type TagReq struct {
+	Path     string `json:"path" form:"path"`
+	Name     string `json:"name"`
+	Shared   bool   `json:"shared"`
+	Password string `json:"password" form:"password"`
+}
+
+// canAccessTagged checks whether the user can access the tagged object like tagTarget does
+func canAccessTagged(user *model.User, path, password string) bool {
+	if !utils.IsSubPath(user.BasePath, path) {
+		return false
+	}
+	meta, err := op.GetNearestMeta(path)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return false
+	}
+	return common.CanAccess(user, meta, path, password)
+}
+
+// accessibleTags filters the tags of the objects the user can access
+func accessibleTags(user *model.User, password string, tags []model.Tag) []model.Tag {
+	res := make([]model.Tag, 0, len(tags))
+	for _, tag := range tags {
+		if canAccessTagged(user, tag.Path, password) {
+			res = append(res, tag)
+		}
+	}
+	return res
+}
+
+// pageOf returns the page of the items, page and perPage are validated
+func pageOf[T any](items []T, page, perPage int) []T {
+	// compared by pages, so a huge perPage doesn't overflow
+	if len(items) == 0 || page-1 > (len(items)-1)/perPage {
+		return items[:0]
+	}
+	start := (page - 1) * perPage
+	return items[start : start+min(perPage, len(items)-start)]
+}
+
+// tagTarget returns the tag of the request after checking the user can see the object,
+// and change the shared tags if they are asked
+func tagTarget(c *gin.Context, req TagReq) (*model.Tag, bool) {
+	user := c.MustGet("user").(*model.User)
+	if err := model.ValidateTagName(req.Name); err != nil {
+		common.ErrorResp(c, err, 400)
+		return nil, false
+	}
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return nil, false
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return nil, false
+	}
+	if !common.CanAccess(user, meta, reqPath, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return nil, false
+	}
+	tag := &model.Tag{Path: reqPath, Name: req.Name, UserID: user.ID}
+	if req.Shared {
+		if req.Name == model.FavoriteTag {
+			common.ErrorStrResp(c, "favorite can't be a shared tag", 400)
+			return nil, false
+		}
+		if !user.CanWrite() && !common.CanWrite(meta, stdpath.Dir(reqPath)) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return nil, false
+		}
+		tag.UserID = 0
+	}
+	return tag, true
+}
+
+// AddTag tags the object for the user, or for all users if it's shared
+func AddTag(c *gin.Context) {
+	var req TagReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	tag, ok := tagTarget(c, req)
+	if !ok {
+		return
+	}
+	if _, err := fs.Get(c, tag.Path, &fs.GetArgs{}); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if err := db.CreateTag(tag); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, tag)
+}
+
+func RemoveTag(c *gin.Context) {
+	var req TagReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	tag, ok := tagTarget(c, req)
+	if !ok {
+		return
+	}
+	if err := db.DeleteTag(tag.Path, tag.Name, tag.UserID); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// GetTags returns the tags of the object visible to the user
+func GetTags(c *gin.Context) {
+	var req TagReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	if !canAccessTagged(user, reqPath, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	tags, err := db.GetTagsByPath(reqPath, user.ID)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, tags)
+}
+
+// TagNames lists the tags visible to the user with the number of the tagged objects the user can access
+func TagNames(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	tags, err := db.GetTagsUnder(user.ID, user.BasePath)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	counts := make([]model.TagCount, 0)
+	index := make(map[model.TagCount]int)
+	// the tags are ordered by name, so are the counts
+	for _, tag := range accessibleTags(user, "", tags) {
+		key := model.TagCount{Name: tag.Name, Shared: tag.Shared()}
+		i, ok := index[key]
+		if !ok {
+			i = len(counts)
+			index[key] = i
+			counts = append(counts, key)
+		}
+		counts[i].Objects++
+	}
+	common.SuccessResp(c, counts)
+}
+
+type ListByTagReq struct {
+	Name     string `json:"name" form:"name"`
+	Parent   string `json:"parent" form:"parent"`
+	Password string `json:"password" form:"password"`
+	model.PageReq
+}
+
+type TagResp struct {
+	model.Tag
+	Shared bool `json:"shared"`
+}
+
+// ListByTag lists the objects with the tag which the user can access
+func ListByTag(c *gin.Context) {
+	var req ListByTagReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	listByTag(c, req)
+}
+
+func listByTag(c *gin.Context, req ListByTagReq) {
+	req.Validate()
+	user := c.MustGet("user").(*model.User)
+	parent, err := user.JoinPath(req.Parent)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	tags, err := db.GetTagsByName(req.Name, user.ID, parent)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	// filtered before paging, so the pages are full and the total is what the user can see
+	tags = accessibleTags(user, req.Password, tags)
+	resp := utils.MustSliceConvert(pageOf(tags, req.Page, req.PerPage), func(tag model.Tag) TagResp {
+		return TagResp{Tag: tag, Shared: tag.Shared()}
+	})
+	common.SuccessResp(c, common.PageResp{
+		Content: resp,
+		Total:   int64(len(tags)),
+	})
+}
+
+type FavoriteReq struct {
+	Path     string `json:"path"`
+	Password string `json:"password"`
+}
+
+func AddFavorite(c *gin.Context) {
+	var req FavoriteReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	tag, ok := tagTarget(c, TagReq{Path: req.Path, Name: model.FavoriteTag, Password: req.Password})
+	if !ok {
+		return
+	}
+	if _, err := fs.Get(c, tag.Path, &fs.GetArgs{}); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if err := db.CreateTag(tag); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func RemoveFavorite(c *gin.Context) {
+	var req FavoriteReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	if err := db.DeleteTag(reqPath, model.FavoriteTag, user.ID); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// ListFavorites lists the favorites of the user
+func ListFavorites(c *gin.Context) {
+	var req ListByTagReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Name = model.FavoriteTag
+	listByTag(c, req)
+}
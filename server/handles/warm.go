@@ -0,0 +1,104 @@
+package handles
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/warmer"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type WarmJobResp struct {
+	model.WarmJob
+	Status warmer.Status `json:"status"`
+}
+
+func ListWarmJobs(c *gin.Context) {
+	jobs, err := warmer.GetWarmJobs()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	resp := make([]WarmJobResp, 0, len(jobs))
+	for _, job := range jobs {
+		resp = append(resp, WarmJobResp{WarmJob: job, Status: warmer.GetStatus(job.ID)})
+	}
+	common.SuccessResp(c, resp)
+}
+
+func GetWarmJob(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	job, err := warmer.GetWarmJobById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, WarmJobResp{WarmJob: *job, Status: warmer.GetStatus(job.ID)})
+}
+
+func CreateWarmJob(c *gin.Context) {
+	var req model.WarmJob
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := warmer.CreateWarmJob(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func UpdateWarmJob(c *gin.Context) {
+	var req model.WarmJob
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := warmer.UpdateWarmJob(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func DeleteWarmJob(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := warmer.DeleteWarmJobById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func RunWarmJob(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	job, err := warmer.GetWarmJobById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if warmer.GetStatus(job.ID).Running {
+		common.ErrorStrResp(c, "warm job is running", 400)
+		return
+	}
+	go warmer.Run(context.Background(), job)
+	common.SuccessResp(c)
+}
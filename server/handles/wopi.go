@@ -0,0 +1,447 @@
+package handles
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	stdpath "path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/cache"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the lock of a file expires in 30 minutes as the wopi spec, unless it's refreshed by the client
+const wopiLockExpiration = 30 * time.Minute
+
+var (
+	wopiLocks = cache.New[string]("wopi_lock", 16)
+	// wopiLockMu makes the check and change of the lock of a file, and the write checked by it, atomic in this instance
+	wopiLockMu pathMutex
+	// wopiDiscovery caches the editor urls of the extensions provided by the wopi client
+	wopiDiscovery = cache.New[map[string]string]("wopi_discovery", 1)
+)
+
+type FsWopiReq struct {
+	Path     string `json:"path" form:"path"`
+	Password string `json:"password" form:"password"`
+}
+
+type FsWopiResp struct {
+	AccessToken    string `json:"access_token"`
+	AccessTokenTTL int64  `json:"access_token_ttl"`
+	WopiSrc        string `json:"wopi_src"`
+	// ActionURL is the url of the editor of the file, empty if the wopi client doesn't support it
+	ActionURL string `json:"action_url"`
+	Write     bool   `json:"write"`
+}
+
+func wopiFileID(path string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(path))
+}
+
+// FsWopi issues the wopi access token of the file for the user, which is used by the wopi client to edit it
+func FsWopi(c *gin.Context) {
+	var req FsWopiReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if !common.CanAccess(user, meta, reqPath, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	obj, err := fs.Get(c, reqPath, &fs.GetArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if obj.IsDir() {
+		common.ErrorResp(c, errs.NotFile, 400)
+		return
+	}
+	write := user.CanWrite() || common.CanWrite(meta, stdpath.Dir(reqPath))
+	token, expiresAt, err := common.GenerateWopiToken(user, reqPath, req.Password)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	wopiSrc := fmt.Sprintf("%s/wopi/files/%s", common.GetApiUrl(c.Request), wopiFileID(reqPath))
+	actionURL, err := wopiActionURL(obj.GetName(), write, wopiSrc)
+	if err != nil {
+		log.Warnf("failed get wopi discovery: %+v", err)
+	}
+	common.SuccessResp(c, FsWopiResp{
+		AccessToken:    token,
+		AccessTokenTTL: expiresAt.UnixMilli(),
+		WopiSrc:        wopiSrc,
+		ActionURL:      actionURL,
+		Write:          write,
+	})
+}
+
+type wopiDiscoveryXML struct {
+	NetZones []struct {
+		Apps []struct {
+			Actions []struct {
+				Name   string `xml:"name,attr"`
+				Ext    string `xml:"ext,attr"`
+				URLSrc string `xml:"urlsrc,attr"`
+			} `xml:"action"`
+		} `xml:"app"`
+	} `xml:"net-zone"`
+}
+
+// the optional parameters in the urlsrc, like <ui=UI_LLCC&>
+var wopiPlaceholder = regexp.MustCompile(`<[^>]*>`)
+
+// getWopiDiscovery returns the urls of the actions by ext, the key is like "edit.docx"
+func getWopiDiscovery() (map[string]string, error) {
+	clientURL := strings.TrimSuffix(setting.GetStr(conf.WopiClientURL), "/")
+	if clientURL == "" {
+		return nil, nil
+	}
+	if actions, ok := wopiDiscovery.Get(clientURL); ok {
+		return actions, nil
+	}
+	res, err := base.RestyClient.R().Get(clientURL + "/hosting/discovery")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if res.IsError() {
+		return nil, errors.Errorf("wopi discovery responds %s", res.Status())
+	}
+	var discovery wopiDiscoveryXML
+	if err = xml.Unmarshal(res.Body(), &discovery); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	actions := make(map[string]string)
+	for _, zone := range discovery.NetZones {
+		for _, app := range zone.Apps {
+			for _, action := range app.Actions {
+				if action.Ext == "" || action.URLSrc == "" {
+					continue
+				}
+				key := action.Name + "." + strings.ToLower(action.Ext)
+				if _, ok := actions[key]; !ok {
+					actions[key] = wopiPlaceholder.ReplaceAllString(action.URLSrc, "")
+				}
+			}
+		}
+	}
+	wopiDiscovery.Set(clientURL, actions, time.Hour)
+	return actions, nil
+}
+
+// wopiActionURL returns the url to edit or view the file in the wopi client
+func wopiActionURL(name string, write bool, wopiSrc string) (string, error) {
+	actions, err := getWopiDiscovery()
+	if err != nil || actions == nil {
+		return "", err
+	}
+	ext := strings.ToLower(utils.Ext(name))
+	urlSrc, ok := "", false
+	if write {
+		urlSrc, ok = actions["edit."+ext]
+	}
+	if !ok {
+		urlSrc, ok = actions["view."+ext]
+	}
+	if !ok {
+		return "", nil
+	}
+	if !strings.HasSuffix(urlSrc, "?") && !strings.HasSuffix(urlSrc, "&") {
+		if strings.Contains(urlSrc, "?") {
+			urlSrc += "&"
+		} else {
+			urlSrc += "?"
+		}
+	}
+	return urlSrc + "WOPISrc=" + url.QueryEscape(wopiSrc), nil
+}
+
+// pathMutex locks by path, the mutex of a path is dropped once it's unlocked by all
+type pathMutex struct {
+	mu    sync.Mutex
+	locks map[string]*pathLock
+}
+
+type pathLock struct {
+	sync.Mutex
+	refs int
+}
+
+// Lock locks the path and returns the function to unlock it
+func (m *pathMutex) Lock(path string) func() {
+	m.mu.Lock()
+	if m.locks == nil {
+		m.locks = make(map[string]*pathLock)
+	}
+	l, ok := m.locks[path]
+	if !ok {
+		l = &pathLock{}
+		m.locks[path] = l
+	}
+	l.refs++
+	m.mu.Unlock()
+	l.Lock()
+	return func() {
+		l.Unlock()
+		m.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(m.locks, path)
+		}
+		m.mu.Unlock()
+	}
+}
+
+// wopiReq is the file requested by the wopi client, on behalf of the user
+type wopiReq struct {
+	ctx  context.Context
+	user *model.User
+	path string
+	obj  model.Obj
+	// write is checked on every request, so a revoked permission takes effect before the token expires
+	write bool
+}
+
+// wopiCanAccess checks the access as the user with the meta password given when the token was issued
+func wopiCanAccess(user *model.User, meta *model.Meta, claims *common.WopiClaims) bool {
+	if common.CanAccess(user, meta, claims.Path, "") {
+		return true
+	}
+	return meta != nil && meta.Password != "" && claims.MetaPwd == common.WopiPasswordHash(meta.Password) &&
+		common.CanAccess(user, meta, claims.Path, meta.Password)
+}
+
+// wopiFile checks the access token of the file requested by the wopi client, and the permissions
+// of the user as they may be changed after the token is issued, the status is responded if it returns false
+func wopiFile(c *gin.Context) (*wopiReq, bool) {
+	claims, err := common.ParseWopiToken(c.Query("access_token"))
+	if err != nil {
+		c.Status(401)
+		return nil, false
+	}
+	path, err := base64.RawURLEncoding.DecodeString(c.Param("id"))
+	if err != nil || string(path) != claims.Path {
+		c.Status(401)
+		return nil, false
+	}
+	user, err := op.GetUserByName(claims.Username)
+	if err != nil || user.Disabled || user.PwdTS != claims.PwdTS {
+		c.Status(401)
+		return nil, false
+	}
+	// the base path may be changed after the token is issued
+	if !utils.IsSubPath(user.BasePath, claims.Path) {
+		c.Status(401)
+		return nil, false
+	}
+	meta, err := op.GetNearestMeta(claims.Path)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		c.Status(500)
+		return nil, false
+	}
+	if !wopiCanAccess(user, meta, claims) {
+		c.Status(401)
+		return nil, false
+	}
+	ctx := context.WithValue(c, "user", user)
+	obj, err := fs.Get(ctx, claims.Path, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		if errs.IsObjectNotFound(err) {
+			c.Status(404)
+		} else {
+			c.Status(500)
+		}
+		return nil, false
+	}
+	if obj.IsDir() {
+		c.Status(404)
+		return nil, false
+	}
+	return &wopiReq{
+		ctx:   ctx,
+		user:  user,
+		path:  claims.Path,
+		obj:   obj,
+		write: user.CanWrite() || common.CanWrite(meta, stdpath.Dir(claims.Path)),
+	}, true
+}
+
+func wopiVersion(obj model.Obj) string {
+	return fmt.Sprintf("%d-%d", obj.ModTime().UnixNano(), obj.GetSize())
+}
+
+func WopiCheckFileInfo(c *gin.Context) {
+	req, ok := wopiFile(c)
+	if !ok {
+		return
+	}
+	c.JSON(200, gin.H{
+		"BaseFileName":            req.obj.GetName(),
+		"Size":                    req.obj.GetSize(),
+		"Version":                 wopiVersion(req.obj),
+		"LastModifiedTime":        req.obj.ModTime().UTC().Format(time.RFC3339),
+		"OwnerId":                 "alist",
+		"UserId":                  strconv.FormatUint(uint64(req.user.ID), 10),
+		"UserFriendlyName":        req.user.Username,
+		"ReadOnly":                !req.write,
+		"UserCanWrite":            req.write,
+		"UserCanRename":           false,
+		"UserCanNotWriteRelative": true,
+		"SupportsUpdate":          true,
+		"SupportsLocks":           true,
+		"SupportsGetLock":         true,
+		"SupportsRename":          false,
+	})
+}
+
+func WopiGetFile(c *gin.Context) {
+	req, ok := wopiFile(c)
+	if !ok {
+		return
+	}
+	link, obj, err := fs.Link(req.ctx, req.path, model.LinkArgs{
+		IP:      c.ClientIP(),
+		Header:  c.Request.Header,
+		HttpReq: c.Request,
+	})
+	if err != nil {
+		c.Status(500)
+		return
+	}
+	c.Header("X-WOPI-ItemVersion", wopiVersion(obj))
+	if err = common.Proxy(c.Writer, c.Request, link, obj); err != nil {
+		log.Errorf("failed proxy wopi file %s: %+v", req.path, err)
+	}
+}
+
+func WopiPutFile(c *gin.Context) {
+	defer c.Request.Body.Close()
+	req, ok := wopiFile(c)
+	if !ok {
+		return
+	}
+	if !req.write {
+		c.Status(401)
+		return
+	}
+	// the size is needed by the storages to put
+	if c.Request.ContentLength < 0 {
+		c.Status(411)
+		return
+	}
+	lock := c.GetHeader("X-WOPI-Lock")
+	// hold the lock until written, so it can't be taken by others in the middle
+	unlock := wopiLockMu.Lock(req.path)
+	defer unlock()
+	current, _ := wopiLocks.Get(req.path)
+	// an unlocked file can only be written if it's empty, as a new file created by the client
+	if (current == "" && req.obj.GetSize() != 0) || (current != "" && current != lock) {
+		c.Header("X-WOPI-Lock", current)
+		c.Status(409)
+		return
+	}
+	dir, name := stdpath.Split(req.path)
+	err := fs.PutDirectly(req.ctx, dir, &stream.FileStream{
+		Ctx: req.ctx,
+		Obj: &model.Object{
+			Name:     name,
+			Size:     c.Request.ContentLength,
+			Modified: time.Now(),
+		},
+		Reader:   c.Request.Body,
+		Mimetype: utils.GetMimeType(name),
+	}, true)
+	if err != nil {
+		log.Errorf("failed put wopi file %s: %+v", req.path, err)
+		c.Status(500)
+		return
+	}
+	if obj, err := fs.Get(req.ctx, req.path, &fs.GetArgs{NoLog: true}); err == nil {
+		c.Header("X-WOPI-ItemVersion", wopiVersion(obj))
+	}
+	c.Status(200)
+}
+
+// WopiFileOperation handles the lock operations specified by the X-WOPI-Override header
+func WopiFileOperation(c *gin.Context) {
+	req, ok := wopiFile(c)
+	if !ok {
+		return
+	}
+	override := c.GetHeader("X-WOPI-Override")
+	lock, oldLock := c.GetHeader("X-WOPI-Lock"), c.GetHeader("X-WOPI-OldLock")
+	unlock := wopiLockMu.Lock(req.path)
+	defer unlock()
+	current, _ := wopiLocks.Get(req.path)
+	conflict := func() {
+		c.Header("X-WOPI-Lock", current)
+		c.Status(409)
+	}
+	switch override {
+	case "GET_LOCK":
+		c.Header("X-WOPI-Lock", current)
+	case "LOCK":
+		if !req.write {
+			c.Status(401)
+			return
+		}
+		if lock == "" {
+			c.Status(400)
+			return
+		}
+		// it's unlock and relock if the old lock is given
+		if (oldLock == "" && current != "" && current != lock) || (oldLock != "" && current != oldLock) {
+			conflict()
+			return
+		}
+		wopiLocks.Update(req.path, lock, wopiLockExpiration)
+	case "REFRESH_LOCK":
+		if current != lock || lock == "" {
+			conflict()
+			return
+		}
+		wopiLocks.Update(req.path, lock, wopiLockExpiration)
+	case "UNLOCK":
+		if current != lock || lock == "" {
+			conflict()
+			return
+		}
+		wopiLocks.Del(req.path)
+	default:
+		c.Status(501)
+		return
+	}
+	c.Status(200)
+}
@@ -29,8 +29,12 @@ func Down(c *gin.Context) {
 	c.Set("meta", meta)
 	// verify sign
 	if needSign(meta, rawPath) {
-		s := c.Query("sign")
-		err = sign.Verify(rawPath, strings.TrimSuffix(s, "/"))
+		s := strings.TrimSuffix(c.Query("sign"), "/")
+		err = sign.Verify(rawPath, s)
+		if err != nil && c.Query("type") == "thumb" && sign.VerifyThumb(rawPath, s) == nil {
+			err = nil
+			c.Set("thumb_only", true)
+		}
 		if err != nil {
 			common.ErrorResp(c, err, 401)
 			c.Abort()
@@ -42,6 +42,7 @@ func Init(e *gin.Engine) {
 	g.GET("/p/*path", middlewares.Down, handles.Proxy)
 	g.HEAD("/d/*path", middlewares.Down, handles.Down)
 	g.HEAD("/p/*path", middlewares.Down, handles.Proxy)
+	_wopi(g.Group("/wopi/files"))
 
 	api := g.Group("/api")
 	auth := api.Group("", middlewares.Auth)
@@ -76,6 +77,10 @@ func Init(e *gin.Engine) {
 	public.Any("/offline_download_tools", handles.OfflineDownloadTools)
 
 	_fs(auth.Group("/fs"))
+	_music(auth.Group("/music"))
+	_photo(auth.Group("/photo"))
+	_tag(auth.Group("/tag", middlewares.AuthNotGuest))
+	_favorite(auth.Group("/favorite", middlewares.AuthNotGuest))
 	_task(auth.Group("/task", middlewares.AuthNotGuest))
 	admin(auth.Group("/admin", middlewares.AuthAdmin))
 	if flags.Debug || flags.Dev {
@@ -135,12 +140,40 @@ func admin(g *gin.RouterGroup) {
 	ms.POST("/get", message.HttpInstance.GetHandle)
 	ms.POST("/send", message.HttpInstance.SendHandle)
 
+	bak := g.Group("/backup")
+	bak.GET("/export", handles.ExportBackup)
+	bak.POST("/import", handles.ImportBackup)
+	bak.POST("/save", handles.SaveBackup)
+
+	musicLib := g.Group("/music")
+	musicLib.GET("/status", handles.GetMusicStatus)
+	musicLib.POST("/scan", handles.ScanMusic)
+	musicLib.POST("/stop", handles.StopScanMusic)
+
+	g.POST("/photo/scan", handles.ScanPhotos)
+	g.POST("/strm/export", handles.StrmExport)
+
+	warm := g.Group("/warm")
+	warm.GET("/list", handles.ListWarmJobs)
+	warm.GET("/get", handles.GetWarmJob)
+	warm.POST("/create", handles.CreateWarmJob)
+	warm.POST("/update", handles.UpdateWarmJob)
+	warm.POST("/delete", handles.DeleteWarmJob)
+	warm.POST("/run", handles.RunWarmJob)
+
 	index := g.Group("/index")
 	index.POST("/build", middlewares.SearchIndex, handles.BuildIndex)
 	index.POST("/update", middlewares.SearchIndex, handles.UpdateIndex)
 	index.POST("/stop", middlewares.SearchIndex, handles.StopIndex)
 	index.POST("/clear", middlewares.SearchIndex, handles.ClearIndex)
 	index.GET("/progress", middlewares.SearchIndex, handles.GetProgress)
+	index.GET("/job/list", handles.ListIndexJobs)
+	index.GET("/job/get", handles.GetIndexJob)
+	index.POST("/job/create", handles.CreateIndexJob)
+	index.POST("/job/update", handles.UpdateIndexJob)
+	index.POST("/job/delete", handles.DeleteIndexJob)
+	index.POST("/job/run", middlewares.SearchIndex, handles.RunIndexJob)
+	index.POST("/job/stop", middlewares.SearchIndex, handles.StopIndexJob)
 }
 
 func _fs(g *gin.RouterGroup) {
@@ -148,7 +181,9 @@ func _fs(g *gin.RouterGroup) {
 	g.Any("/search", middlewares.SearchIndex, handles.Search)
 	g.Any("/get", handles.FsGet)
 	g.Any("/other", handles.FsOther)
+	g.Any("/hash", handles.FsHash)
 	g.Any("/dirs", handles.FsDirs)
+	g.Any("/changes", handles.FsChanges)
 	g.POST("/mkdir", handles.FsMkdir)
 	g.POST("/rename", handles.FsRename)
 	g.POST("/batch_rename", handles.FsBatchRename)
@@ -160,11 +195,48 @@ func _fs(g *gin.RouterGroup) {
 	g.POST("/remove_empty_directory", handles.FsRemoveEmptyDirectory)
 	g.PUT("/put", middlewares.FsUp, handles.FsStream)
 	g.PUT("/form", middlewares.FsUp, handles.FsForm)
+	g.POST("/upload/session", middlewares.FsUp, handles.FsUploadSession)
+	g.POST("/upload/complete", middlewares.FsUp, handles.FsUploadComplete)
 	g.POST("/link", middlewares.AuthAdmin, handles.Link)
 	// g.POST("/add_aria2", handles.AddOfflineDownload)
 	// g.POST("/add_qbit", handles.AddQbittorrent)
 	// g.POST("/add_transmission", handles.SetTransmission)
 	g.POST("/add_offline_download", handles.AddOfflineDownload)
+	g.Any("/wopi", handles.FsWopi)
+}
+
+// _wopi serves the wopi clients, which are authorized by the access token of the file
+func _wopi(g *gin.RouterGroup) {
+	g.GET("/:id", handles.WopiCheckFileInfo)
+	g.POST("/:id", handles.WopiFileOperation)
+	g.GET("/:id/contents", handles.WopiGetFile)
+	g.POST("/:id/contents", handles.WopiPutFile)
+}
+
+func _music(g *gin.RouterGroup) {
+	g.Any("/artists", handles.MusicArtists)
+	g.Any("/albums", handles.MusicAlbums)
+	g.Any("/tracks", handles.MusicTracks)
+	g.GET("/playlist", handles.MusicPlaylist)
+}
+
+func _photo(g *gin.RouterGroup) {
+	g.POST("/list", handles.PhotoList)
+	g.Any("/cameras", handles.PhotoCameras)
+}
+
+func _tag(g *gin.RouterGroup) {
+	g.POST("/add", handles.AddTag)
+	g.POST("/remove", handles.RemoveTag)
+	g.Any("/get", handles.GetTags)
+	g.GET("/names", handles.TagNames)
+	g.Any("/list", handles.ListByTag)
+}
+
+func _favorite(g *gin.RouterGroup) {
+	g.POST("/add", handles.AddFavorite)
+	g.POST("/remove", handles.RemoveFavorite)
+	g.Any("/list", handles.ListFavorites)
 }
 
 func _task(g *gin.RouterGroup) {
@@ -81,9 +81,23 @@ func (b *s3Backend) ListBucket(ctx context.Context, bucketName string, prefix *g
 		prefix.HasDelimiter = false
 	}
 
-	response := gofakes3.NewObjectList()
 	path, remaining := prefixParser(prefix)
+	// list a single level page by page, a marker that is a key falls back to the full list
+	if prefix.HasDelimiter && remaining == "" && (!page.HasMarker || strings.HasPrefix(page.Marker, pageMarkerPrefix)) {
+		response, err := b.entryListPage(ctx, bucketPath, path, page)
+		if err == gofakes3.ErrNoSuchKey {
+			return gofakes3.NewObjectList(), nil
+		} else if err != nil {
+			return nil, err
+		}
+		// the storage may return the whole folder at once if it can't be paged
+		if !response.IsTruncated && page.MaxKeys > 0 && int64(len(response.CommonPrefixes)+len(response.Contents)) > page.MaxKeys {
+			return b.pager(response, gofakes3.ListBucketPage{MaxKeys: page.MaxKeys})
+		}
+		return response, nil
+	}
 
+	response := gofakes3.NewObjectList()
 	err = b.entryListR(bucketPath, path, remaining, prefix.HasDelimiter, response)
 	if err == gofakes3.ErrNoSuchKey {
 		// AWS just returns an empty list
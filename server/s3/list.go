@@ -3,12 +3,62 @@
 package s3
 
 import (
+	"context"
 	"path"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/gofakes3"
+	"github.com/pkg/errors"
 )
 
+// pageMarkerPrefix marks the markers which are list tokens of fs.ListPage instead of keys
+const pageMarkerPrefix = "alist-page:"
+
+// entryListPage lists a page of the entries in fdPath, so that a huge folder
+// is never loaded into memory at once.
+func (b *s3Backend) entryListPage(ctx context.Context, bucket, fdPath string, page gofakes3.ListBucketPage) (*gofakes3.ObjectList, error) {
+	fp := path.Join(bucket, fdPath)
+	limit := page.MaxKeys
+	if limit <= 0 {
+		limit = 1000
+	}
+	meta, _ := op.GetNearestMeta(fp)
+	dirEntries, next, err := fs.ListPage(context.WithValue(ctx, "meta", meta), fp, &fs.ListPageArgs{
+		Token: strings.TrimPrefix(page.Marker, pageMarkerPrefix),
+		Limit: int(limit),
+		NoLog: true,
+	})
+	if errs.IsNotFoundError(err) || errors.Is(errors.Cause(err), errs.NotFolder) {
+		return nil, gofakes3.ErrNoSuchKey
+	} else if err != nil {
+		return nil, err
+	}
+
+	response := gofakes3.NewObjectList()
+	for _, entry := range dirEntries {
+		objectPath := path.Join(fdPath, entry.GetName())
+		if entry.IsDir() {
+			response.AddPrefix(objectPath)
+			continue
+		}
+		response.Add(&gofakes3.Content{
+			Key:          objectPath,
+			LastModified: gofakes3.NewContentTime(entry.ModTime()),
+			ETag:         getFileHash(entry),
+			Size:         entry.GetSize(),
+			StorageClass: gofakes3.StorageStandard,
+		})
+	}
+	if next != "" {
+		response.IsTruncated = true
+		response.NextMarker = pageMarkerPrefix + next
+	}
+	return response, nil
+}
+
 func (b *s3Backend) entryListR(bucket, fdPath, name string, addPrefix bool, response *gofakes3.ObjectList) error {
 	fp := path.Join(bucket, fdPath)
 
@@ -64,6 +64,9 @@ func copyFiles(ctx context.Context, src, dst string, overwrite bool) (status int
 	return http.StatusCreated, nil
 }
 
+// walkPageSize is the number of objs listed at a time by walkFS
+const walkPageSize = 1000
+
 // walkFS traverses filesystem fs starting at name up to depth levels.
 //
 // Allowed values for depth are 0, 1 or infiniteDepth. For each visited node,
@@ -85,32 +88,27 @@ func walkFS(ctx context.Context, depth int, name string, info model.Obj, walkFn
 		depth = 0
 	}
 	meta, _ := op.GetNearestMeta(name)
-	// Read directory names.
-	objs, err := fs.List(context.WithValue(ctx, "meta", meta), name, &fs.ListArgs{})
-	//f, err := fs.OpenFile(ctx, name, os.O_RDONLY, 0)
-	//if err != nil {
-	//	return walkFn(name, info, err)
-	//}
-	//fileInfos, err := f.Readdir(0)
-	//f.Close()
-	if err != nil {
-		return walkFn(name, info, err)
-	}
-
-	for _, fileInfo := range objs {
-		filename := path.Join(name, fileInfo.GetName())
-		if err != nil {
-			if err := walkFn(filename, fileInfo, err); err != nil && err != filepath.SkipDir {
-				return err
-			}
-		} else {
-			err = walkFS(ctx, depth, filename, fileInfo, walkFn)
+	// Read directory names page by page, so that the responses of a huge folder
+	// are streamed to the client instead of waiting for the whole list.
+	var walkErr error
+	err = fs.ListPages(context.WithValue(ctx, "meta", meta), name, &fs.ListPageArgs{Limit: walkPageSize}, func(objs []model.Obj) error {
+		for _, fileInfo := range objs {
+			filename := path.Join(name, fileInfo.GetName())
+			err := walkFS(ctx, depth, filename, fileInfo, walkFn)
 			if err != nil {
 				if !fileInfo.IsDir() || err != filepath.SkipDir {
+					walkErr = err
 					return err
 				}
 			}
 		}
+		return nil
+	})
+	if walkErr != nil {
+		return walkErr
+	}
+	if err != nil {
+		return walkFn(name, info, err)
 	}
 	return nil
 }
@@ -315,8 +315,14 @@ type multistatusWriter struct {
 
 	w   http.ResponseWriter
 	enc *ixml.Encoder
+	// n is the number of responses written, used to flush periodically
+	n int
 }
 
+// flushInterval is the number of responses after which the written responses
+// are flushed to the client, so that listing a huge folder is streamed
+const flushInterval = 100
+
 // Write validates and emits a DAV response as part of a multistatus response
 // element.
 //
@@ -342,7 +348,16 @@ func (w *multistatusWriter) write(r *response) error {
 	if err != nil {
 		return err
 	}
-	return w.enc.Encode(r)
+	if err = w.enc.Encode(r); err != nil {
+		return err
+	}
+	w.n++
+	if w.n%flushInterval == 0 {
+		if f, ok := w.w.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+	return nil
 }
 
 // writeHeader writes a XML multistatus start element on w's underlying